@@ -9,6 +9,7 @@ import (
 
 var (
 	appDirId          = "mwa"
+	thumbnailsDirName = "thumbnails"
 	defaultAppDirName = fmt.Sprintf(".%s", appDirId)
 	subdirs           = []string{
 		"playlists",
@@ -60,3 +61,14 @@ func GetCachePath(dir string) (string, error) {
 
 	return path.Join(cacheDirPath, appDirId), nil
 }
+
+// GetThumbnailsPath returns directory for generated images under the cache directory.
+// Unlike cache of directories, images are always cached, so temp directory is used when cache directory cannot be resolved.
+func GetThumbnailsPath(cacheDir string) string {
+	cachePath, err := GetCachePath(cacheDir)
+	if err != nil {
+		cachePath = filepath.Join(os.TempDir(), appDirId)
+	}
+
+	return filepath.Join(cachePath, thumbnailsDirName)
+}
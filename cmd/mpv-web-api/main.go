@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -15,7 +16,9 @@ import (
 	"github.com/sarpt/mpv-web-api/cmd/mpv-web-api/internal/utils"
 	"github.com/sarpt/mpv-web-api/internal/rest"
 	"github.com/sarpt/mpv-web-api/internal/sse"
+	"github.com/sarpt/mpv-web-api/internal/ws"
 	"github.com/sarpt/mpv-web-api/pkg/api"
+	"github.com/sarpt/mpv-web-api/pkg/probe"
 	"github.com/sarpt/mpv-web-api/pkg/state"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
 )
@@ -28,6 +31,7 @@ const (
 const (
 	defaultAddress           = ":3001"
 	defaultSocketTimeoutSec  = 15
+	defaultThumbnailWorkers  = 2
 	defaultMpvSocketFilename = "mpvsocket"
 
 	addressFlag          = "addr"
@@ -36,14 +40,25 @@ const (
 	clearCacheFlag       = "clear-cache"
 	cacheDirFlag         = "cache-dir"
 	dirFlag              = "dir"
+	dirExcludeFlag       = "dir-exclude"
+	dirIncludeFlag       = "dir-include"
+	dirMaxDepthFlag      = "dir-max-depth"
 	dirRecursiveFlag     = "dir-recursive"
 	mpvSocketPathFlag    = "mpv-socket-path"
 	pathMappingsFlag     = "path-mappings"
 	playlistPrefixFlag   = "playlist-prefix"
+	proberFlag           = "prober"
 	socketTimeoutSecFlag = "socket-timeout"
+	sseHeartbeatSecFlag  = "sse-heartbeat-interval"
+	sseQueuePolicyFlag   = "sse-queue-policy"
+	sseQueueSizeFlag     = "sse-queue-size"
+	sseRetrySecFlag      = "sse-retry-interval"
 	startMpvInstanceFlag = "start-mpv-instance"
+	thumbnailWorkersFlag = "thumbnail-workers"
 	appDirFlag           = "app-dir"
 	watchDirFlag         = "watch-dir"
+	watchDirPollFlag     = "watch-dir-poll"
+	watchDirPollSecFlag  = "watch-dir-poll-interval"
 )
 
 var (
@@ -55,18 +70,31 @@ var (
 	clearCache       *bool
 	cacheDir         *string
 	dir              *listflag.StringList
+	dirExclude       *listflag.StringList
+	dirInclude       *listflag.StringList
+	dirMaxDepth      *int
 	dirRecursive     *bool
 	mpvSocketPath    *string
 	pathMappings     *listflag.StringList
 	playlistPrefix   *listflag.StringList
+	proberName       *string
 	socketTimeoutSec *int64
+	sseHeartbeatSec  *int64
+	sseQueuePolicy   *string
+	sseQueueSize     *int
+	sseRetrySec      *int64
 	startMpvInstance *bool
+	thumbnailWorkers *int
 	appDir           *string
 	watchDir         *bool
+	watchDirPoll     *bool
+	watchDirPollSec  *int64
 )
 
 func init() {
 	dir = listflag.NewStringList([]string{})
+	dirExclude = listflag.NewStringList([]string{})
+	dirInclude = listflag.NewStringList([]string{})
 	pathMappings = listflag.NewStringList([]string{})
 	playlistPrefix = listflag.NewStringList([]string{})
 
@@ -75,15 +103,26 @@ func init() {
 	cacheDir = flag.String(cacheDirFlag, "", "directory used for cache lookup. When not provided a default user cache directory will be used")
 	clearCache = flag.Bool(clearCacheFlag, false, "clear previously saved cache (if it exists). Only takes effect when provided alongside --cache. Does nothing otherwise.")
 	flag.Var(dir, dirFlag, "directory containing media files. When not provided current working directory for the process is being used")
+	flag.Var(dirExclude, dirExcludeFlag, "glob pattern of files and directories (relative to --dir) that should not be handled, eg. '**/Extras/**' or '*.sample.mkv'. Patterns without '/' are matched against every path segment. Can be provided multiple times")
+	flag.Var(dirInclude, dirIncludeFlag, "glob pattern of files (relative to --dir) that should be handled. When provided, files not matching any of include patterns are skipped. Can be provided multiple times")
+	dirMaxDepth = flag.Int(dirMaxDepthFlag, 0, "maximum depth of subdirectories checked recursively under --dir. 0 means no limit")
 	dirRecursive = flag.Bool(dirRecursiveFlag, true, "when not provided, directories provided to --dir (or working directory when --dir is absent) will only be checked on the first level and any directories within will be ignored")
 	address = flag.String(addressFlag, defaultAddress, "address on which server should listen on")
 	allowCORS = flag.Bool(allowCorsFlag, false, "when not provided, Cross Origin Site Requests will be rejected")
 	mpvSocketPath = flag.String(mpvSocketPathFlag, defaultMpvSocketPath, "path to a socket file used by a MPV instance to listen for commands")
 	flag.Var(pathMappings, pathMappingsFlag, "path parts to be replaced when providing them to mpv process. The mapping is in a form of <path-to-be-replaced>:<replacement-path>. Each path is matched against every replacement provided, even when previous replacements matched")
 	flag.Var(playlistPrefix, playlistPrefixFlag, "prefix for JSON files to be treated as playlists. The JSON file itself has to have in the root object property 'MpvWebApiPlaylist' set to true to be treated as a playlist")
+	proberName = flag.String(proberFlag, probe.FfprobeProberName, fmt.Sprintf("backend used for probing media files. '%s' requires ffprobe to be available in PATH, '%s' parses Matroska/WebM and MP4/MOV containers without external tools and falls back to ffprobe for other files", probe.FfprobeProberName, probe.NativeProberName))
 	socketTimeoutSec = flag.Int64(socketTimeoutSecFlag, defaultSocketTimeoutSec, "maximum allowed time in seconds for retrying connection to MPV instance")
+	sseHeartbeatSec = flag.Int64(sseHeartbeatSecFlag, int64(sse.DefaultHeartbeatInterval/time.Second), "interval in seconds between heartbeats sent to SSE clients, keeping idle connections open and detecting disconnected clients. 0 disables heartbeats")
+	sseQueuePolicy = flag.String(sseQueuePolicyFlag, string(sse.DropOldestPolicy), fmt.Sprintf("what happens to SSE and WebSocket changes when a client does not receive them fast enough and its queue is full. '%s' drops the oldest queued change, '%s' replaces a queued change of the same type, '%s' closes the connection", sse.DropOldestPolicy, sse.CoalescePolicy, sse.DisconnectPolicy))
+	sseQueueSize = flag.Int(sseQueueSizeFlag, sse.DefaultQueueSize, "maximum number of SSE and WebSocket changes waiting to be sent to each client on each channel")
+	sseRetrySec = flag.Int64(sseRetrySecFlag, int64(sse.DefaultRetryInterval/time.Second), "time in seconds that browsers should wait before reconnecting to SSE after the connection is lost")
 	startMpvInstance = flag.Bool(startMpvInstanceFlag, true, "controls whether the application should create and manage its own MPV instance")
+	thumbnailWorkers = flag.Int(thumbnailWorkersFlag, defaultThumbnailWorkers, "maximum number of ffmpeg processes generating thumbnails at the same time")
 	watchDir = flag.Bool(watchDirFlag, false, "when not provided, directories provided to --dir (or working directory when --dir is absent) will only be checked once at a startup and files adding/removal in these directories during runtime will be ignored")
+	watchDirPoll = flag.Bool(watchDirPollFlag, false, "when provided alongside --watch-dir, directories are watched by periodically listing their contents instead of relying on file system notifications. Useful for network file systems (NFS, SMB, FUSE) which do not emit notifications")
+	watchDirPollSec = flag.Int64(watchDirPollSecFlag, int64(api.DefaultFsPollInterval/time.Second), "interval in seconds between listings of directories watched with --watch-dir-poll")
 
 	flag.Parse()
 }
@@ -114,11 +153,34 @@ func main() {
 		}
 	}
 
+	prober, err := probe.NewProber(*proberName)
+	if err != nil {
+		errLog.Printf("could not use \"%s\" as a prober: %s", *proberName, err)
+		os.Exit(1)
+	}
+
+	outLog.Printf("using \"%s\" prober for media files", prober.Name())
+
+	queuePolicy, err := sse.ParseOverflowPolicy(*sseQueuePolicy)
+	if err != nil {
+		errLog.Printf("could not use \"%s\" as a SSE queue policy: %s", *sseQueuePolicy, err)
+		os.Exit(1)
+	}
+
+	if *sseQueueSize <= 0 {
+		errLog.Printf("could not use %d as a SSE queue size: %s", *sseQueueSize, sse.ErrInvalidQueueSize)
+		os.Exit(1)
+	}
+
 	statesRepository := state.NewRepository()
 	sseCfg := sse.Config{
-		ErrWriter:        errWriter,
-		OutWriter:        outWriter,
-		StatesRepository: statesRepository,
+		ErrWriter:         errWriter,
+		HeartbeatInterval: time.Duration(*sseHeartbeatSec) * time.Second,
+		OutWriter:         outWriter,
+		QueuePolicy:       queuePolicy,
+		QueueSize:         *sseQueueSize,
+		RetryInterval:     time.Duration(*sseRetrySec) * time.Second,
+		StatesRepository:  statesRepository,
 	}
 	sseServer := sse.NewServer(sseCfg)
 
@@ -130,7 +192,18 @@ func main() {
 	}
 	restServer := rest.NewServer(restCfg)
 
+	wsCfg := ws.Config{
+		AllowCORS:        *allowCORS,
+		ErrWriter:        errWriter,
+		OutWriter:        outWriter,
+		QueuePolicy:      queuePolicy,
+		QueueSize:        *sseQueueSize,
+		StatesRepository: statesRepository,
+	}
+	wsServer := ws.NewServer(wsCfg)
+
 	socketConnectionTimeout := time.Duration(time.Duration(*socketTimeoutSec) * time.Second)
+	fsPollInterval := time.Duration(*watchDirPollSec) * time.Second
 
 	pathMappingsList := []api.PathMapping{}
 	for _, replacement := range pathMappings.Values() {
@@ -144,16 +217,21 @@ func main() {
 		AllowCORS:             *allowCORS,
 		CacheDir:              appCachePath,
 		ClearCache:            *clearCache,
+		FsPollInterval:        fsPollInterval,
 		MpvSocketPath:         *mpvSocketPath,
 		PathMappings:          pathMappingsList,
 		PlaylistFilesPrefixes: playlistPrefix.Values(),
+		Prober:                prober,
 		PluginServers: map[string]api.PluginServer{
 			sseServer.Name():  sseServer,
 			restServer.Name(): restServer,
+			wsServer.Name():   wsServer,
 		},
 		StartMpvInstance:        *startMpvInstance,
 		StatesRepository:        statesRepository,
 		SocketConnectionTimeout: socketConnectionTimeout,
+		ThumbnailsDir:           utils.GetThumbnailsPath(*cacheDir),
+		ThumbnailWorkers:        *thumbnailWorkers,
 		UseCache:                *cache,
 	}
 
@@ -176,14 +254,22 @@ func main() {
 
 		outLog.Printf("no directories specified for server - using working directory '%s'\n", wd)
 		mediaFilesDirectories = append(mediaFilesDirectories, directories.Entry{
+			Exclude:   dirExclude.Values(),
+			Include:   dirInclude.Values(),
+			MaxDepth:  *dirMaxDepth,
 			Path:      wd,
+			Polled:    *watchDirPoll,
 			Recursive: *dirRecursive,
 			Watched:   *watchDir,
 		})
 	} else {
 		for _, dir := range dir.Values() {
 			mediaFilesDirectories = append(mediaFilesDirectories, directories.Entry{
+				Exclude:   dirExclude.Values(),
+				Include:   dirInclude.Values(),
+				MaxDepth:  *dirMaxDepth,
 				Path:      dir,
+				Polled:    *watchDirPoll,
 				Recursive: *dirRecursive,
 				Watched:   *watchDir,
 			})
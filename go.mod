@@ -6,6 +6,7 @@ require (
 	github.com/fsnotify/fsnotify v1.5.1
 	github.com/golang/mock v1.6.0
 	github.com/google/uuid v1.2.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/sarpt/goutils v0.0.4
 )
 
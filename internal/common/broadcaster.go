@@ -10,37 +10,114 @@ type Subscriber[T any] interface {
 	Receive(payload T)
 }
 
+// subscription holds payloads waiting to be received by a subscriber.
+// Each subscription delivers payloads on its own goroutine, one at a time and in the order they were sent,
+// so a slow subscriber neither holds back the producer and other subscribers, nor receives payloads out of order.
+type subscription[T any] struct {
+	done       chan struct{}
+	lock       *sync.Mutex
+	pending    []T
+	subscriber Subscriber[T]
+	wake       chan struct{}
+}
+
+func newSubscription[T any](subscriber Subscriber[T]) *subscription[T] {
+	return &subscription[T]{
+		done:       make(chan struct{}),
+		lock:       &sync.Mutex{},
+		pending:    []T{},
+		subscriber: subscriber,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+func (s *subscription[T]) enqueue(payload T) {
+	s.lock.Lock()
+	s.pending = append(s.pending, payload)
+	s.lock.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscription[T]) deliver() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+
+		for {
+			s.lock.Lock()
+			if len(s.pending) == 0 {
+				s.lock.Unlock()
+
+				break
+			}
+
+			payload := s.pending[0]
+			s.pending = s.pending[1:]
+			s.lock.Unlock()
+
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+
+			s.subscriber.Receive(payload)
+		}
+	}
+}
+
+func (s *subscription[T]) stop() {
+	close(s.done)
+}
+
 type Broadcaster[T any] struct {
-	payloads    chan T
-	lock        *sync.RWMutex
-	subscribers map[string]Subscriber[T]
+	payloads      chan T
+	lock          *sync.RWMutex
+	subscriptions map[string]*subscription[T]
 }
 
 func NewBroadcaster[T any]() *Broadcaster[T] {
 	return &Broadcaster[T]{
-		payloads:    make(chan T),
-		lock:        &sync.RWMutex{},
-		subscribers: map[string]Subscriber[T]{},
+		payloads:      make(chan T),
+		lock:          &sync.RWMutex{},
+		subscriptions: map[string]*subscription[T]{},
 	}
 }
 
 // Subscribe adds subscriber to the broadcast listeneres pool.
+// Subscriber receives payloads sent after subscription, in the order they were sent.
 // Returns unsubscriber function.
 func (b *Broadcaster[T]) Subscribe(sub Subscriber[T]) func() {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
 	subUuid := uuid.NewString()
-	b.subscribers[subUuid] = sub
+	subscription := newSubscription(sub)
+	b.subscriptions[subUuid] = subscription
+	go subscription.deliver()
 
 	return func() { b.unsubscribe(subUuid) }
 }
 
+// unsubscribe removes subscriber from the pool. Payloads not yet received by the subscriber are discarded.
 func (b *Broadcaster[T]) unsubscribe(subUuid string) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
-	delete(b.subscribers, subUuid)
+	subscription, ok := b.subscriptions[subUuid]
+	if !ok {
+		return
+	}
+
+	subscription.stop()
+	delete(b.subscriptions, subUuid)
 }
 
 func (b *Broadcaster[T]) Send(payload T) {
@@ -56,8 +133,8 @@ func (b *Broadcaster[T]) Broadcast() {
 			}
 
 			b.lock.RLock()
-			for _, subscriber := range b.subscribers {
-				go subscriber.Receive(payload)
+			for _, subscription := range b.subscriptions {
+				subscription.enqueue(payload)
 			}
 			b.lock.RUnlock()
 		}
@@ -0,0 +1,169 @@
+package common_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+)
+
+type recordingSubscriber struct {
+	delay    time.Duration
+	expected int
+	done     chan struct{}
+	lock     *sync.Mutex
+	received []int
+}
+
+func newRecordingSubscriber(expected int, delay time.Duration) *recordingSubscriber {
+	return &recordingSubscriber{
+		delay:    delay,
+		expected: expected,
+		done:     make(chan struct{}),
+		lock:     &sync.Mutex{},
+		received: []int{},
+	}
+}
+
+func (rs *recordingSubscriber) Receive(payload int) {
+	if rs.delay > 0 && payload%100 == 0 {
+		time.Sleep(rs.delay)
+	}
+
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	rs.received = append(rs.received, payload)
+	if len(rs.received) == rs.expected {
+		close(rs.done)
+	}
+}
+
+func (rs *recordingSubscriber) wait(t *testing.T) []int {
+	t.Helper()
+
+	select {
+	case <-rs.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for payloads")
+	}
+
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	return rs.received
+}
+
+type blockingSubscriber struct {
+	release chan struct{}
+	*recordingSubscriber
+}
+
+func (bs *blockingSubscriber) Receive(payload int) {
+	<-bs.release
+	bs.recordingSubscriber.Receive(payload)
+}
+
+func assertInOrder(t *testing.T, received []int, expected int) {
+	t.Helper()
+
+	if len(received) != expected {
+		t.Fatalf("Expected %d payloads, got %d", expected, len(received))
+	}
+
+	for idx, payload := range received {
+		if payload != idx {
+			t.Fatalf("Expected payload %d at position %d, got %d", idx, idx, payload)
+		}
+	}
+}
+
+func TestBroadcaster_DeliversPayloadsInOrderUnderLoad(t *testing.T) {
+	// given
+	payloadsCount := 10000
+	uut := common.NewBroadcaster[int]()
+	uut.Broadcast()
+
+	subscribers := []*recordingSubscriber{
+		newRecordingSubscriber(payloadsCount, 0),
+		newRecordingSubscriber(payloadsCount, time.Millisecond),
+		newRecordingSubscriber(payloadsCount, 2*time.Millisecond),
+	}
+	for _, subscriber := range subscribers {
+		defer uut.Subscribe(subscriber)()
+	}
+
+	// when
+	for idx := 0; idx < payloadsCount; idx++ {
+		uut.Send(idx)
+	}
+
+	// then
+	for _, subscriber := range subscribers {
+		assertInOrder(t, subscriber.wait(t), payloadsCount)
+	}
+}
+
+func TestBroadcaster_DoesNotBlockProducerOnSlowSubscriber(t *testing.T) {
+	// given
+	payloadsCount := 1000
+	uut := common.NewBroadcaster[int]()
+	uut.Broadcast()
+
+	blocked := &blockingSubscriber{
+		release:             make(chan struct{}),
+		recordingSubscriber: newRecordingSubscriber(payloadsCount, 0),
+	}
+	defer uut.Subscribe(blocked)()
+
+	other := newRecordingSubscriber(payloadsCount, 0)
+	defer uut.Subscribe(other)()
+
+	// when
+	sent := make(chan struct{})
+	go func() {
+		for idx := 0; idx < payloadsCount; idx++ {
+			uut.Send(idx)
+		}
+		close(sent)
+	}()
+
+	// then
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Producer was blocked by a slow subscriber")
+	}
+
+	assertInOrder(t, other.wait(t), payloadsCount)
+
+	close(blocked.release)
+	assertInOrder(t, blocked.wait(t), payloadsCount)
+}
+
+func TestBroadcaster_StopsDeliveryAfterUnsubscribe(t *testing.T) {
+	// given
+	uut := common.NewBroadcaster[int]()
+	uut.Broadcast()
+
+	unsubscribed := newRecordingSubscriber(1, 0)
+	unsubscribe := uut.Subscribe(unsubscribed)
+	uut.Send(0)
+	unsubscribed.wait(t)
+
+	other := newRecordingSubscriber(1, 0)
+	defer uut.Subscribe(other)()
+
+	// when
+	unsubscribe()
+	uut.Send(0)
+	other.wait(t)
+
+	// then
+	unsubscribed.lock.Lock()
+	defer unsubscribed.lock.Unlock()
+	if len(unsubscribed.received) != 1 {
+		t.Errorf("Expected unsubscribed subscriber to receive 1 payload, got %d", len(unsubscribed.received))
+	}
+}
@@ -116,18 +116,7 @@ func CreateFormHandler(allArgHandlers map[string]FormArgument) http.HandlerFunc
 		responsePayload := FormResponse{}
 
 		selectedArgHandlers, errors := validateFormRequest(req, allArgHandlers)
-		responsePayload.GeneralError = errors.GeneralError
-		responsePayload.ArgumentErrors = errors.ArgumentErrors
-
-		if responsePayload.GeneralError != "" || len(responsePayload.ArgumentErrors) != 0 {
-			out, err := prepareJSONOutput(responsePayload)
-			if err != nil {
-				res.WriteHeader(400)
-			} else {
-				res.WriteHeader(500)
-			}
-			res.Write(out)
-
+		if writeValidationErrors(res, errors) {
 			return
 		}
 
@@ -161,6 +150,57 @@ func CreateFormHandler(allArgHandlers map[string]FormArgument) http.HandlerFunc
 	}
 }
 
+// CreateFormCommandHandler returns handler function responsible for correct validation of arguments, which are then
+// handled together by the command - for requests in which relations and order of handling of the arguments matter.
+func CreateFormCommandHandler(allArgHandlers map[string]FormArgument, command FormArgumentHandler) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		responsePayload := FormResponse{}
+
+		_, errors := validateFormRequest(req, allArgHandlers)
+		if writeValidationErrors(res, errors) {
+			return
+		}
+
+		err := command(res, req)
+		if err != nil {
+			responsePayload.GeneralError = err.Error()
+			out, _ := prepareJSONOutput(responsePayload)
+			res.WriteHeader(500)
+			res.Write(out)
+
+			return
+		}
+
+		out, err := prepareJSONOutput(responsePayload)
+		if err != nil {
+			res.WriteHeader(500)
+			res.Write(out)
+
+			return
+		}
+
+		res.WriteHeader(200)
+		res.Write(out)
+	}
+}
+
+// writeValidationErrors responds with validation errors, if any occured. Returns whether the response was written.
+func writeValidationErrors(res http.ResponseWriter, errors HandlerErrors) bool {
+	if errors.GeneralError == "" && len(errors.ArgumentErrors) == 0 {
+		return false
+	}
+
+	out, err := prepareJSONOutput(FormResponse{HandlerErrors: errors})
+	if err != nil {
+		res.WriteHeader(400)
+	} else {
+		res.WriteHeader(500)
+	}
+	res.Write(out)
+
+	return true
+}
+
 // validateFormRequest checks form body for arguments and their correctnes.
 // Result of validation is an array of arguments that have handlers associated and handlerErrors (if any occured).
 func validateFormRequest(req *http.Request, arguments map[string]FormArgument) (map[string]FormArgumentHandler, HandlerErrors) {
@@ -0,0 +1,164 @@
+package playback_commands
+
+import (
+	"errors"
+	"log"
+
+	"github.com/sarpt/mpv-web-api/pkg/api"
+)
+
+var (
+	ErrPathAndUuidProvidedTogether = errors.New("path and uuid arguments should not be provided together in the same request")
+)
+
+// Args are arguments of playback commands, named the same as arguments of POST /rest/playback.
+// Only provided arguments are handled.
+type Args struct {
+	AlbumID      *string `json:"albumID"`
+	Append       bool    `json:"append"`
+	AudioID      *string `json:"audioID"`
+	Chapter      *int64  `json:"chapter"`
+	Chapters     []int64 `json:"chapters"`
+	Force        bool    `json:"force"`
+	Fullscreen   *bool   `json:"fullscreen"`
+	LoopFile     *bool   `json:"loopFile"`
+	Path         *string `json:"path"`
+	Pause        *bool   `json:"pause"`
+	PlaylistIdx  *int    `json:"playlistIdx"`
+	PlaylistUUID *string `json:"playlistUUID"`
+	Stop         bool    `json:"stop"`
+	SubtitleID   *string `json:"subtitleID"`
+	UUID         *string `json:"uuid"`
+}
+
+// Executor executes playback commands requested by clients of the servers, the same way regardless of the protocol used.
+type Executor struct {
+	api    api.PluginApi
+	outLog *log.Logger
+}
+
+// NewExecutor returns executor of playback commands, logging executed operations to the outLog.
+func NewExecutor(pluginApi api.PluginApi, outLog *log.Logger) *Executor {
+	return &Executor{
+		api:    pluginApi,
+		outLog: outLog,
+	}
+}
+
+// Execute executes playback operations of the provided arguments. Loading of files, playlists and albums goes first,
+// so the rest of the operations apply to the loaded media. Execution stops at the first failed operation.
+// Requester describes the client requesting the command in logs.
+func (e *Executor) Execute(args Args, requester string) error {
+	if args.Path != nil && args.UUID != nil {
+		return ErrPathAndUuidProvidedTogether
+	}
+
+	if args.AlbumID != nil {
+		e.outLog.Printf("loading album with id '%s' and append '%t' due to request from %s\n", *args.AlbumID, args.Append, requester)
+		err := e.api.LoadAlbum(*args.AlbumID, args.Append)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Path != nil {
+		e.outLog.Printf("loading file '%s' with '%t' argument due to request from %s\n", *args.Path, args.Append, requester)
+		err := e.api.LoadFile(*args.Path, args.Append)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.UUID != nil {
+		e.outLog.Printf("loading file by UUID '%s' with '%t' argument due to request from %s\n", *args.UUID, args.Append, requester)
+		err := e.api.LoadFileByUuid(*args.UUID, args.Append)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.PlaylistUUID != nil {
+		e.outLog.Printf("loading playlist with uuid '%s' and append '%t' due to request from %s\n", *args.PlaylistUUID, args.Append, requester)
+		err := e.api.LoadPlaylist(*args.PlaylistUUID, args.Append)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.AudioID != nil {
+		e.outLog.Printf("changing audio id to %s due to request from %s\n", *args.AudioID, requester)
+		err := e.api.ChangeAudio(*args.AudioID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Chapter != nil {
+		e.outLog.Printf("changing chapter id to %d due to request from %s\n", *args.Chapter, requester)
+		err := e.api.ChangeChapter(*args.Chapter)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Chapters != nil {
+		if args.UUID != nil {
+			e.api.WaitUntilMediaFileByUuid(*args.UUID)
+		} else if args.Path != nil {
+			e.api.WaitUntilMediaFileByPath(*args.Path)
+		}
+
+		e.outLog.Printf("changing chapters order to %v (forced: %t) due to request from %s\n", args.Chapters, args.Force, requester)
+		err := e.api.ChangeChaptersOrder(args.Chapters, args.Force)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Fullscreen != nil {
+		e.outLog.Printf("changing fullscreen to %t due to request from %s\n", *args.Fullscreen, requester)
+		err := e.api.ChangeFullscreen(*args.Fullscreen)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.LoopFile != nil {
+		e.outLog.Printf("changing file looping to %t due to request from %s\n", *args.LoopFile, requester)
+		err := e.api.LoopFile(*args.LoopFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Pause != nil {
+		e.outLog.Printf("changing pause to %t due to request from %s\n", *args.Pause, requester)
+		err := e.api.ChangePause(*args.Pause)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.PlaylistIdx != nil {
+		e.outLog.Printf("changing playlist idx to %d due to request from %s\n", *args.PlaylistIdx, requester)
+		err := e.api.PlaylistPlayIndex(*args.PlaylistIdx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.SubtitleID != nil {
+		e.outLog.Printf("changing subtitle id to %s due to request from %s\n", *args.SubtitleID, requester)
+		err := e.api.ChangeSubtitle(*args.SubtitleID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if args.Stop {
+		e.outLog.Printf("stopping playback due to request from %s\n", requester)
+		return e.api.StopPlayback()
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package playback_commands
+
+import (
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/api"
+)
+
+type testPluginApi struct {
+	api.PluginApi
+	calls []string
+}
+
+func (tpa *testPluginApi) ChangePause(paused bool) error {
+	tpa.calls = append(tpa.calls, "pause")
+
+	return nil
+}
+
+func (tpa *testPluginApi) LoadFile(path string, appending bool) error {
+	tpa.calls = append(tpa.calls, "load")
+
+	return nil
+}
+
+func (tpa *testPluginApi) StopPlayback() error {
+	tpa.calls = append(tpa.calls, "stop")
+
+	return nil
+}
+
+func TestExecuteLoadsMediaFirst(t *testing.T) {
+	// given
+	testApi := &testPluginApi{}
+	uut := NewExecutor(testApi, log.New(io.Discard, "", 0))
+
+	path := "/media/file.mkv"
+	pause := true
+
+	// when
+	err := uut.Execute(Args{Pause: &pause, Path: &path, Stop: true}, "test")
+
+	// then
+	if err != nil {
+		t.Fatalf("Unexpected error while executing: %s", err)
+	}
+
+	expected := []string{"load", "pause", "stop"}
+	if !reflect.DeepEqual(testApi.calls, expected) {
+		t.Errorf("Expected operations %v, got %v", expected, testApi.calls)
+	}
+}
+
+func TestExecuteRejectsPathAndUuidTogether(t *testing.T) {
+	// given
+	testApi := &testPluginApi{}
+	uut := NewExecutor(testApi, log.New(io.Discard, "", 0))
+
+	path := "/media/file.mkv"
+	uuid := "d3b07384"
+
+	// when
+	err := uut.Execute(Args{Path: &path, UUID: &uuid}, "test")
+
+	// then
+	if !errors.Is(err, ErrPathAndUuidProvidedTogether) {
+		t.Errorf("Expected path and uuid error, got %v", err)
+	}
+
+	if len(testApi.calls) != 0 {
+		t.Errorf("Expected no operations, got %v", testApi.calls)
+	}
+}
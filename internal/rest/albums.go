@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+)
+
+const (
+	albumIDArg = "albumID"
+	artistArg  = "artist"
+)
+
+type getAlbumsRespone struct {
+	Albums []media_files.Album `json:"albums"`
+}
+
+type getAlbumRespone struct {
+	Album  media_files.Album   `json:"album"`
+	Tracks []media_files.Entry `json:"tracks"`
+}
+
+type getArtistsRespone struct {
+	Artists []media_files.Artist `json:"artists"`
+}
+
+func (s *Server) getAlbumsHandler(res http.ResponseWriter, req *http.Request) {
+	stateRevision := s.statesRepository.MediaFiles().Revision()
+	if checkUnfilteredRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	albumsResponse := getAlbumsRespone{
+		Albums: []media_files.Album{},
+	}
+
+	artist := req.URL.Query().Get(artistArg)
+	for _, album := range s.statesRepository.MediaFiles().Albums() {
+		if artist == "" || media_files.ArtistID(album.Artist) == media_files.ArtistID(artist) {
+			albumsResponse.Albums = append(albumsResponse.Albums, album)
+		}
+	}
+
+	response, err := json.Marshal(&albumsResponse)
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
+
+// getAlbumHandler handles path of a single album, eg. "/rest/albums/{id}".
+func (s *Server) getAlbumHandler(res http.ResponseWriter, req *http.Request) {
+	id, err := url.PathUnescape(strings.TrimPrefix(req.URL.Path, albumPathPrefix))
+	if err != nil || id == "" || strings.Contains(id, "/") {
+		res.WriteHeader(404)
+		res.Write([]byte(fmt.Sprintf("album resource not found at '%s'\n", req.URL.Path)))
+
+		return
+	}
+
+	stateRevision := s.statesRepository.MediaFiles().Revision()
+	if checkRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	album, tracks, err := s.statesRepository.MediaFiles().Album(id)
+	if err != nil {
+		res.WriteHeader(404)
+		res.Write([]byte(fmt.Sprintf("album with id '%s' not found\n", id)))
+
+		return
+	}
+
+	response, err := json.Marshal(&getAlbumRespone{Album: album, Tracks: tracks})
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
+
+func (s *Server) getArtistsHandler(res http.ResponseWriter, req *http.Request) {
+	stateRevision := s.statesRepository.MediaFiles().Revision()
+	if checkRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	response, err := json.Marshal(&getArtistsRespone{Artists: s.statesRepository.MediaFiles().Artists()})
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
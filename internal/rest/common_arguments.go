@@ -4,3 +4,9 @@ const (
 	pathArg = "path"
 	uuidArg = "uuid"
 )
+
+const (
+	contentTypeHeader = "Content-Type"
+	jpegContentType   = "image/jpeg"
+	webVTTContentType = "text/vtt"
+)
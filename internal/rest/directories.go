@@ -2,6 +2,7 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,12 +13,16 @@ import (
 )
 
 const (
-	watchedArg = "watched"
+	excludeArg   = "exclude"
+	includeArg   = "include"
+	maxDepthArg  = "maxDepth"
+	polledArg    = "polled"
+	recursiveArg = "recursive"
+	watchedArg   = "watched"
 )
 
 type (
 	addDirectoriesCb    = func([]directories.Entry)
-	loadPlaylistCb      = func(string, bool) error
 	removeDirectoriesCb = func(string) (directories.Entry, error)
 )
 
@@ -95,6 +100,21 @@ func (s *Server) directoriesPathHandler(res http.ResponseWriter, req *http.Reque
 		return err
 	}
 
+	polledDir, err := getPolledArgument(req)
+	if err != nil {
+		return err
+	}
+
+	recursiveDir, err := getRecursiveArgument(req)
+	if err != nil {
+		return err
+	}
+
+	maxDepth, err := getMaxDepthArgument(req)
+	if err != nil {
+		return err
+	}
+
 	path := req.PostFormValue(pathArg)
 
 	if watchedDir {
@@ -105,19 +125,92 @@ func (s *Server) directoriesPathHandler(res http.ResponseWriter, req *http.Reque
 
 	s.addDirectoriesCb([]directories.Entry{
 		{
-			Path:    path,
-			Watched: watchedDir,
+			Exclude:   req.PostForm[excludeArg],
+			Include:   req.PostForm[includeArg],
+			MaxDepth:  maxDepth,
+			Path:      path,
+			Polled:    polledDir,
+			Recursive: recursiveDir,
+			Watched:   watchedDir,
 		},
 	})
 
 	return nil
 }
 
+func getMaxDepthArgument(req *http.Request) (int, error) {
+	maxDepthArgInForm := req.PostFormValue(maxDepthArg)
+	if maxDepthArgInForm == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(maxDepthArgInForm)
+}
+
+func getRecursiveArgument(req *http.Request) (bool, error) {
+	recursiveArgInForm := req.PostFormValue(recursiveArg)
+	if recursiveArgInForm == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(recursiveArgInForm)
+}
+
+func validatePatternsArgument(argName string) common.FormArgumentValidator {
+	return func(req *http.Request) error {
+		for _, pattern := range req.PostForm[argName] {
+			err := directories.ValidatePattern(pattern)
+			if err != nil {
+				return fmt.Errorf("incorrect pattern '%s': %w", pattern, err)
+			}
+		}
+
+		return nil
+	}
+}
+
+func getPolledArgument(req *http.Request) (bool, error) {
+	polledArgInForm := req.PostFormValue(polledArg)
+	if polledArgInForm == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(polledArgInForm)
+}
+
 func (s *Server) postDirectoriesFormArgumentsHandlers() map[string]common.FormArgument {
 	return map[string]common.FormArgument{
+		excludeArg: {
+			Validate: validatePatternsArgument(excludeArg),
+		},
+		includeArg: {
+			Validate: validatePatternsArgument(includeArg),
+		},
+		maxDepthArg: {
+			Validate: func(req *http.Request) error {
+				maxDepth, err := strconv.Atoi(req.PostFormValue(maxDepthArg))
+				if err == nil && maxDepth < 0 {
+					return errors.New("max depth cannot be negative")
+				}
+
+				return err
+			},
+		},
 		pathArg: {
 			Handle: s.directoriesPathHandler,
 		},
+		polledArg: {
+			Validate: func(req *http.Request) error {
+				_, err := strconv.ParseBool(req.PostFormValue(polledArg))
+				return err
+			},
+		},
+		recursiveArg: {
+			Validate: func(req *http.Request) error {
+				_, err := strconv.ParseBool(req.PostFormValue(recursiveArg))
+				return err
+			},
+		},
 		watchedArg: {
 			Validate: func(req *http.Request) error {
 				_, err := strconv.ParseBool(req.PostFormValue(watchedArg))
@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sarpt/mpv-web-api/pkg/duplicates"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+)
+
+type (
+	mediaFileDuplicatesCb = func() ([]duplicates.Group, int)
+)
+
+type duplicatesGroup struct {
+	Hash       string              `json:"hash"`
+	MediaFiles []media_files.Entry `json:"mediaFiles"`
+	Size       int64               `json:"size"`
+}
+
+type getMediaFileDuplicatesRespone struct {
+	Duplicates []duplicatesGroup `json:"duplicates"`
+	Pending    int               `json:"pending"`
+}
+
+func (s *Server) getMediaFileDuplicatesHandler(res http.ResponseWriter, req *http.Request) {
+	groups, pending := s.mediaFileDuplicatesCb()
+
+	duplicatesResponse := getMediaFileDuplicatesRespone{
+		Duplicates: []duplicatesGroup{},
+		Pending:    pending,
+	}
+	for _, group := range groups {
+		duplicates := duplicatesGroup{
+			Hash:       group.Hash,
+			MediaFiles: []media_files.Entry{},
+			Size:       group.Size,
+		}
+
+		for _, path := range group.Paths {
+			mediaFile, err := s.statesRepository.MediaFiles().ByPath(path)
+			if err == nil {
+				duplicates.MediaFiles = append(duplicates.MediaFiles, mediaFile)
+			}
+		}
+
+		if len(duplicates.MediaFiles) > 1 {
+			duplicatesResponse.Duplicates = append(duplicatesResponse.Duplicates, duplicates)
+		}
+	}
+
+	response, err := json.Marshal(&duplicatesResponse)
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	res.WriteHeader(200)
+	res.Write(response)
+}
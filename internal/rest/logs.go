@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
+)
+
+const (
+	levelArg = "level"
+	sinceArg = "since"
+)
+
+type getLogsRespone struct {
+	Logs []logs.Entry `json:"logs"`
+}
+
+func (s *Server) getLogsHandler(res http.ResponseWriter, req *http.Request) {
+	stateRevision := s.statesRepository.Logs().Revision()
+	if checkUnfilteredRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	level, since, err := getLogsQueryArguments(req.URL.Query())
+	if err != nil {
+		res.WriteHeader(400)
+		res.Write([]byte(fmt.Sprintf("could not parse logs query: %s\n", err)))
+
+		return
+	}
+
+	logsResponse := getLogsRespone{
+		Logs: s.statesRepository.Logs().Entries(level, since),
+	}
+
+	response, err := json.Marshal(&logsResponse)
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
+
+func getLogsQueryArguments(args url.Values) (logs.Level, time.Time, error) {
+	level := logs.InfoLevel
+	since := time.Time{}
+
+	var err error
+	if args.Has(levelArg) {
+		level, err = logs.ParseLevel(args.Get(levelArg))
+		if err != nil {
+			return level, since, err
+		}
+	}
+
+	if args.Has(sinceArg) {
+		since, err = time.Parse(time.RFC3339, args.Get(sinceArg))
+		if err != nil {
+			return level, since, fmt.Errorf("%s should be a RFC 3339 timestamp: %w", sinceArg, err)
+		}
+	}
+
+	return level, since, nil
+}
@@ -1,27 +1,109 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/sarpt/mpv-web-api/pkg/api"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/thumbnails"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+const (
+	addedSinceArg       = "addedSince"
+	audioLanguageArg    = "audioLanguage"
+	cursorArg           = "cursor"
+	directoryArg        = "directory"
+	favouriteArg        = "favourite"
+	formatArg           = "format"
+	limitArg            = "limit"
+	maxDurationArg      = "maxDuration"
+	maxHeightArg        = "maxHeight"
+	minDurationArg      = "minDuration"
+	minHeightArg        = "minHeight"
+	minRatingArg        = "minRating"
+	orderArg            = "order"
+	searchArg           = "search"
+	sortArg             = "sort"
+	subtitleLanguageArg = "subtitleLanguage"
+	tagArg              = "tag"
+	unwatchedArg        = "unwatched"
+	widthArg            = "width"
+
+	ascendingOrder  = "asc"
+	descendingOrder = "desc"
+
+	chaptersSubpath  = "chapters"
+	thumbnailSubpath = "thumbnail"
+	trickplaySubpath = "trickplay"
+)
+
+type (
+	mediaFileChapterThumbnailCb = func(context.Context, string, int, int) (string, error)
+	mediaFileThumbnailCb        = func(context.Context, string, int) (string, error)
 )
 
 type getMediaFilesRespone struct {
 	MediaFiles map[string]media_files.Entry `json:"mediaFiles"`
+	NextCursor string                       `json:"nextCursor"`
+	Order      []string                     `json:"order"`
+	Total      int                          `json:"total"`
+}
+
+type getMediaFileRespone struct {
+	MediaFile media_files.Entry `json:"mediaFile"`
 }
 
 func (s *Server) getMediaFilesHandler(res http.ResponseWriter, req *http.Request) {
 	stateRevision := s.statesRepository.MediaFiles().Revision()
-	if checkRevisionIsSame(stateRevision, req) {
+	if checkUnfilteredRevisionIsSame(stateRevision, req) {
 		res.WriteHeader(304)
 		res.Write(nil)
 		return
 	}
 
+	args := req.URL.Query()
+	query, err := getMediaFilesQueryArguments(args)
+	if err != nil {
+		res.WriteHeader(400)
+		res.Write([]byte(fmt.Sprintf("could not parse media files query: %s\n", err)))
+
+		return
+	}
+
+	limit, err := getNonNegativeIntArgument(args, limitArg)
+	if err != nil {
+		res.WriteHeader(400)
+		res.Write([]byte(fmt.Sprintf("could not parse media files query: %s\n", err)))
+
+		return
+	}
+
+	page, err := s.statesRepository.MediaFiles().Query(query, args.Get(cursorArg), limit)
+	if err != nil {
+		res.WriteHeader(400)
+		res.Write([]byte(fmt.Sprintf("could not query media files: %s\n", err)))
+
+		return
+	}
+
 	mediaFilesResponse := getMediaFilesRespone{
-		MediaFiles: s.statesRepository.MediaFiles().All(),
+		MediaFiles: map[string]media_files.Entry{},
+		NextCursor: page.NextCursor,
+		Order:      []string{},
+		Total:      page.Total,
+	}
+	for _, mediaFile := range page.Items {
+		mediaFilesResponse.MediaFiles[mediaFile.Path()] = mediaFile
+		mediaFilesResponse.Order = append(mediaFilesResponse.Order, mediaFile.Path())
 	}
 
 	response, err := json.Marshal(&mediaFilesResponse)
@@ -36,3 +118,295 @@ func (s *Server) getMediaFilesHandler(res http.ResponseWriter, req *http.Request
 	res.WriteHeader(200)
 	res.Write(response)
 }
+
+// getMediaFileSubtreeHandler handles paths under a single media file, eg. "/rest/media-files/{uuid}/thumbnail".
+func (s *Server) getMediaFileSubtreeHandler(res http.ResponseWriter, req *http.Request) {
+	uuid, subpath, ok := parseMediaFilePath(res, req)
+	if !ok {
+		return
+	}
+
+	switch {
+	case subpath == "":
+		s.getMediaFileHandler(res, req, uuid)
+	case subpath == thumbnailSubpath:
+		s.getMediaFileThumbnailHandler(res, req, uuid)
+	case subpath == trickplaySubpath:
+		s.getTrickplayHandler(res, req, uuid)
+	case strings.HasPrefix(subpath, chaptersSubpath+"/"):
+		s.getChapterSubtreeHandler(res, req, uuid, strings.TrimPrefix(subpath, chaptersSubpath+"/"))
+	case strings.HasPrefix(subpath, trickplaySubpath+"/"):
+		s.getTrickplayFileHandler(res, req, uuid, strings.TrimPrefix(subpath, trickplaySubpath+"/"))
+	default:
+		writeUnknownMediaFileResource(res, subpath)
+	}
+}
+
+func (s *Server) deleteMediaFileSubtreeHandler(res http.ResponseWriter, req *http.Request) {
+	uuid, subpath, ok := parseMediaFilePath(res, req)
+	if !ok {
+		return
+	}
+
+	switch subpath {
+	case trickplaySubpath:
+		s.deleteTrickplayHandler(res, req, uuid)
+	default:
+		writeUnknownMediaFileResource(res, subpath)
+	}
+}
+
+// parseMediaFilePath splits path under a single media file into uuid and the rest of the path.
+// When the path does not contain uuid, not found response is written.
+func parseMediaFilePath(res http.ResponseWriter, req *http.Request) (string, string, bool) {
+	uuid, subpath, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, mediaFilePathPrefix), "/")
+	uuid, err := url.PathUnescape(uuid)
+	if err != nil || uuid == "" {
+		res.WriteHeader(404)
+		res.Write([]byte(fmt.Sprintf("media file not found at '%s'\n", req.URL.Path)))
+
+		return "", "", false
+	}
+
+	return uuid, subpath, true
+}
+
+func writeMediaFileNotFound(res http.ResponseWriter, uuid string) {
+	res.WriteHeader(404)
+	res.Write([]byte(fmt.Sprintf("media file with uuid '%s' not found\n", uuid)))
+}
+
+func writeUnknownMediaFileResource(res http.ResponseWriter, subpath string) {
+	res.WriteHeader(404)
+	res.Write([]byte(fmt.Sprintf("unknown media file resource '%s'\n", subpath)))
+}
+
+func (s *Server) getMediaFileHandler(res http.ResponseWriter, req *http.Request, uuid string) {
+	stateRevision := s.statesRepository.MediaFiles().Revision()
+	if checkRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	mediaFile, err := s.statesRepository.MediaFiles().ByUuid(uuid)
+	if err != nil {
+		writeMediaFileNotFound(res, uuid)
+
+		return
+	}
+
+	response, err := json.Marshal(&getMediaFileRespone{MediaFile: mediaFile})
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
+
+func (s *Server) getMediaFileThumbnailHandler(res http.ResponseWriter, req *http.Request, uuid string) {
+	if _, err := s.statesRepository.MediaFiles().ByUuid(uuid); err != nil {
+		writeMediaFileNotFound(res, uuid)
+
+		return
+	}
+
+	width, ok := getWidthArgument(res, req)
+	if !ok {
+		return
+	}
+
+	thumbnailPath, err := s.mediaFileThumbnailCb(req.Context(), uuid, width)
+	if err != nil {
+		writeImageGenerationError(res, req, err)
+
+		return
+	}
+
+	res.Header().Set(contentTypeHeader, jpegContentType)
+	http.ServeFile(res, req, thumbnailPath)
+}
+
+// getChapterSubtreeHandler handles paths under a single chapter of the media file, eg. "/rest/media-files/{uuid}/chapters/{idx}/thumbnail".
+func (s *Server) getChapterSubtreeHandler(res http.ResponseWriter, req *http.Request, uuid string, subpath string) {
+	idx, resource, _ := strings.Cut(subpath, "/")
+	chapterIdx, err := strconv.Atoi(idx)
+	if err != nil || resource != thumbnailSubpath {
+		writeUnknownMediaFileResource(res, chaptersSubpath+"/"+subpath)
+
+		return
+	}
+
+	if _, err := s.statesRepository.MediaFiles().ByUuid(uuid); err != nil {
+		writeMediaFileNotFound(res, uuid)
+
+		return
+	}
+
+	width, ok := getWidthArgument(res, req)
+	if !ok {
+		return
+	}
+
+	thumbnailPath, err := s.mediaFileChapterThumbnailCb(req.Context(), uuid, chapterIdx, width)
+	if err != nil {
+		writeImageGenerationError(res, req, err)
+
+		return
+	}
+
+	res.Header().Set(contentTypeHeader, jpegContentType)
+	http.ServeFile(res, req, thumbnailPath)
+}
+
+// getWidthArgument returns width of the requested image, or the default one when it's not provided.
+// When the width is invalid, bad request response is written.
+func getWidthArgument(res http.ResponseWriter, req *http.Request) (int, bool) {
+	if !req.URL.Query().Has(widthArg) {
+		return thumbnails.DefaultWidth, true
+	}
+
+	width, err := strconv.Atoi(req.URL.Query().Get(widthArg))
+	if err != nil || width < 1 || width > thumbnails.MaxWidth {
+		res.WriteHeader(400)
+		res.Write([]byte(fmt.Sprintf("%s: %s\n", widthArg, thumbnails.ErrInvalidWidth)))
+
+		return 0, false
+	}
+
+	return width, true
+}
+
+// writeImageGenerationError maps errors of lazily generated images to response statuses.
+func writeImageGenerationError(res http.ResponseWriter, req *http.Request, err error) {
+	switch {
+	case req.Context().Err() != nil:
+		return // client went away, nothing to respond to
+	case errors.Is(err, thumbnails.ErrNoImage), errors.Is(err, thumbnails.ErrNoTrickplaySheet), errors.Is(err, api.ErrChapterNotFound):
+		res.WriteHeader(404)
+	case errors.Is(err, thumbnails.ErrQueueFull):
+		res.WriteHeader(503)
+	default:
+		res.WriteHeader(500)
+	}
+
+	res.Write([]byte(fmt.Sprintln(err)))
+}
+
+func getMediaFilesQueryArguments(args url.Values) (media_files.Query, error) {
+	query := media_files.Query{
+		AudioLanguages:    args[audioLanguageArg],
+		Directory:         args.Get(directoryArg),
+		Formats:           args[formatArg],
+		Search:            args.Get(searchArg),
+		SortBy:            media_files.SortField(args.Get(sortArg)),
+		SubtitleLanguages: args[subtitleLanguageArg],
+		Tags:              args[tagArg],
+	}
+
+	switch args.Get(orderArg) {
+	case "", ascendingOrder:
+	case descendingOrder:
+		query.SortDescending = true
+	default:
+		return query, fmt.Errorf("%s should be either '%s' or '%s'", orderArg, ascendingOrder, descendingOrder)
+	}
+
+	var err error
+	query.MinDuration, err = getNonNegativeFloatArgument(args, minDurationArg)
+	if err != nil {
+		return query, err
+	}
+
+	query.MaxDuration, err = getNonNegativeFloatArgument(args, maxDurationArg)
+	if err != nil {
+		return query, err
+	}
+
+	query.MinHeight, err = getNonNegativeIntArgument(args, minHeightArg)
+	if err != nil {
+		return query, err
+	}
+
+	query.MaxHeight, err = getNonNegativeIntArgument(args, maxHeightArg)
+	if err != nil {
+		return query, err
+	}
+
+	query.MinRating, err = getNonNegativeIntArgument(args, minRatingArg)
+	if err != nil {
+		return query, err
+	}
+
+	if query.MinRating > user_metadata.MaxRating {
+		return query, fmt.Errorf("%s: %w", minRatingArg, user_metadata.ErrInvalidRating)
+	}
+
+	if args.Has(favouriteArg) {
+		query.Favourite, err = strconv.ParseBool(args.Get(favouriteArg))
+		if err != nil {
+			return query, fmt.Errorf("%s should be a boolean: %w", favouriteArg, err)
+		}
+	}
+
+	if args.Has(unwatchedArg) {
+		query.Unwatched, err = strconv.ParseBool(args.Get(unwatchedArg))
+		if err != nil {
+			return query, fmt.Errorf("%s should be a boolean: %w", unwatchedArg, err)
+		}
+	}
+
+	if args.Has(addedSinceArg) {
+		addedSince, err := time.Parse(time.RFC3339, args.Get(addedSinceArg))
+		if err != nil {
+			return query, fmt.Errorf("%s should be a RFC 3339 timestamp: %w", addedSinceArg, err)
+		}
+
+		query.AddedSince = &addedSince
+	}
+
+	if err := query.Validate(); err != nil {
+		return query, fmt.Errorf("%s '%s': %w", sortArg, query.SortBy, err)
+	}
+
+	return query, nil
+}
+
+func getNonNegativeIntArgument(args url.Values, argName string) (int, error) {
+	if !args.Has(argName) {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(args.Get(argName))
+	if err != nil {
+		return 0, fmt.Errorf("%s should be an integer: %w", argName, err)
+	}
+
+	if value < 0 {
+		return 0, errors.New(argName + " should not be negative")
+	}
+
+	return value, nil
+}
+
+func getNonNegativeFloatArgument(args url.Values, argName string) (float64, error) {
+	if !args.Has(argName) {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(args.Get(argName), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s should be a number: %w", argName, err)
+	}
+
+	if value < 0 {
+		return 0, errors.New(argName + " should not be negative")
+	}
+
+	return value, nil
+}
@@ -2,13 +2,13 @@ package rest
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/internal/playback_commands"
 )
 
 const (
@@ -26,26 +26,6 @@ const (
 	subtitleIDArg   = "subtitleID"
 )
 
-var (
-	ErrPathAndUuidProvidedTogether = errors.New("path and uuid arguments should not be provided together in the same request")
-)
-
-type (
-	loadFileCb                 func(string, bool) error
-	loadFileByUuidCb           func(string, bool) error
-	changeFullscreenCb         func(bool) error
-	changeAudioCb              func(string) error
-	changeChapterCb            func(int64) error
-	changeSubtitleCb           func(string) error
-	loopFileCb                 func(bool) error
-	changePauseCb              func(bool) error
-	changeChaptersOrderCb      func([]int64, bool) error
-	playlistPlayIndexCb        func(int) error
-	stopPlaybackCb             func() error
-	waitUntilMediaFileByPathCb func(string) error
-	waitUntilMediaFileByUuidCb func(string) error
-)
-
 func (s *Server) getPlaybackHandler(res http.ResponseWriter, req *http.Request) {
 	stateRevision := s.statesRepository.Playback().Revision()
 	if checkRevisionIsSame(stateRevision, req) {
@@ -67,151 +47,115 @@ func (s *Server) getPlaybackHandler(res http.ResponseWriter, req *http.Request)
 	res.Write(json)
 }
 
-func (s *Server) pathHandler(res http.ResponseWriter, req *http.Request) error {
-	filePath := req.PostFormValue(pathArg)
-
-	append, err := getAppendArgument(req)
+// postPlaybackHandler executes playback operations of the arguments. Arguments are already validated at this point.
+func (s *Server) postPlaybackHandler(res http.ResponseWriter, req *http.Request) error {
+	args, err := playbackCommandArgs(req)
 	if err != nil {
 		return err
 	}
 
-	s.outLog.Printf("loading file '%s' with '%t' argument due to request from %s\n", filePath, append, req.RemoteAddr)
-
-	return s.loadFileCb(filePath, append)
+	return s.playbackCommands.Execute(args, req.RemoteAddr)
 }
 
-func (s *Server) uuidHandler(res http.ResponseWriter, req *http.Request) error {
-	uuid := req.PostFormValue(uuidArg)
+// playbackCommandArgs reads arguments provided in the form. Arguments not present in the form are left empty.
+func playbackCommandArgs(req *http.Request) (playback_commands.Args, error) {
+	args := playback_commands.Args{}
 
-	append, err := getAppendArgument(req)
+	var err error
+	args.Append, err = getAppendArgument(req)
 	if err != nil {
-		return err
+		return args, err
 	}
 
-	s.outLog.Printf("loading file by UUID '%s' with '%t' argument due to request from %s\n", uuid, append, req.RemoteAddr)
-
-	return s.loadFileByUuidCb(uuid, append)
-}
-
-func (s *Server) fullscreenHandler(res http.ResponseWriter, req *http.Request) error {
-	fullscreen, err := strconv.ParseBool(req.PostFormValue(fullscreenArg))
+	args.Force, err = getForceArgument(req)
 	if err != nil {
-		return err
+		return args, err
 	}
 
-	s.outLog.Printf("changing fullscreen to %t due to request from %s\n", fullscreen, req.RemoteAddr)
-	return s.changeFullscreenCb(fullscreen)
-}
-
-func (s *Server) audioIDHandler(res http.ResponseWriter, req *http.Request) error {
-	audioID := req.PostFormValue(audioIDArg)
+	args.AlbumID = optionalFormValue(req, albumIDArg)
+	args.AudioID = optionalFormValue(req, audioIDArg)
+	args.Path = optionalFormValue(req, pathArg)
+	args.PlaylistUUID = optionalFormValue(req, playlistUUIDArg)
+	args.SubtitleID = optionalFormValue(req, subtitleIDArg)
+	args.UUID = optionalFormValue(req, uuidArg)
 
-	s.outLog.Printf("changing audio id to %s due to request from %s\n", audioID, req.RemoteAddr)
-	return s.changeAudioCb(audioID)
-}
+	if value := optionalFormValue(req, chapterArg); value != nil {
+		chapterIdx, err := strconv.ParseInt(*value, 10, 64)
+		if err != nil {
+			return args, err
+		}
 
-func (s *Server) chapterHandler(res http.ResponseWriter, req *http.Request) error {
-	chapterIdx, err := strconv.ParseInt(req.PostFormValue(chapterArg), 10, 64)
-	if err != nil {
-		return err
+		args.Chapter = &chapterIdx
 	}
 
-	s.outLog.Printf("changing chapter id to %d due to request from %s\n", chapterIdx, req.RemoteAddr)
-	return s.changeChapterCb(chapterIdx)
-}
-
-func (s *Server) chaptersHandler(res http.ResponseWriter, req *http.Request) error {
-	providedChaptersArg := req.PostFormValue(chaptersArgs)
-	chapters := strings.Split(providedChaptersArg, ",")
+	if value := optionalFormValue(req, chaptersArgs); value != nil {
+		args.Chapters = []int64{}
+		for _, chapter := range strings.Split(*value, ",") {
+			chapterId, err := strconv.Atoi(chapter)
+			if err != nil {
+				return args, err
+			}
 
-	chapterIds := []int64{}
-	for _, chapter := range chapters {
-		chapterId, err := strconv.Atoi(chapter)
-		if err != nil {
-			return err
+			args.Chapters = append(args.Chapters, int64(chapterId))
 		}
-
-		chapterIds = append(chapterIds, int64(chapterId))
 	}
 
-	force, err := getForceArgument(req)
+	args.Fullscreen, err = optionalBoolFormValue(req, fullscreenArg)
 	if err != nil {
-		return err
+		return args, err
 	}
 
-	filePath := req.PostFormValue(pathArg)
-	uuid := req.PostFormValue(uuidArg)
-	if uuid != "" {
-		s.waitUntilMediaFileByUuidCb(uuid)
-	} else if filePath != "" {
-		s.waitUntilMediaFileByPathCb(filePath)
+	args.LoopFile, err = optionalBoolFormValue(req, loopFileArg)
+	if err != nil {
+		return args, err
 	}
 
-	s.outLog.Printf("changing chapters order to %s (forced: %t) due to request from %s\n", providedChaptersArg, force, req.RemoteAddr)
-	return s.changeChaptersOrderCb(chapterIds, force)
-}
-
-func (s *Server) subtitleIDHandler(res http.ResponseWriter, req *http.Request) error {
-	subtitleID := req.PostFormValue(subtitleIDArg)
-
-	s.outLog.Printf("changing subtitle id to %s due to request from %s\n", subtitleID, req.RemoteAddr)
-	return s.changeSubtitleCb(subtitleID)
-}
-
-func (s *Server) loopFileHandler(res http.ResponseWriter, req *http.Request) error {
-	loopFile, err := strconv.ParseBool(req.PostFormValue(loopFileArg))
+	args.Pause, err = optionalBoolFormValue(req, pauseArg)
 	if err != nil {
-		return err
+		return args, err
 	}
 
-	s.outLog.Printf("changing file looping to %t due to request from %s\n", loopFile, req.RemoteAddr)
-	return s.loopFileCb(loopFile)
-}
+	if value := optionalFormValue(req, playlistIdxArg); value != nil {
+		idx, err := strconv.Atoi(*value)
+		if err != nil {
+			return args, err
+		}
 
-func (s *Server) pauseHandler(res http.ResponseWriter, req *http.Request) error {
-	pause, err := strconv.ParseBool(req.PostFormValue(pauseArg))
-	if err != nil {
-		return err
+		args.PlaylistIdx = &idx
 	}
 
-	s.outLog.Printf("changing pause to %t due to request from %s\n", pause, req.RemoteAddr)
-	return s.changePauseCb(pause)
-}
-
-func (s *Server) playlistIdxHandler(res http.ResponseWriter, req *http.Request) error {
-	idx, err := strconv.Atoi(req.PostFormValue(playlistIdxArg))
+	stop, err := optionalBoolFormValue(req, stopArg)
 	if err != nil {
-		return err
+		return args, err
 	}
 
-	s.outLog.Printf("changing playlist idx to %d due to request from %s\n", idx, req.RemoteAddr)
-	return s.playlistPlayIndexCb(idx)
-}
+	args.Stop = stop != nil && *stop
 
-func (s *Server) playlistUUIDHandler(res http.ResponseWriter, req *http.Request) error {
-	uuid := req.PostFormValue(playlistUUIDArg)
+	return args, nil
+}
 
-	append, err := getAppendArgument(req)
-	if err != nil {
-		return err
+// optionalFormValue returns value of the argument, or nil when the argument is not present in the form.
+func optionalFormValue(req *http.Request, argName string) *string {
+	if _, ok := req.PostForm[argName]; !ok {
+		return nil
 	}
 
-	s.outLog.Printf("loading playlist with uuid '%s' and append '%t' due to request from %s\n", uuid, append, req.RemoteAddr)
-	return s.loadPlaylistCb(uuid, append)
+	value := req.PostFormValue(argName)
+	return &value
 }
 
-func (s *Server) stopHandler(res http.ResponseWriter, req *http.Request) error {
-	stop, err := strconv.ParseBool(req.PostFormValue(stopArg))
-	if err != nil {
-		return err
+func optionalBoolFormValue(req *http.Request, argName string) (*bool, error) {
+	value := optionalFormValue(req, argName)
+	if value == nil {
+		return nil, nil
 	}
 
-	if !stop {
-		return nil
+	parsed, err := strconv.ParseBool(*value)
+	if err != nil {
+		return nil, err
 	}
 
-	s.outLog.Printf("stopping playback due to request from %s\n", req.RemoteAddr)
-	return s.stopPlaybackCb()
+	return &parsed, nil
 }
 
 func getAppendArgument(req *http.Request) (bool, error) {
@@ -236,20 +180,16 @@ func getForceArgument(req *http.Request) (bool, error) {
 
 func (s *Server) postPlaybackFormArgumentsHandlers() map[string]common.FormArgument {
 	return map[string]common.FormArgument{
+		albumIDArg: {},
 		appendArg: {
 			Validate: func(req *http.Request) error {
 				_, err := strconv.ParseBool(req.PostFormValue(appendArg))
 				return err
 			},
 		},
-		audioIDArg: {
-			Handle: s.audioIDHandler,
-		},
-		chapterArg: {
-			Handle: s.chapterHandler,
-		},
+		audioIDArg: {},
+		chapterArg: {},
 		chaptersArgs: {
-			Handle: s.chaptersHandler,
 			Validate: func(req *http.Request) error {
 				chapters := strings.Split(req.PostFormValue(chaptersArgs), ",")
 				for _, chapter := range chapters {
@@ -271,66 +211,52 @@ func (s *Server) postPlaybackFormArgumentsHandlers() map[string]common.FormArgum
 			},
 		},
 		fullscreenArg: {
-			Handle: s.fullscreenHandler,
 			Validate: func(req *http.Request) error {
 				_, err := strconv.ParseBool(req.PostFormValue(fullscreenArg))
 				return err
 			},
 		},
 		loopFileArg: {
-			Handle: s.loopFileHandler,
 			Validate: func(req *http.Request) error {
 				_, err := strconv.ParseBool(req.PostFormValue(loopFileArg))
 				return err
 			},
 		},
 		pathArg: {
-			Handle:   s.pathHandler,
-			Priority: 1,
 			Validate: func(req *http.Request) error {
 				uuid := req.PostFormValue(uuidArg)
 				if uuid != "" {
-					return ErrPathAndUuidProvidedTogether
+					return playback_commands.ErrPathAndUuidProvidedTogether
 				}
 
 				return nil
 			},
 		},
 		uuidArg: {
-			Handle:   s.uuidHandler,
-			Priority: 1,
 			Validate: func(req *http.Request) error {
 				path := req.PostFormValue(pathArg)
 				if path != "" {
-					return ErrPathAndUuidProvidedTogether
+					return playback_commands.ErrPathAndUuidProvidedTogether
 				}
 
 				return nil
 			},
 		},
 		pauseArg: {
-			Handle: s.pauseHandler,
 			Validate: func(req *http.Request) error {
 				_, err := strconv.ParseBool(req.PostFormValue(pauseArg))
 				return err
 			},
 		},
 		playlistIdxArg: {
-			Handle: s.playlistIdxHandler,
 			Validate: func(req *http.Request) error {
 				_, err := strconv.Atoi(req.PostFormValue(playlistIdxArg))
 				return err
 			},
 		},
-		playlistUUIDArg: {
-			Handle:   s.playlistUUIDHandler,
-			Priority: 1,
-		},
-		subtitleIDArg: {
-			Handle: s.subtitleIDHandler,
-		},
+		playlistUUIDArg: {},
+		subtitleIDArg:   {},
 		stopArg: {
-			Handle: s.stopHandler,
 			Validate: func(req *http.Request) error {
 				_, err := strconv.ParseBool(req.PostFormValue(stopArg))
 				return err
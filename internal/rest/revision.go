@@ -22,3 +22,10 @@ func checkRevisionIsSame(stateRevision uint64, req *http.Request) bool {
 func setRevisionInResponse(stateRevision uint64, res http.ResponseWriter) {
 	res.Header().Add(revisionHeader, fmt.Sprintf("%d", stateRevision))
 }
+
+// checkUnfilteredRevisionIsSame checks the revision only for requests without query arguments.
+// The revision describes the whole state and not the part selected by the arguments (eg. a page or filtered entries),
+// so responses to such requests are never reported as unchanged.
+func checkUnfilteredRevisionIsSame(stateRevision uint64, req *http.Request) bool {
+	return req.URL.RawQuery == "" && checkRevisionIsSame(stateRevision, req)
+}
@@ -7,16 +7,25 @@ import (
 )
 
 const (
-	mediaFilesPath  = "/rest/media-files"
-	directoriesPath = "/rest/directories"
-	playbackPath    = "/rest/playback"
-	playlistsPath   = "/rest/playlists"
+	albumsPath          = "/rest/albums"
+	albumPathPrefix     = albumsPath + "/"
+	artistsPath         = "/rest/artists"
+	mediaFilesPath      = "/rest/media-files"
+	mediaFilePathPrefix = mediaFilesPath + "/"
+	duplicatesPath      = mediaFilesPath + "/duplicates"
+	directoriesPath     = "/rest/directories"
+	logsPath            = "/rest/logs"
+	playbackPath        = "/rest/playback"
+	playlistsPath       = "/rest/playlists"
+	showsPath           = "/rest/shows"
+	showPathPrefix      = showsPath + "/"
+	userMetadataPath    = "/rest/user-metadata"
 )
 
 // Handler returns http.Handler responsible for REST handling subtree.
 func (s *Server) Handler() http.Handler {
 	playbackHandlers := map[string]http.HandlerFunc{
-		http.MethodPost: common.CreateFormHandler(s.postPlaybackFormArgumentsHandlers()),
+		http.MethodPost: common.CreateFormCommandHandler(s.postPlaybackFormArgumentsHandlers(), s.postPlaybackHandler),
 		http.MethodGet:  s.getPlaybackHandler,
 	}
 
@@ -24,10 +33,47 @@ func (s *Server) Handler() http.Handler {
 		http.MethodGet: s.getMediaFilesHandler,
 	}
 
+	mediaFileHandlers := map[string]http.HandlerFunc{
+		http.MethodGet:    s.getMediaFileSubtreeHandler,
+		http.MethodDelete: s.deleteMediaFileSubtreeHandler,
+	}
+
+	duplicatesHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getMediaFileDuplicatesHandler,
+	}
+
 	playlistsHandlers := map[string]http.HandlerFunc{
 		http.MethodGet: s.getPlaylistsHandler,
 	}
 
+	showsHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getShowsHandler,
+	}
+
+	showHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getShowSubtreeHandler,
+	}
+
+	albumsHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getAlbumsHandler,
+	}
+
+	albumHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getAlbumHandler,
+	}
+
+	artistsHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getArtistsHandler,
+	}
+
+	logsHandlers := map[string]http.HandlerFunc{
+		http.MethodGet: s.getLogsHandler,
+	}
+
+	userMetadataHandlers := map[string]http.HandlerFunc{
+		http.MethodPost: common.CreateFormHandler(s.postUserMetadataFormArgumentsHandlers()),
+	}
+
 	directoriesHandlers := map[string]http.HandlerFunc{
 		http.MethodGet:    s.getDirectoriesHandler,
 		http.MethodPost:   common.CreateFormHandler(s.postDirectoriesFormArgumentsHandlers()),
@@ -35,10 +81,19 @@ func (s *Server) Handler() http.Handler {
 	}
 
 	allHandlers := map[string]common.MethodHandlers{
-		playbackPath:    playbackHandlers,
-		mediaFilesPath:  mediaFilesHandlers,
-		directoriesPath: directoriesHandlers,
-		playlistsPath:   playlistsHandlers,
+		playbackPath:        playbackHandlers,
+		mediaFilesPath:      mediaFilesHandlers,
+		mediaFilePathPrefix: mediaFileHandlers,
+		duplicatesPath:      duplicatesHandlers,
+		directoriesPath:     directoriesHandlers,
+		logsPath:            logsHandlers,
+		playlistsPath:       playlistsHandlers,
+		showsPath:           showsHandlers,
+		showPathPrefix:      showHandlers,
+		albumsPath:          albumsHandlers,
+		albumPathPrefix:     albumHandlers,
+		artistsPath:         artistsHandlers,
+		userMetadataPath:    userMetadataHandlers,
 	}
 
 	mux := http.NewServeMux()
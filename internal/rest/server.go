@@ -4,8 +4,10 @@ import (
 	"io"
 	"log"
 
+	"github.com/sarpt/mpv-web-api/internal/playback_commands"
 	"github.com/sarpt/mpv-web-api/pkg/api"
 	"github.com/sarpt/mpv-web-api/pkg/state"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
 )
 
 const (
@@ -25,21 +27,14 @@ type Config struct {
 
 type Callbacks struct {
 	addDirectoriesCb
-	changeChaptersOrderCb
+	cancelMediaFileTrickplayCb
 	removeDirectoriesCb
-	loadPlaylistCb
-	loadFileCb
-	loadFileByUuidCb
-	changeFullscreenCb
-	changeAudioCb
-	changeChapterCb
-	changeSubtitleCb
-	loopFileCb
-	changePauseCb
-	playlistPlayIndexCb
-	stopPlaybackCb
-	waitUntilMediaFileByPathCb
-	waitUntilMediaFileByUuidCb
+	mediaFileChapterThumbnailCb
+	mediaFileDuplicatesCb
+	mediaFileThumbnailCb
+	mediaFileTrickplayCb
+	mediaFileTrickplaySheetCb
+	setUserMetadataCb
 }
 
 // Server is responsible for creating REST handlers, argument parsing and validation.
@@ -50,37 +45,33 @@ type Server struct {
 	allowCORS        bool
 	errLog           *log.Logger
 	outLog           *log.Logger
+	playbackCommands *playback_commands.Executor
 	statesRepository state.Repository
 }
 
 // NewServer returns rest.Server instance.
 func NewServer(cfg Config) *Server {
+	outWriter, errWriter := cfg.StatesRepository.Logs().TeeWriters(logs.RestSource, cfg.OutWriter, cfg.ErrWriter)
+
 	return &Server{
 		allowCORS:        cfg.AllowCORS,
-		errLog:           log.New(cfg.ErrWriter, logPrefix, log.LstdFlags),
-		outLog:           log.New(cfg.OutWriter, logPrefix, log.LstdFlags),
+		errLog:           log.New(errWriter, logPrefix, log.LstdFlags),
+		outLog:           log.New(outWriter, logPrefix, log.LstdFlags),
 		statesRepository: cfg.StatesRepository,
 	}
 }
 
 func (s *Server) Init(apiServer api.PluginApi) error {
+	s.playbackCommands = playback_commands.NewExecutor(apiServer, s.outLog)
 	s.addDirectoriesCb = apiServer.AddRootDirectories
 	s.removeDirectoriesCb = apiServer.TakeDirectory
-	s.loadPlaylistCb = apiServer.LoadPlaylist
-
-	s.loadFileCb = apiServer.LoadFile
-	s.loadFileByUuidCb = apiServer.LoadFileByUuid
-	s.changeFullscreenCb = apiServer.ChangeFullscreen
-	s.changeAudioCb = apiServer.ChangeAudio
-	s.changeChapterCb = apiServer.ChangeChapter
-	s.changeSubtitleCb = apiServer.ChangeSubtitle
-	s.loopFileCb = apiServer.LoopFile
-	s.changePauseCb = apiServer.ChangePause
-	s.playlistPlayIndexCb = apiServer.PlaylistPlayIndex
-	s.stopPlaybackCb = apiServer.StopPlayback
-	s.changeChaptersOrderCb = apiServer.ChangeChaptersOrder
-	s.waitUntilMediaFileByPathCb = apiServer.WaitUntilMediaFileByPath
-	s.waitUntilMediaFileByUuidCb = apiServer.WaitUntilMediaFileByUuid
+	s.mediaFileThumbnailCb = apiServer.MediaFileThumbnail
+	s.mediaFileChapterThumbnailCb = apiServer.MediaFileChapterThumbnail
+	s.mediaFileDuplicatesCb = apiServer.MediaFileDuplicates
+	s.mediaFileTrickplayCb = apiServer.MediaFileTrickplay
+	s.mediaFileTrickplaySheetCb = apiServer.MediaFileTrickplaySheet
+	s.cancelMediaFileTrickplayCb = apiServer.CancelMediaFileTrickplay
+	s.setUserMetadataCb = apiServer.SetUserMetadata
 
 	return nil
 }
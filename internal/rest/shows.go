@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+)
+
+const (
+	seasonsSubpath = "seasons"
+)
+
+type getShowsRespone struct {
+	Shows []media_files.Show `json:"shows"`
+}
+
+type getShowSeasonsRespone struct {
+	Seasons []media_files.Season `json:"seasons"`
+}
+
+func (s *Server) getShowsHandler(res http.ResponseWriter, req *http.Request) {
+	stateRevision := s.statesRepository.MediaFiles().Revision()
+	if checkRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	showsResponse := getShowsRespone{
+		Shows: s.statesRepository.MediaFiles().Shows(),
+	}
+
+	response, err := json.Marshal(&showsResponse)
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
+
+// getShowSubtreeHandler handles paths under a single show, eg. "/rest/shows/{id}/seasons".
+func (s *Server) getShowSubtreeHandler(res http.ResponseWriter, req *http.Request) {
+	id, subpath, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, showPathPrefix), "/")
+	id, err := url.PathUnescape(id)
+	if err != nil || id == "" || subpath != seasonsSubpath {
+		res.WriteHeader(404)
+		res.Write([]byte(fmt.Sprintf("show resource not found at '%s'\n", req.URL.Path)))
+
+		return
+	}
+
+	stateRevision := s.statesRepository.MediaFiles().Revision()
+	if checkRevisionIsSame(stateRevision, req) {
+		res.WriteHeader(304)
+		res.Write(nil)
+		return
+	}
+
+	seasons, err := s.statesRepository.MediaFiles().ShowSeasons(id)
+	if err != nil {
+		res.WriteHeader(404)
+		res.Write([]byte(fmt.Sprintf("show with id '%s' not found\n", id)))
+
+		return
+	}
+
+	response, err := json.Marshal(&getShowSeasonsRespone{Seasons: seasons})
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	setRevisionInResponse(stateRevision, res)
+	res.WriteHeader(200)
+	res.Write(response)
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sarpt/mpv-web-api/pkg/thumbnails"
+)
+
+const (
+	trickplayWebVTTFilename = "index.vtt"
+	trickplaySheetExtension = ".jpg"
+)
+
+type (
+	cancelMediaFileTrickplayCb = func(string) (bool, error)
+	mediaFileTrickplayCb       = func(string) (thumbnails.TrickplayIndex, error)
+	mediaFileTrickplaySheetCb  = func(string, int) (string, error)
+)
+
+type getTrickplayRespone struct {
+	Trickplay thumbnails.TrickplayIndex `json:"trickplay"`
+}
+
+func (s *Server) getTrickplayHandler(res http.ResponseWriter, req *http.Request, uuid string) {
+	index, ok := s.trickplayIndex(res, req, uuid)
+	if !ok {
+		return
+	}
+
+	response, err := json.Marshal(&getTrickplayRespone{Trickplay: index})
+	if err != nil {
+		res.WriteHeader(500)
+		res.Write([]byte(fmt.Sprintln("could not prepare output")))
+
+		return
+	}
+
+	res.WriteHeader(200)
+	res.Write(response)
+}
+
+// getTrickplayFileHandler serves WebVTT index (index.vtt) or sprite sheets ({sheet}.jpg).
+func (s *Server) getTrickplayFileHandler(res http.ResponseWriter, req *http.Request, uuid string, filename string) {
+	if filename == trickplayWebVTTFilename {
+		index, ok := s.trickplayIndex(res, req, uuid)
+		if !ok {
+			return
+		}
+
+		res.Header().Set(contentTypeHeader, webVTTContentType)
+		res.WriteHeader(200)
+		res.Write(index.WebVTT(func(sheet int) string {
+			return fmt.Sprintf("%d%s", sheet, trickplaySheetExtension) // relative to the WebVTT file
+		}))
+
+		return
+	}
+
+	sheet, err := strconv.Atoi(strings.TrimSuffix(filename, trickplaySheetExtension))
+	if err != nil || !strings.HasSuffix(filename, trickplaySheetExtension) {
+		writeUnknownMediaFileResource(res, trickplaySubpath+"/"+filename)
+
+		return
+	}
+
+	if _, err := s.statesRepository.MediaFiles().ByUuid(uuid); err != nil {
+		writeMediaFileNotFound(res, uuid)
+
+		return
+	}
+
+	sheetPath, err := s.mediaFileTrickplaySheetCb(uuid, sheet)
+	if err != nil {
+		writeTrickplayError(res, req, err)
+
+		return
+	}
+
+	res.Header().Set(contentTypeHeader, jpegContentType)
+	http.ServeFile(res, req, sheetPath)
+}
+
+func (s *Server) deleteTrickplayHandler(res http.ResponseWriter, req *http.Request, uuid string) {
+	if _, err := s.statesRepository.MediaFiles().ByUuid(uuid); err != nil {
+		writeMediaFileNotFound(res, uuid)
+
+		return
+	}
+
+	cancelled, err := s.cancelMediaFileTrickplayCb(uuid)
+	if err != nil {
+		writeImageGenerationError(res, req, err)
+
+		return
+	}
+
+	if !cancelled {
+		res.WriteHeader(404)
+		res.Write([]byte(fmt.Sprintf("trickplay generation for media file '%s' is not in progress\n", uuid)))
+
+		return
+	}
+
+	res.WriteHeader(200)
+}
+
+func (s *Server) trickplayIndex(res http.ResponseWriter, req *http.Request, uuid string) (thumbnails.TrickplayIndex, bool) {
+	if _, err := s.statesRepository.MediaFiles().ByUuid(uuid); err != nil {
+		writeMediaFileNotFound(res, uuid)
+
+		return thumbnails.TrickplayIndex{}, false
+	}
+
+	index, err := s.mediaFileTrickplayCb(uuid)
+	if err != nil {
+		writeTrickplayError(res, req, err)
+
+		return index, false
+	}
+
+	return index, true
+}
+
+// writeTrickplayError responds with 202 Accepted when sheets are still being generated - client should retry later.
+func writeTrickplayError(res http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, thumbnails.ErrTrickplayPending) {
+		res.WriteHeader(202)
+		res.Write([]byte(fmt.Sprintln(err)))
+
+		return
+	}
+
+	writeImageGenerationError(res, req, err)
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+const (
+	notesArg  = "notes"
+	ratingArg = "rating"
+)
+
+type (
+	setUserMetadataCb = func(string, user_metadata.Update) (user_metadata.Metadata, error)
+)
+
+func (s *Server) userMetadataPathHandler(res http.ResponseWriter, req *http.Request) error {
+	var update user_metadata.Update
+
+	if _, ok := req.PostForm[favouriteArg]; ok {
+		favourite, err := strconv.ParseBool(req.PostFormValue(favouriteArg))
+		if err != nil {
+			return err
+		}
+
+		update.Favourite = &favourite
+	}
+
+	if _, ok := req.PostForm[notesArg]; ok {
+		notes := req.PostFormValue(notesArg)
+		update.Notes = &notes
+	}
+
+	if _, ok := req.PostForm[ratingArg]; ok {
+		rating, err := strconv.Atoi(req.PostFormValue(ratingArg))
+		if err != nil {
+			return err
+		}
+
+		update.Rating = &rating
+	}
+
+	if tags, ok := req.PostForm[tagArg]; ok {
+		update.Tags = &tags
+	}
+
+	if _, ok := req.PostForm[watchedArg]; ok {
+		watched, err := strconv.ParseBool(req.PostFormValue(watchedArg))
+		if err != nil {
+			return err
+		}
+
+		update.Watched = &watched
+	}
+
+	path := req.PostFormValue(pathArg)
+	s.outLog.Printf("changing user metadata of '%s' due to request from %s\n", path, req.RemoteAddr)
+	_, err := s.setUserMetadataCb(path, update)
+
+	return err
+}
+
+func (s *Server) postUserMetadataFormArgumentsHandlers() map[string]common.FormArgument {
+	return map[string]common.FormArgument{
+		favouriteArg: {
+			Validate: func(req *http.Request) error {
+				_, err := strconv.ParseBool(req.PostFormValue(favouriteArg))
+				return err
+			},
+		},
+		notesArg: {},
+		pathArg: {
+			Handle: s.userMetadataPathHandler,
+		},
+		ratingArg: {
+			Validate: func(req *http.Request) error {
+				rating, err := strconv.Atoi(req.PostFormValue(ratingArg))
+				if err == nil && (rating < 0 || rating > user_metadata.MaxRating) {
+					return user_metadata.ErrInvalidRating
+				}
+
+				return err
+			},
+		},
+		tagArg: {},
+		watchedArg: {
+			Validate: func(req *http.Request) error {
+				_, err := strconv.ParseBool(req.PostFormValue(watchedArg))
+				return err
+			},
+		},
+	}
+}
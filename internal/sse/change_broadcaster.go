@@ -11,50 +11,96 @@ type Change interface {
 	MarshalJSON() ([]byte, error)
 }
 
+// ChangesBroadcaster distributes changes of a channel to its observers.
+// Every broadcasted change gets the next revision of the channel, so changes are numbered in the order
+// they were broadcasted, regardless of how far the state moved on before the change reached the channel.
 type ChangesBroadcaster[CT Change] struct {
 	lock      *sync.RWMutex
-	observers map[string]chan CT
+	observers map[string]*observerQueue[CT]
+	queueCfg  QueueConfig
+	recent    *recentChanges[CT]
+	revision  *uint64
 }
 
-func NewChangesBroadcaster[CT Change]() ChangesBroadcaster[CT] {
+// NewChangesBroadcaster constructs broadcaster of changes to the channel observers.
+func NewChangesBroadcaster[CT Change](queueCfg QueueConfig) ChangesBroadcaster[CT] {
 	return ChangesBroadcaster[CT]{
 		lock:      &sync.RWMutex{},
-		observers: map[string]chan CT{},
+		observers: map[string]*observerQueue[CT]{},
+		queueCfg:  queueCfg,
+		recent:    newRecentChanges[CT](recentChangesSize, 0),
+		revision:  new(uint64),
 	}
 }
 
-func (st *ChangesBroadcaster[CT]) AddObserver(address string) {
-	changes := make(chan CT)
+func (st *ChangesBroadcaster[CT]) AddObserver(observerID string) {
+	changes := newObserverQueue[CT](st.queueCfg)
 
 	st.lock.Lock()
 	defer st.lock.Unlock()
 
-	st.observers[address] = changes
+	st.observers[observerID] = changes
 }
 
-func (st *ChangesBroadcaster[CT]) RemoveObserver(address string) {
+// AddResumingObserver adds observer which already received changes up to the provided revision.
+// Returns changes broadcasted after the revision, which the observer missed. When the missed
+// changes are no longer available, false is returned and the observer should be replayed instead.
+func (st *ChangesBroadcaster[CT]) AddResumingObserver(observerID string, revision uint64) ([]revisionedChange[CT], bool) {
+	changes := newObserverQueue[CT](st.queueCfg)
+
 	st.lock.Lock()
 	defer st.lock.Unlock()
 
-	changes, ok := st.observers[address]
+	st.observers[observerID] = changes
+	if revision > *st.revision {
+		return nil, false
+	}
+
+	return st.recent.since(revision)
+}
+
+func (st *ChangesBroadcaster[CT]) RemoveObserver(observerID string) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	changes, ok := st.observers[observerID]
 	if !ok {
 		return
 	}
 
-	close(changes)
-	delete(st.observers, address)
+	changes.close()
+	delete(st.observers, observerID)
 }
 
+// BroadcastToChannelObservers queues the change for every observer of the channel without waiting for observers to receive it.
 func (st *ChangesBroadcaster[CT]) BroadcastToChannelObservers(change CT) {
-	st.lock.RLock()
-	defer st.lock.RUnlock()
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	*st.revision++
+	revisioned := revisionedChange[CT]{
+		change:   change,
+		revision: *st.revision,
+	}
+	st.recent.push(revisioned)
 
 	for _, observer := range st.observers {
-		observer <- change
+		observer.push(revisioned)
 	}
 }
 
-func (st *ChangesBroadcaster[CT]) Observer(address string) (chan CT, bool) {
-	observer, ok := st.observers[address]
+func (st *ChangesBroadcaster[CT]) Observer(observerID string) (*observerQueue[CT], bool) {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	observer, ok := st.observers[observerID]
 	return observer, ok
 }
+
+// Revision returns the revision of the latest change broadcasted on the channel.
+func (st *ChangesBroadcaster[CT]) Revision() uint64 {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	return *st.revision
+}
@@ -1,13 +1,16 @@
 package sse
 
 import (
+	"github.com/sarpt/mpv-web-api/internal/common"
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
 )
 
 type channel interface {
-	AddObserver(address string)
-	RemoveObserver(address string)
+	AddObserver(observerID string)
+	ChangeVariants() []common.ChangeVariant
+	RemoveObserver(observerID string)
 	Replay(res ResponseWriter) error
-	ServeObserver(address string, res ResponseWriter, done chan<- bool, errors chan<- error)
+	ResumeObserver(observerID string, res ResponseWriter, revision uint64) error
+	ServeObserver(observerID string, res ResponseWriter, done chan<- bool, errors chan<- error)
 	Variant() state_sse.ChannelVariant
 }
@@ -2,10 +2,15 @@ package sse
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
+
+	"github.com/google/uuid"
 
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
 )
@@ -13,14 +18,19 @@ import (
 var (
 	sseEventEnd = []byte("\n\n")
 
-	errResponseJSONCreationFailed = errors.New("could not create JSON for response")
-	errClientWritingFailed        = errors.New("could not write to the client")
-	errConvertToFlusherFailed     = errors.New("could not instantiate http sse flusher")
+	errResponseJSONCreationFailed  = errors.New("could not create JSON for response")
+	errClientWritingFailed         = errors.New("could not write to the client")
+	errConvertToFlusherFailed      = errors.New("could not instantiate http sse flusher")
+	errInvalidPlaybackTimeInterval = errors.New("playback time interval should be a non-negative number of milliseconds")
 )
 
 const (
-	replaySseStateArg = "replay"
-	sseChannelArg     = "channel"
+	clientNameArg           = "client"
+	deltaArg                = "delta"
+	playbackTimeIntervalArg = "playbackTimeInterval"
+	playbackTimeOnlyArg     = "playbackTimeOnly"
+	replaySseStateArg       = "replay"
+	sseChannelArg           = "channel"
 
 	// ObserverAdded informs about new observer being added to the SSE server.
 	ObserverAdded ObserverChangeVariant = "observer-added"
@@ -35,8 +45,36 @@ type ObserverChangeVariant string
 // ObserversChange informs about changes to the SSE observers list.
 type ObserversChange struct {
 	ChangeVariant  ObserverChangeVariant
-	RemoteAddr     string
 	ChannelVariant state_sse.ChannelVariant
+	ClientName     string
+	ConnectedAt    time.Time
+	ConnectionID   string
+	RemoteAddr     string
+	UserAgent      string
+}
+
+// connection holds information about a single SSE connection, shared by observations of all the channels requested on it.
+// Observers are identified by the connection id, since many connections may come from the same remote address
+// (eg. browser tabs behind the same NAT or proxy).
+type connection struct {
+	clientName  string
+	connectedAt time.Time
+	disconnect  context.CancelFunc
+	id          string
+	req         *http.Request
+	res         ResponseWriter
+}
+
+func (c connection) observersChange(changeVariant ObserverChangeVariant, channelVariant state_sse.ChannelVariant) ObserversChange {
+	return ObserversChange{
+		ChangeVariant:  changeVariant,
+		ChannelVariant: channelVariant,
+		ClientName:     c.clientName,
+		ConnectedAt:    c.connectedAt,
+		ConnectionID:   c.id,
+		RemoteAddr:     c.req.RemoteAddr,
+		UserAgent:      c.req.UserAgent(),
+	}
 }
 
 type getSseHandler = func(res http.ResponseWriter, req *http.Request)
@@ -53,14 +91,49 @@ func (s *Server) createSseRegisterHandler(cfg handlerConfig) getSseHandler {
 			return
 		}
 
-		sseResWriter, err := sseResponseWriter(res)
+		options, err := sseConnectionOptions(req, channelsKnownVariants(cfg.Channels))
 		if err != nil {
 			res.WriteHeader(400)
+			res.Write([]byte(fmt.Sprintf("%s\n", err)))
+			return
+		}
+
+		sseResWriter, err := sseResponseWriter(res, s.instance, options)
+		if err != nil {
+			res.WriteHeader(400)
+			return
+		}
+		defer sseResWriter.playbackTime.stop()
+
+		err = sseResWriter.SendRetry(s.retry)
+		if err != nil {
+			s.errLog.Printf("could not start sse connection for %s: %s\n", req.RemoteAddr, err)
 			return
 		}
 
+		// observation of any channel may end the connection (eg. when observer could not keep up with the changes),
+		// in which case observations of all channels are ended
+		ctx, disconnect := context.WithCancel(req.Context())
+		defer disconnect()
+
+		conn := connection{
+			clientName:  req.URL.Query().Get(clientNameArg),
+			connectedAt: time.Now(),
+			disconnect:  disconnect,
+			id:          uuid.NewString(),
+			req:         req.WithContext(ctx),
+			res:         sseResWriter,
+		}
+
+		heartbeats := &sync.WaitGroup{}
+		if s.heartbeat > 0 {
+			heartbeats.Add(1)
+			go s.sendHeartbeats(conn, heartbeats)
+		}
+
 		wg := &sync.WaitGroup{}
 
+		lastRevisions, reconnecting := lastEventIDs(req, s.instance)
 		channelVariants := req.URL.Query()[sseChannelArg]
 		for _, reqChannel := range channelVariants {
 			channelVariant := state_sse.ChannelVariant(reqChannel)
@@ -70,53 +143,81 @@ func (s *Server) createSseRegisterHandler(cfg handlerConfig) getSseHandler {
 				continue
 			}
 
+			lastRevision, resuming := lastRevisions[channelVariant]
+			if resuming {
+				sseResWriter.setEventID(channelVariant, lastRevision)
+			}
+
+			resumption := observationResumption{
+				replay:   reconnecting && !resuming,
+				resuming: resuming,
+				revision: lastRevision,
+			}
+
 			wg.Add(1)
-			go s.observeChannelVariant(sseResWriter, req, channel, wg)
+			go s.observeChannelVariant(conn, channel, resumption, wg)
 		}
 
 		wg.Wait()
-		s.outLog.Printf("all sse channels closed for %s", req.RemoteAddr)
+
+		// response cannot be written to after the handler returns
+		conn.disconnect()
+		heartbeats.Wait()
+		s.outLog.Printf("all sse channels closed for connection %s from %s", conn.id, req.RemoteAddr)
 	}
 }
 
-func (s *Server) observeChannelVariant(res ResponseWriter, req *http.Request, sseChannel channel, wg *sync.WaitGroup) {
+// observationResumption specifies whether observer reconnected after receiving changes up to the revision.
+// Reconnecting observer which revision is unknown (eg. it connected to the previous instance of the server) should be replayed.
+type observationResumption struct {
+	replay   bool
+	resuming bool
+	revision uint64
+}
+
+func (s *Server) observeChannelVariant(conn connection, sseChannel channel, resumption observationResumption, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	remoteAddr := req.RemoteAddr
-	sseChannel.AddObserver(remoteAddr)
+	if resumption.resuming {
+		err := sseChannel.ResumeObserver(conn.id, conn.res, resumption.revision)
+		if err != nil {
+			s.errLog.Println(fmt.Sprintf("could not resume sse from revision %d: %s", resumption.revision, err.Error()))
+		}
+	} else {
+		sseChannel.AddObserver(conn.id)
+	}
 
 	if s.observersChanges != nil {
-		s.observersChanges <- ObserversChange{
-			ChangeVariant:  ObserverAdded,
-			RemoteAddr:     remoteAddr,
-			ChannelVariant: sseChannel.Variant(),
-		}
+		s.observersChanges <- conn.observersChange(ObserverAdded, sseChannel.Variant())
 	}
-	s.outLog.Printf("added %s observer with addr %s\n", sseChannel.Variant(), remoteAddr)
+	s.outLog.Printf("added %s observer with connection %s from addr %s\n", sseChannel.Variant(), conn.id, conn.req.RemoteAddr)
 
-	if replaySseState(req) {
-		err := sseChannel.Replay(res)
+	if !resumption.resuming && (resumption.replay || replaySseState(conn.req)) {
+		err := sseChannel.Replay(conn.res)
 		if err != nil {
 			s.errLog.Println(fmt.Sprintf("could not replay data on sse: %s", err.Error()))
 		}
 	}
 
-	connectionDone := make(chan bool)
+	connectionDone := make(chan bool, 1)
 	channelDone := make(chan bool)
 	channelErrors := make(chan error)
-	go s.waitForConnectionClosure(req, connectionDone, sseChannel)
-	go sseChannel.ServeObserver(remoteAddr, res, channelDone, channelErrors)
+	go s.waitForConnectionClosure(conn, connectionDone, sseChannel)
+	go sseChannel.ServeObserver(conn.id, conn.res, channelDone, channelErrors)
 
 	for {
 		select {
 		case err := <-channelErrors:
-			s.errLog.Printf("error occured on channel '%s' for remote address %s: %s\n", sseChannel.Variant(), remoteAddr, err)
+			s.handleChannelError(conn, sseChannel, err)
+		case err := <-conn.res.playbackTime.errors():
+			s.handleChannelError(conn, sseChannel, err)
 		case <-channelDone:
-			s.outLog.Printf("sse observation on channel '%s' done for remote address %s due to changes channel being closed\n", sseChannel.Variant(), remoteAddr)
+			s.outLog.Printf("sse observation on channel '%s' done for connection %s due to changes channel being closed\n", sseChannel.Variant(), conn.id)
+			conn.disconnect()
 
 			return
 		case <-s.ctx.Done():
-			s.outLog.Printf("sse observation on channel '%s' done for remote address %s due to server being stopped\n", sseChannel.Variant(), remoteAddr)
+			s.outLog.Printf("sse observation on channel '%s' done for connection %s due to server being stopped\n", sseChannel.Variant(), conn.id)
 
 			return
 		case <-connectionDone:
@@ -125,29 +226,57 @@ func (s *Server) observeChannelVariant(res ResponseWriter, req *http.Request, ss
 	}
 }
 
+// handleChannelError closes the connection when the error means that the client cannot be written to anymore.
+func (s *Server) handleChannelError(conn connection, sseChannel channel, err error) {
+	s.errLog.Printf("error occured on channel '%s' for connection %s: %s\n", sseChannel.Variant(), conn.id, err)
+	if errors.Is(err, errClientWritingFailed) {
+		conn.disconnect()
+	}
+}
+
+// sendHeartbeats periodically writes heartbeat to the connection until it is closed.
+// Failed heartbeat closes the connection, which removes its observers from all channels.
+func (s *Server) sendHeartbeats(conn connection, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.req.Context().Done():
+			return
+		case <-ticker.C:
+			err := conn.res.SendHeartbeat()
+			if err != nil {
+				s.outLog.Printf("closing connection %s from %s: %s\n", conn.id, conn.req.RemoteAddr, err)
+				conn.disconnect()
+
+				return
+			}
+		}
+	}
+}
+
 // waitForConnectionClosure handles waiting for the sse SSE connection closure, handling the mutex and observers management afterwards.
 // It should be run in a separate goroutine in-case change to the list of sse observers triggered by dispatcher is not handled due to Context().Done()
-// being (randomly) selected first - since after the disconnect the lock should be obtained for the list of observers, it may happen that
+// being (randomly) selected first - since after the disconnect the lock should be obtained for the list of observers, it may happen that
 // the dispatcher already acquired this lock and will deadlock below code. Running this method in a goroutine ensures that dispatcher will manage to go through
 // the loop of channel observers and unlock the mutex.
-func (s *Server) waitForConnectionClosure(req *http.Request, done chan<- bool, sseChannel channel) {
-	<-req.Context().Done()
-	sseChannel.RemoveObserver(req.RemoteAddr)
+func (s *Server) waitForConnectionClosure(conn connection, done chan<- bool, sseChannel channel) {
+	<-conn.req.Context().Done()
+	sseChannel.RemoveObserver(conn.id)
 
 	if s.observersChanges != nil {
-		s.observersChanges <- ObserversChange{
-			ChangeVariant:  ObserverRemoved,
-			RemoteAddr:     req.RemoteAddr,
-			ChannelVariant: sseChannel.Variant(),
-		}
+		s.observersChanges <- conn.observersChange(ObserverRemoved, sseChannel.Variant())
 	}
-	s.outLog.Printf("removing %s observer with addr %s\n", sseChannel.Variant(), req.RemoteAddr)
+	s.outLog.Printf("removing %s observer with connection %s from addr %s\n", sseChannel.Variant(), conn.id, conn.req.RemoteAddr)
 
 	done <- true
 	close(done)
 }
 
-func sseResponseWriter(res http.ResponseWriter) (ResponseWriter, error) {
+func sseResponseWriter(res http.ResponseWriter, instance string, options connectionOptions) (ResponseWriter, error) {
 	flusher, ok := res.(http.Flusher)
 	if !ok {
 		return ResponseWriter{}, errConvertToFlusherFailed
@@ -158,22 +287,64 @@ func sseResponseWriter(res http.ResponseWriter) (ResponseWriter, error) {
 	res.Header().Set("Access-Control-Allow-Origin", "*")
 
 	sseFlusher := ResponseWriter{
-		res:     res,
-		flusher: flusher,
-		lock:    &sync.Mutex{},
+		res:          res,
+		controller:   http.NewResponseController(res),
+		flusher:      flusher,
+		lock:         &sync.Mutex{},
+		deltas:       deltaBases{},
+		eventIDs:     eventIDs{},
+		instance:     instance,
+		options:      options,
+		playbackTime: newThrottle(options.playbackTimeInterval),
 	}
 	return sseFlusher, nil
 }
 
+func sseConnectionOptions(req *http.Request, known knownVariants) (connectionOptions, error) {
+	delta, err := parseDeltaMode(req.URL.Query().Get(deltaArg))
+	if err != nil {
+		return connectionOptions{}, err
+	}
+
+	variants, err := parseVariantFilter(req.URL.Query()[includeVariantArg], req.URL.Query()[excludeVariantArg], known)
+	if err != nil {
+		return connectionOptions{}, err
+	}
+
+	options := connectionOptions{
+		delta:            delta,
+		playbackTimeOnly: req.URL.Query().Get(playbackTimeOnlyArg) == "true",
+		variants:         variants,
+	}
+
+	interval := req.URL.Query().Get(playbackTimeIntervalArg)
+	if interval == "" {
+		return options, nil
+	}
+
+	intervalMs, err := strconv.ParseUint(interval, 10, 32)
+	if err != nil {
+		return options, fmt.Errorf("%w: %s", errInvalidPlaybackTimeInterval, err)
+	}
+
+	options.playbackTimeInterval = time.Duration(intervalMs) * time.Millisecond
+
+	return options, nil
+}
+
 func replaySseState(req *http.Request) bool {
 	replay, ok := req.URL.Query()[replaySseStateArg]
 
 	return ok && len(replay) > 0 && replay[0] == "true"
 }
 
-func formatSseEvent(channel state_sse.ChannelVariant, eventName string, data []byte) []byte {
+func formatSseEvent(id string, channel state_sse.ChannelVariant, eventName string, data []byte) []byte {
 	var out []byte
 
+	if id != "" {
+		out = append(out, []byte(fmt.Sprintf("id:%s\n", id))...)
+	}
+
 	channelEvent := fmt.Sprintf("%s.%s", channel, eventName)
 	out = append(out, []byte(fmt.Sprintf("event:%s\n", channelEvent))...)
 
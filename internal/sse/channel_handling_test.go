@@ -0,0 +1,127 @@
+package sse
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/state"
+)
+
+func newTestServer(t *testing.T, heartbeat time.Duration) *Server {
+	t.Helper()
+
+	uut := NewServer(Config{
+		ErrWriter:         io.Discard,
+		HeartbeatInterval: heartbeat,
+		OutWriter:         io.Discard,
+		RetryInterval:     5 * time.Second,
+		StatesRepository:  state.NewRepository(),
+	})
+	uut.Init(nil)
+	t.Cleanup(uut.Shutdown)
+
+	return uut
+}
+
+// failingResponseWriter accepts a number of writes, after which every write fails as if the client disconnected.
+type failingResponseWriter struct {
+	header   http.Header
+	lock     *sync.Mutex
+	accepted int
+	written  []string
+}
+
+func (f *failingResponseWriter) Header() http.Header {
+	return f.header
+}
+
+func (f *failingResponseWriter) Write(data []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.written = append(f.written, string(data))
+	if len(f.written) > f.accepted {
+		return 0, errors.New("broken pipe")
+	}
+
+	return len(data), nil
+}
+
+func (f *failingResponseWriter) WriteHeader(statusCode int) {}
+
+func (f *failingResponseWriter) Flush() {}
+
+func TestRetryAndHeartbeatsAreSent(t *testing.T) {
+	// given
+	uut := newTestServer(t, 10*time.Millisecond)
+	server := httptest.NewServer(uut.Handler())
+	t.Cleanup(server.Close)
+
+	// when
+	res, err := http.Get(server.URL + registerPath + "?channel=directories")
+	if err != nil {
+		t.Fatalf("Unexpected error while connecting: %s", err)
+	}
+	defer res.Body.Close()
+
+	// then
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	expected := []string{"retry:5000", "", ":heartbeat"}
+	for _, expectedLine := range expected {
+		select {
+		case line, ok := <-lines:
+			if !ok || line != expectedLine {
+				t.Fatalf("Expected line '%s', got '%s'", expectedLine, line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected line '%s' to be sent", expectedLine)
+		}
+	}
+}
+
+func TestFailedHeartbeatDisconnects(t *testing.T) {
+	// given
+	uut := newTestServer(t, 10*time.Millisecond)
+	handler := uut.createSseRegisterHandler(handlerConfig{Channels: uut.channels})
+	res := &failingResponseWriter{
+		accepted: 1,
+		header:   http.Header{},
+		lock:     &sync.Mutex{},
+	}
+	req := httptest.NewRequest(http.MethodGet, registerPath+"?channel=directories", nil)
+
+	// when
+	done := make(chan struct{})
+	go func() {
+		handler(res, req)
+		close(done)
+	}()
+
+	// then
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected connection to be closed after failed heartbeat")
+	}
+
+	res.lock.Lock()
+	defer res.lock.Unlock()
+	if len(res.written) != 2 || !strings.HasPrefix(res.written[0], "retry:") || res.written[1] != string(heartbeatComment) {
+		t.Errorf("Expected retry and a single failed heartbeat to be written, got %q", res.written)
+	}
+}
@@ -0,0 +1,185 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// NoDelta sends whole payloads in every event.
+	NoDelta DeltaMode = ""
+
+	// FieldsDelta sends only top-level fields of payloads which changed since the last event, with removed fields set to null.
+	FieldsDelta DeltaMode = "fields"
+
+	// PatchDelta sends RFC 6902 JSON Patch transforming the payload of the last event into the current one.
+	PatchDelta DeltaMode = "patch"
+)
+
+var (
+	errUnknownDeltaMode = errors.New("unknown delta mode")
+)
+
+// DeltaMode specifies how the changes of state are sent to the clients which opted in for delta payloads.
+type DeltaMode string
+
+func parseDeltaMode(name string) (DeltaMode, error) {
+	mode := DeltaMode(name)
+	switch mode {
+	case NoDelta, FieldsDelta, PatchDelta:
+		return mode, nil
+	default:
+		return mode, fmt.Errorf("%w: %s", errUnknownDeltaMode, name)
+	}
+}
+
+// patchOperation is a single operation of RFC 6902 JSON Patch.
+type patchOperation struct {
+	Op    string
+	Path  string
+	Value interface{}
+}
+
+// MarshalJSON satisfies json.Marshaller. Value is omitted only for remove operations,
+// since null, false or 0 are valid values of other operations.
+func (po patchOperation) MarshalJSON() ([]byte, error) {
+	if po.Op == "remove" {
+		return json.Marshal(map[string]interface{}{"op": po.Op, "path": po.Path})
+	}
+
+	return json.Marshal(map[string]interface{}{"op": po.Op, "path": po.Path, "value": po.Value})
+}
+
+// deltaBases holds the payloads of the last events sent on a connection, which the deltas are computed against.
+// Bases are kept per channel and subject - the part of the channel state that events carry (eg. a single playlist).
+type deltaBases map[string]interface{}
+
+func deltaBaseKey(channel string, subject string) string {
+	return fmt.Sprintf("%s/%s", channel, subject)
+}
+
+// resetChannel removes bases of a channel, so the next event on the channel carries whole payload.
+func (db deltaBases) resetChannel(channel string) {
+	prefix := deltaBaseKey(channel, "")
+	for key := range db {
+		if strings.HasPrefix(key, prefix) {
+			delete(db, key)
+		}
+	}
+}
+
+// fieldsDelta returns top-level fields of current object that differ from base object.
+// Fields missing in current object are returned as nil. When any of the values is not an object, current value is returned.
+func fieldsDelta(base interface{}, current interface{}) interface{} {
+	baseObject, baseOk := base.(map[string]interface{})
+	currentObject, currentOk := current.(map[string]interface{})
+	if !baseOk || !currentOk {
+		return current
+	}
+
+	delta := map[string]interface{}{}
+	for key, value := range currentObject {
+		baseValue, ok := baseObject[key]
+		if !ok || !reflect.DeepEqual(baseValue, value) {
+			delta[key] = value
+		}
+	}
+
+	for key := range baseObject {
+		if _, ok := currentObject[key]; !ok {
+			delta[key] = nil
+		}
+	}
+
+	return delta
+}
+
+// jsonPatch returns operations transforming base value into current value.
+// Values should be results of unmarshalling JSON into interface{}.
+func jsonPatch(base interface{}, current interface{}) []patchOperation {
+	return appendPatch([]patchOperation{}, "", base, current)
+}
+
+func appendPatch(operations []patchOperation, path string, base interface{}, current interface{}) []patchOperation {
+	if reflect.DeepEqual(base, current) {
+		return operations
+	}
+
+	baseObject, baseIsObject := base.(map[string]interface{})
+	currentObject, currentIsObject := current.(map[string]interface{})
+	if baseIsObject && currentIsObject {
+		return appendObjectPatch(operations, path, baseObject, currentObject)
+	}
+
+	baseArray, baseIsArray := base.([]interface{})
+	currentArray, currentIsArray := current.([]interface{})
+	if baseIsArray && currentIsArray {
+		return appendArrayPatch(operations, path, baseArray, currentArray)
+	}
+
+	return append(operations, patchOperation{Op: "replace", Path: path, Value: current})
+}
+
+func appendObjectPatch(operations []patchOperation, path string, base map[string]interface{}, current map[string]interface{}) []patchOperation {
+	for _, key := range sortedKeys(base) {
+		if _, ok := current[key]; !ok {
+			operations = append(operations, patchOperation{Op: "remove", Path: jsonPointer(path, key)})
+		}
+	}
+
+	for _, key := range sortedKeys(current) {
+		baseValue, ok := base[key]
+		if !ok {
+			operations = append(operations, patchOperation{Op: "add", Path: jsonPointer(path, key), Value: current[key]})
+			continue
+		}
+
+		operations = appendPatch(operations, jsonPointer(path, key), baseValue, current[key])
+	}
+
+	return operations
+}
+
+// appendArrayPatch compares elements at the same indexes, adding or removing elements at the end of the array.
+func appendArrayPatch(operations []patchOperation, path string, base []interface{}, current []interface{}) []patchOperation {
+	common := len(base)
+	if len(current) < common {
+		common = len(current)
+	}
+
+	for idx := 0; idx < common; idx++ {
+		operations = appendPatch(operations, jsonPointer(path, strconv.Itoa(idx)), base[idx], current[idx])
+	}
+
+	for idx := len(base) - 1; idx >= common; idx-- {
+		operations = append(operations, patchOperation{Op: "remove", Path: jsonPointer(path, strconv.Itoa(idx))})
+	}
+
+	for idx := common; idx < len(current); idx++ {
+		operations = append(operations, patchOperation{Op: "add", Path: jsonPointer(path, strconv.Itoa(idx)), Value: current[idx]})
+	}
+
+	return operations
+}
+
+func jsonPointer(path string, token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+
+	return fmt.Sprintf("%s/%s", path, token)
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func unmarshalTestDocument(t *testing.T, document string) interface{} {
+	t.Helper()
+
+	var out interface{}
+	err := json.Unmarshal([]byte(document), &out)
+	if err != nil {
+		t.Fatalf("Unexpected error while unmarshalling test document: %s", err)
+	}
+
+	return out
+}
+
+func TestJsonPatch(t *testing.T) {
+	// given
+	base := unmarshalTestDocument(t, `{"Paused": true, "Removed": 1, "Entries": [{"Path": "/a"}, {"Path": "/b"}], "a/b": 0}`)
+	current := unmarshalTestDocument(t, `{"Paused": false, "Entries": [{"Path": "/a"}, {"Path": "/c"}, {"Path": "/d"}], "a/b": 1}`)
+
+	// when
+	patch := jsonPatch(base, current)
+
+	// then
+	out, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("Unexpected error while marshalling patch: %s", err)
+	}
+
+	expected := `[{"op":"remove","path":"/Removed"},` +
+		`{"op":"replace","path":"/Entries/1/Path","value":"/c"},` +
+		`{"op":"add","path":"/Entries/2","value":{"Path":"/d"}},` +
+		`{"op":"replace","path":"/Paused","value":false},` +
+		`{"op":"replace","path":"/a~1b","value":1}]`
+	if string(out) != expected {
+		t.Errorf("Expected patch %s, got %s", expected, out)
+	}
+}
+
+func TestFieldsDelta(t *testing.T) {
+	// given
+	base := unmarshalTestDocument(t, `{"CurrentTime": 1.5, "Paused": true, "Removed": 1}`)
+	current := unmarshalTestDocument(t, `{"CurrentTime": 2.5, "Paused": true}`)
+
+	// when
+	delta := fieldsDelta(base, current)
+
+	// then
+	out, err := json.Marshal(delta)
+	if err != nil {
+		t.Fatalf("Unexpected error while marshalling delta: %s", err)
+	}
+
+	expected := `{"CurrentTime":2.5,"Removed":null}`
+	if string(out) != expected {
+		t.Errorf("Expected delta %s, got %s", expected, out)
+	}
+}
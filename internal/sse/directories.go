@@ -2,6 +2,7 @@ package sse
 
 import (
 	"encoding/json"
+	"github.com/sarpt/mpv-web-api/internal/common"
 
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
@@ -32,12 +33,13 @@ func (dc *directoriesChangesBroadcaster) ChangeHandler(res ResponseWriter, chang
 	return res.SendChange(change, directoriesSSEChannelVariant, string(directories.AddedDirectoriesChange))
 }
 
-func NewDirectoriesChannel(storage *directories.Storage) *StateChannel[directories.Change] {
+func NewDirectoriesChannel(storage *directories.Storage, queueCfg QueueConfig) *StateChannel[directories.Change] {
 	return &StateChannel[directories.Change]{
 		&directoriesChangesBroadcaster{
 			storage,
-			NewChangesBroadcaster[directories.Change](),
+			NewChangesBroadcaster[directories.Change](queueCfg),
 		},
 		directoriesSSEChannelVariant,
+		[]common.ChangeVariant{directories.AddedDirectoriesChange, directories.UpdatedDirectoriesChange, directories.RemovedDirectoriesChange},
 	}
 }
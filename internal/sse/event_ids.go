@@ -0,0 +1,78 @@
+package sse
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+	lastEventIDArg    = "lastEventId"
+
+	eventIDsSeparator        = ";"
+	eventIDRevisionSeparator = ":"
+)
+
+// eventIDs holds revisions of the latest events sent on each of the channels of a single connection.
+// Since one connection multiplexes many channels, the SSE event id consists of revisions of all of them,
+// eg. "5f1c0e2a;directories:3;playback:120", so that a client reconnecting with the Last-Event-ID can be resumed on every channel.
+// The first entry identifies the server instance - revisions are not persisted, so they are meaningless after server restart.
+type eventIDs map[state_sse.ChannelVariant]uint64
+
+func (ei eventIDs) format(instance string) string {
+	ids := make([]string, 0, len(ei)+1)
+	for channelVariant, revision := range ei {
+		ids = append(ids, fmt.Sprintf("%s%s%d", channelVariant, eventIDRevisionSeparator, revision))
+	}
+	sort.Strings(ids)
+
+	return strings.Join(append([]string{instance}, ids...), eventIDsSeparator)
+}
+
+// parseEventIDs reads revisions of channels from the event id sent by the server instance.
+// Malformed entries are ignored - channels without revisions are replayed instead of resumed.
+func parseEventIDs(id string, instance string) eventIDs {
+	revisions := eventIDs{}
+
+	entries := strings.Split(id, eventIDsSeparator)
+	if entries[0] != instance {
+		return revisions
+	}
+
+	for _, entry := range entries[1:] {
+		channelVariant, revisionText, found := strings.Cut(entry, eventIDRevisionSeparator)
+		if !found {
+			continue
+		}
+
+		revision, err := strconv.ParseUint(revisionText, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		revisions[state_sse.ChannelVariant(channelVariant)] = revision
+	}
+
+	return revisions
+}
+
+// lastEventIDs returns revisions of channels that the client already received before reconnecting.
+// Browsers send them in the Last-Event-ID header, other clients may provide them with a query argument.
+// False is returned when the client connects for the first time.
+func lastEventIDs(req *http.Request, instance string) (eventIDs, bool) {
+	id := req.Header.Get(lastEventIDHeader)
+	if id == "" {
+		id = req.URL.Query().Get(lastEventIDArg)
+	}
+
+	if id == "" {
+		return eventIDs{}, false
+	}
+
+	return parseEventIDs(id, instance), true
+}
@@ -0,0 +1,69 @@
+package sse
+
+import (
+	"testing"
+)
+
+func TestParseEventIDs(t *testing.T) {
+	cases := map[string]eventIDs{
+		"abc;directories:3;playback:120": {"directories": 3, "playback": 120},
+		"abc;playback:x;status;logs:7":   {"logs": 7},
+		"other;playback:120":             {},
+		"":                               {},
+	}
+
+	for id, expected := range cases {
+		// when
+		revisions := parseEventIDs(id, "abc")
+
+		// then
+		if len(revisions) != len(expected) {
+			t.Errorf("Expected revisions %v for id '%s', got %v", expected, id, revisions)
+
+			continue
+		}
+
+		for channelVariant, revision := range expected {
+			if revisions[channelVariant] != revision {
+				t.Errorf("Expected revision %d of channel '%s' for id '%s', got %d", revision, channelVariant, id, revisions[channelVariant])
+			}
+		}
+	}
+}
+
+func TestEventIDsFormat(t *testing.T) {
+	// given
+	uut := eventIDs{"playback": 120, "directories": 3}
+
+	// when
+	id := uut.format("abc")
+
+	// then
+	expected := "abc;directories:3;playback:120"
+	if id != expected {
+		t.Errorf("Expected id '%s', got '%s'", expected, id)
+	}
+
+	if parsed := parseEventIDs(id, "abc"); parsed["playback"] != 120 || parsed["directories"] != 3 {
+		t.Errorf("Expected formatted id to be parsed back, got %v", parsed)
+	}
+}
+
+func TestChangesBroadcasterRevisions(t *testing.T) {
+	// given
+	uut := NewChangesBroadcaster[testChange](QueueConfig{Policy: DropOldestPolicy, Size: DefaultQueueSize})
+
+	// when
+	uut.BroadcastToChannelObservers(testChange{"first"})
+	uut.BroadcastToChannelObservers(testChange{"second"})
+	missed, ok := uut.AddResumingObserver("observer", 1)
+
+	// then
+	if uut.Revision() != 2 {
+		t.Errorf("Expected revision 2 after two changes, got %d", uut.Revision())
+	}
+
+	if !ok || len(missed) != 1 || missed[0].revision != 2 || missed[0].change.variant != "second" {
+		t.Errorf("Expected only the second change to be missed, got %+v", missed)
+	}
+}
@@ -0,0 +1,37 @@
+package sse
+
+import (
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+const (
+	logsSSEChannelVariant state_sse.ChannelVariant = "logs"
+
+	logsReplaySseEvent = "replay"
+)
+
+type logsChangesBroadcaster struct {
+	logs *logs.Storage
+	ChangesBroadcaster[logs.Change]
+}
+
+func (lc *logsChangesBroadcaster) Replay(res ResponseWriter) error {
+	return res.SendChange(lc.logs, logsSSEChannelVariant, logsReplaySseEvent)
+}
+
+func (lc *logsChangesBroadcaster) ChangeHandler(res ResponseWriter, change logs.Change) error {
+	return res.SendChange(change, logsSSEChannelVariant, string(change.Variant()))
+}
+
+func NewLogsChannel(storage *logs.Storage, queueCfg QueueConfig) *StateChannel[logs.Change] {
+	return &StateChannel[logs.Change]{
+		&logsChangesBroadcaster{
+			storage,
+			NewChangesBroadcaster[logs.Change](queueCfg),
+		},
+		logsSSEChannelVariant,
+		[]common.ChangeVariant{common.ChangeVariant(logs.InfoLevel), common.ChangeVariant(logs.ErrorLevel)},
+	}
+}
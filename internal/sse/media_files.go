@@ -2,6 +2,7 @@ package sse
 
 import (
 	"encoding/json"
+	"github.com/sarpt/mpv-web-api/internal/common"
 
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
@@ -32,12 +33,13 @@ func (mfc *mediaFilesChangesBroadcaster) ChangeHandler(res ResponseWriter, chang
 	return res.SendChange(change, mediaFilesSSEChannelVariant, string(change.ChangeVariant))
 }
 
-func NewMediaFilesChannel(storage *media_files.Storage) *StateChannel[media_files.Change] {
+func NewMediaFilesChannel(storage *media_files.Storage, queueCfg QueueConfig) *StateChannel[media_files.Change] {
 	return &StateChannel[media_files.Change]{
 		&mediaFilesChangesBroadcaster{
 			storage,
-			NewChangesBroadcaster[media_files.Change](),
+			NewChangesBroadcaster[media_files.Change](queueCfg),
 		},
 		mediaFilesSSEChannelVariant,
+		[]common.ChangeVariant{media_files.AddedMediaFilesChange, media_files.UpdatedMediaFilesChange, media_files.RemovedMediaFilesChange},
 	}
 }
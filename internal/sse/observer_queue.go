@@ -0,0 +1,207 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+const (
+	// DropOldestPolicy drops the oldest queued change to make room for a new one.
+	DropOldestPolicy OverflowPolicy = "drop-oldest"
+
+	// CoalescePolicy replaces the queued change of the same variant with a new one, dropping the oldest change when none matches.
+	CoalescePolicy OverflowPolicy = "coalesce"
+
+	// DisconnectPolicy closes the connection of the observer which queue is full.
+	DisconnectPolicy OverflowPolicy = "disconnect"
+
+	// DefaultQueueSize is a default number of changes waiting to be sent to an observer.
+	DefaultQueueSize = 64
+)
+
+var (
+	ErrUnknownOverflowPolicy = errors.New("unknown overflow policy")
+	ErrInvalidQueueSize      = errors.New("queue size should be larger than 0")
+)
+
+// OverflowPolicy specifies what happens to the changes when observer does not receive them fast enough and its queue is full.
+type OverflowPolicy string
+
+// ParseOverflowPolicy returns overflow policy by its name.
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	policy := OverflowPolicy(name)
+	switch policy {
+	case DropOldestPolicy, CoalescePolicy, DisconnectPolicy:
+		return policy, nil
+	default:
+		return policy, fmt.Errorf("%w: %s", ErrUnknownOverflowPolicy, name)
+	}
+}
+
+// QueueConfig controls the queues of changes of channel observers.
+// OnOverflow is called with the number of changes dropped for an observer, and whether the observer was disconnected.
+// ReplayOnDrop should be set for channels which changes carry only parts of the state (eg. added media files) -
+// the observer missing any of them would end up with a wrong state, so instead of dropping single changes
+// all queued changes are dropped and the whole state of the channel is replayed to the observer.
+type QueueConfig struct {
+	OnOverflow   func(dropped int, disconnected bool)
+	Policy       OverflowPolicy
+	ReplayOnDrop bool
+	Size         int
+}
+
+// observerQueue holds changes waiting to be sent to an observer.
+// Pushing to the queue never blocks, so a slow observer does not hold back other observers of the channel.
+type observerQueue[CT Change] struct {
+	changes []revisionedChange[CT]
+	closed  bool
+	cfg     QueueConfig
+	lock    *sync.Mutex
+	wake    chan struct{}
+}
+
+func newObserverQueue[CT Change](cfg QueueConfig) *observerQueue[CT] {
+	return &observerQueue[CT]{
+		changes: []revisionedChange[CT]{},
+		cfg:     cfg,
+		lock:    &sync.Mutex{},
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+func (oq *observerQueue[CT]) push(change revisionedChange[CT]) {
+	dropped, disconnected := oq.enqueue(change)
+	if dropped > 0 && oq.cfg.OnOverflow != nil {
+		oq.cfg.OnOverflow(dropped, disconnected)
+	}
+
+	select {
+	case oq.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (oq *observerQueue[CT]) enqueue(change revisionedChange[CT]) (int, bool) {
+	oq.lock.Lock()
+	defer oq.lock.Unlock()
+
+	if oq.closed {
+		return 0, false
+	}
+
+	if len(oq.changes) < oq.cfg.Size {
+		oq.changes = append(oq.changes, change)
+
+		return 0, false
+	}
+
+	if oq.cfg.Policy == DisconnectPolicy {
+		dropped := len(oq.changes) + 1
+		oq.changes = nil
+		oq.closed = true
+
+		return dropped, true
+	}
+
+	if oq.cfg.ReplayOnDrop {
+		dropped := len(oq.changes) + 1
+		if oq.changes[0].replay { // replay requested by the previous overflow is not a dropped change
+			dropped--
+		}
+		oq.changes = []revisionedChange[CT]{{replay: true}}
+
+		return dropped, false
+	}
+
+	if oq.cfg.Policy == CoalescePolicy {
+		for idx, queued := range oq.changes {
+			if !queued.replay && queued.change.Variant() == change.change.Variant() {
+				oq.changes = append(oq.changes[:idx], oq.changes[idx+1:]...)
+				oq.changes = append(oq.changes, change)
+
+				return 1, false
+			}
+		}
+	}
+
+	oq.changes = append(oq.changes[1:], change)
+
+	return 1, false
+}
+
+// pop waits for the next change in the queue. False is returned when the queue is closed.
+func (oq *observerQueue[CT]) pop() (revisionedChange[CT], bool) {
+	for {
+		oq.lock.Lock()
+		if len(oq.changes) > 0 {
+			change := oq.changes[0]
+			oq.changes = oq.changes[1:]
+			oq.lock.Unlock()
+
+			return change, true
+		}
+
+		closed := oq.closed
+		oq.lock.Unlock()
+
+		if closed {
+			return revisionedChange[CT]{}, false
+		}
+
+		<-oq.wake
+	}
+}
+
+func (oq *observerQueue[CT]) close() {
+	oq.lock.Lock()
+	oq.closed = true
+	oq.lock.Unlock()
+
+	select {
+	case oq.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ObserverQueue holds changes waiting to be sent to an observer outside of SSE server (eg. WebSocket connection),
+// with the same overflow handling as queues of SSE observers.
+type ObserverQueue[CT Change] struct {
+	queue *observerQueue[CT]
+}
+
+// NewObserverQueue returns empty queue of changes.
+func NewObserverQueue[CT Change](cfg QueueConfig) *ObserverQueue[CT] {
+	return &ObserverQueue[CT]{
+		queue: newObserverQueue[CT](cfg),
+	}
+}
+
+// Push adds change with its revision to the queue without blocking.
+func (q *ObserverQueue[CT]) Push(change CT, revision uint64) {
+	q.queue.push(revisionedChange[CT]{
+		change:   change,
+		revision: revision,
+	})
+}
+
+// Pop waits for the next change in the queue. Replay is true when changes were dropped and the whole state
+// should be sent to the observer instead. False is returned when the queue is closed.
+func (q *ObserverQueue[CT]) Pop() (change CT, revision uint64, replay bool, ok bool) {
+	revisioned, ok := q.queue.pop()
+
+	return revisioned.change, revisioned.revision, revisioned.replay, ok
+}
+
+// Close stops the queue - waiting Pop returns, and pushed changes are ignored.
+func (q *ObserverQueue[CT]) Close() {
+	q.queue.close()
+}
+
+// IsPartialStateChannel returns whether changes of the channel variant carry only parts of its state,
+// so observers missing any change should be replayed.
+func IsPartialStateChannel(channelVariant state_sse.ChannelVariant) bool {
+	return partialStateChannels[channelVariant]
+}
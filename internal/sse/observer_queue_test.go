@@ -0,0 +1,133 @@
+package sse
+
+import (
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+)
+
+type testChange struct {
+	variant common.ChangeVariant
+}
+
+func (tc testChange) MarshalJSON() ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (tc testChange) Variant() common.ChangeVariant {
+	return tc.variant
+}
+
+func pushTestChanges(uut *observerQueue[testChange], variants ...common.ChangeVariant) {
+	for idx, variant := range variants {
+		uut.push(revisionedChange[testChange]{change: testChange{variant}, revision: uint64(idx + 1)})
+	}
+}
+
+func popTestRevisions(uut *observerQueue[testChange]) []uint64 {
+	uut.close()
+
+	revisions := []uint64{}
+	for {
+		change, more := uut.pop()
+		if !more {
+			return revisions
+		}
+
+		revisions = append(revisions, change.revision)
+	}
+}
+
+func TestObserverQueue_DropOldestPolicy(t *testing.T) {
+	// given
+	var dropped int
+	uut := newObserverQueue[testChange](QueueConfig{
+		OnOverflow: func(d int, disconnected bool) { dropped += d },
+		Policy:     DropOldestPolicy,
+		Size:       2,
+	})
+
+	// when
+	pushTestChanges(uut, "a", "b", "a", "c")
+
+	// then
+	revisions := popTestRevisions(uut)
+	if len(revisions) != 2 || revisions[0] != 3 || revisions[1] != 4 {
+		t.Errorf("Expected revisions [3 4] to be queued, got %v", revisions)
+	}
+
+	if dropped != 2 {
+		t.Errorf("Expected 2 changes to be dropped, got %d", dropped)
+	}
+}
+
+func TestObserverQueue_CoalescePolicy(t *testing.T) {
+	// given
+	uut := newObserverQueue[testChange](QueueConfig{
+		Policy: CoalescePolicy,
+		Size:   2,
+	})
+
+	// when
+	pushTestChanges(uut, "a", "b", "a")
+
+	// then
+	revisions := popTestRevisions(uut)
+	if len(revisions) != 2 || revisions[0] != 2 || revisions[1] != 3 {
+		t.Errorf("Expected revisions [2 3] to be queued, got %v", revisions)
+	}
+}
+
+func TestObserverQueue_DisconnectPolicy(t *testing.T) {
+	// given
+	var disconnected bool
+	uut := newObserverQueue[testChange](QueueConfig{
+		OnOverflow: func(d int, disc bool) { disconnected = disc },
+		Policy:     DisconnectPolicy,
+		Size:       2,
+	})
+
+	// when
+	pushTestChanges(uut, "a", "b", "c")
+
+	// then
+	if !disconnected {
+		t.Errorf("Expected observer to be disconnected")
+	}
+
+	_, more := uut.pop()
+	if more {
+		t.Errorf("Expected queue to be closed without pending changes")
+	}
+}
+
+func TestObserverQueue_ReplayOnDrop(t *testing.T) {
+	// given
+	var dropped int
+	uut := newObserverQueue[testChange](QueueConfig{
+		OnOverflow:   func(d int, disconnected bool) { dropped += d },
+		Policy:       DropOldestPolicy,
+		ReplayOnDrop: true,
+		Size:         2,
+	})
+
+	// when
+	pushTestChanges(uut, "a", "b", "c", "d")
+
+	// then
+	uut.close()
+
+	replay, _ := uut.pop()
+	if !replay.replay {
+		t.Errorf("Expected replay to be requested in place of dropped changes")
+	}
+
+	change, more := uut.pop()
+	if !more || change.revision != 4 {
+		t.Errorf("Expected change with revision 4 to be queued after the replay, got %+v", change)
+	}
+
+	if dropped != 3 {
+		t.Errorf("Expected 3 changes to be dropped, got %d", dropped)
+	}
+}
@@ -1,6 +1,9 @@
 package sse
 
 import (
+	"encoding/json"
+	"github.com/sarpt/mpv-web-api/internal/common"
+
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playback"
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
 )
@@ -12,6 +15,14 @@ const (
 	playbackReplaySseEvent = "replay"
 )
 
+type playbackTimeChange struct {
+	CurrentTime float64
+}
+
+func (ptc playbackTimeChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]float64{"CurrentTime": ptc.CurrentTime})
+}
+
 type playbackChangesBroadcaster struct {
 	playback *playback.Storage
 	ChangesBroadcaster[playback.Change]
@@ -21,20 +32,60 @@ func (pc *playbackChangesBroadcaster) Replay(res ResponseWriter) error {
 	return res.SendChange(pc.playback, playbackSSEChannelVariant, playbackReplaySseEvent)
 }
 
+// ChangeHandler sends the change of playback. Playback time change postponed by the throttle is discarded
+// when any other change is sent, since it would arrive after the newer change with an outdated state.
 func (pc *playbackChangesBroadcaster) ChangeHandler(res ResponseWriter, change playback.Change) error {
+	if change.ChangeVariant != playback.PlaybackTimeChange {
+		res.playbackTime.cancel()
+	}
+
 	if pc.playback.Stopped { // TODO: the changes are shot by state.Playback even after the mediaFilePath is cleared, as such it may be wasteful to push further changes through SSE. to think of a way to reduce number of those blank data calls after closing stopping playback
 		return res.SendEmptyChange(playbackSSEChannelVariant, string(change.ChangeVariant))
 	}
 
-	return res.SendChange(pc.playback, playbackSSEChannelVariant, string(change.ChangeVariant))
+	if change.ChangeVariant == playback.PlaybackTimeChange {
+		return res.playbackTime.do(func() error {
+			return pc.sendPlaybackTime(res, change)
+		})
+	}
+
+	return res.SendDeltaChange("", pc.playback, playbackSSEChannelVariant, string(change.ChangeVariant))
+}
+
+// sendPlaybackTime sends either whole playback state or only the time, depending on the options of the connection.
+func (pc *playbackChangesBroadcaster) sendPlaybackTime(res ResponseWriter, change playback.Change) error {
+	if !res.options.playbackTimeOnly {
+		return res.SendDeltaChange("", pc.playback, playbackSSEChannelVariant, string(change.ChangeVariant))
+	}
+
+	currentTime, ok := change.Value.(float64)
+	if !ok {
+		currentTime = pc.playback.CurrentTime()
+	}
+
+	return res.SendChange(playbackTimeChange{CurrentTime: currentTime}, playbackSSEChannelVariant, string(change.ChangeVariant))
 }
 
-func NewPlaybackChannel(storage *playback.Storage) *StateChannel[playback.Change] {
+func NewPlaybackChannel(storage *playback.Storage, queueCfg QueueConfig) *StateChannel[playback.Change] {
 	return &StateChannel[playback.Change]{
 		&playbackChangesBroadcaster{
 			storage,
-			NewChangesBroadcaster[playback.Change](),
+			NewChangesBroadcaster[playback.Change](queueCfg),
 		},
 		playbackSSEChannelVariant,
+		[]common.ChangeVariant{
+			playback.FullscreenChange,
+			playback.LoopFileChange,
+			playback.PauseChange,
+			playback.AudioIDChange,
+			playback.PlaybackStoppedChange,
+			playback.SubtitleIDChange,
+			playback.CurrentChapterIdxChange,
+			playback.MediaFileChange,
+			playback.PlaybackTimeChange,
+			playback.PlaylistSelectionChange,
+			playback.PlaylistUnloadChange,
+			playback.PlaylistCurrentIdxChange,
+		},
 	}
 }
@@ -28,16 +28,17 @@ func (pc *playlistsChangesBroadcaster) ChangeHandler(res ResponseWriter, change
 		return res.SendEmptyChange(playlistsSSEChannelVariant, string(change.ChangeVariant))
 	}
 
-	return res.SendChange(change.Playlist, playlistsSSEChannelVariant, string(change.ChangeVariant))
+	return res.SendDeltaChange(change.Playlist.UUID(), change.Playlist, playlistsSSEChannelVariant, string(change.ChangeVariant))
 }
 
-func NewPlaylistsChannel(playbackStorage *playback.Storage, playlistsStorage *playlists.Storage) *StateChannel[playlists.Change] {
+func NewPlaylistsChannel(playbackStorage *playback.Storage, playlistsStorage *playlists.Storage, queueCfg QueueConfig) *StateChannel[playlists.Change] {
 	return &StateChannel[playlists.Change]{
 		&playlistsChangesBroadcaster{
 			playbackStorage,
 			playlistsStorage,
-			NewChangesBroadcaster[playlists.Change](),
+			NewChangesBroadcaster[playlists.Change](queueCfg),
 		},
 		playlistsSSEChannelVariant,
+		[]common.ChangeVariant{playlists.PlaylistsAdded, playlists.PlaylistsCurrentEntryIdxChange, playlists.PlaylistsEntriesChange},
 	}
 }
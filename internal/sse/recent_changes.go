@@ -0,0 +1,72 @@
+package sse
+
+import "sync"
+
+const (
+	// recentChangesSize is a number of the latest changes kept by each channel for resumption of reconnecting observers.
+	recentChangesSize = 128
+)
+
+// revisionedChange is a change with the revision of the channel it was broadcasted at.
+// Queued change with replay set carries no change - it requests the whole state of the channel to be replayed instead.
+type revisionedChange[CT Change] struct {
+	change   CT
+	replay   bool
+	revision uint64
+}
+
+// recentChanges is a ring buffer of the latest changes broadcasted on a channel.
+// Changes at or below floor revision are not available anymore - either they were evicted
+// from the buffer or they happened before the buffer was created.
+type recentChanges[CT Change] struct {
+	changes []revisionedChange[CT]
+	count   int
+	floor   uint64
+	lock    *sync.Mutex
+	start   int
+}
+
+func newRecentChanges[CT Change](size int, floor uint64) *recentChanges[CT] {
+	return &recentChanges[CT]{
+		changes: make([]revisionedChange[CT], size),
+		floor:   floor,
+		lock:    &sync.Mutex{},
+	}
+}
+
+func (rc *recentChanges[CT]) push(change revisionedChange[CT]) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if rc.count < len(rc.changes) {
+		rc.changes[(rc.start+rc.count)%len(rc.changes)] = change
+		rc.count++
+
+		return
+	}
+
+	rc.floor = rc.changes[rc.start].revision
+	rc.changes[rc.start] = change
+	rc.start = (rc.start + 1) % len(rc.changes)
+}
+
+// since returns changes with revision after the provided one, in the order they were broadcasted.
+// When some of the changes after the revision are no longer available, false is returned.
+func (rc *recentChanges[CT]) since(revision uint64) ([]revisionedChange[CT], bool) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+
+	if revision < rc.floor {
+		return nil, false
+	}
+
+	changes := []revisionedChange[CT]{}
+	for idx := 0; idx < rc.count; idx++ {
+		change := rc.changes[(rc.start+idx)%len(rc.changes)]
+		if change.revision > revision {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, true
+}
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"testing"
+)
+
+func pushRecentTestChanges(uut *recentChanges[testChange], revisions ...uint64) {
+	for _, revision := range revisions {
+		uut.push(revisionedChange[testChange]{change: testChange{"change"}, revision: revision})
+	}
+}
+
+func recentRevisions(changes []revisionedChange[testChange]) []uint64 {
+	revisions := []uint64{}
+	for _, change := range changes {
+		revisions = append(revisions, change.revision)
+	}
+
+	return revisions
+}
+
+func TestRecentChangesSince(t *testing.T) {
+	// given
+	uut := newRecentChanges[testChange](4, 0)
+	pushRecentTestChanges(uut, 1, 2, 3)
+
+	// when
+	changes, ok := uut.since(1)
+
+	// then
+	if !ok {
+		t.Fatalf("Expected changes after revision 1 to be available")
+	}
+
+	revisions := recentRevisions(changes)
+	if len(revisions) != 2 || revisions[0] != 2 || revisions[1] != 3 {
+		t.Errorf("Expected revisions [2 3], got %v", revisions)
+	}
+}
+
+func TestRecentChangesEvictsOldestChanges(t *testing.T) {
+	// given
+	uut := newRecentChanges[testChange](3, 0)
+	pushRecentTestChanges(uut, 1, 2, 3, 4, 5)
+
+	// when
+	available, availableOk := uut.since(2)
+	_, evictedOk := uut.since(1)
+
+	// then
+	if !availableOk {
+		t.Fatalf("Expected changes after revision 2 to be available")
+	}
+
+	revisions := recentRevisions(available)
+	if len(revisions) != 3 || revisions[0] != 3 || revisions[2] != 5 {
+		t.Errorf("Expected revisions [3 4 5], got %v", revisions)
+	}
+
+	if evictedOk {
+		t.Errorf("Expected changes after revision 1 to be unavailable, since revision 2 was evicted")
+	}
+}
+
+func TestRecentChangesBelowFloor(t *testing.T) {
+	// given
+	uut := newRecentChanges[testChange](3, 10)
+
+	// when
+	_, ok := uut.since(9)
+
+	// then
+	if ok {
+		t.Errorf("Expected changes before the floor to be unavailable")
+	}
+}
@@ -5,16 +5,46 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
 )
 
+const (
+	writeTimeout = 10 * time.Second
+)
+
+var (
+	heartbeatComment = []byte(":heartbeat\n\n")
+)
+
 // ResponseWriter is used to send data through keep-alive SSE connection.
 // The writer and flusher are protected by lock since multiple go routines use the same connection to send events.
+// Each copy of ResponseWriter may be set to a revision of the state which changes it sends, and the revision
+// is then included in the id of the events.
 type ResponseWriter struct {
-	res     http.ResponseWriter
-	flusher http.Flusher
-	lock    *sync.Mutex
+	res          http.ResponseWriter
+	controller   *http.ResponseController
+	flusher      http.Flusher
+	lock         *sync.Mutex
+	deltas       deltaBases
+	eventIDs     eventIDs
+	instance     string
+	options      connectionOptions
+	playbackTime *throttle
+	revision     uint64
+}
+
+// connectionOptions holds options of the events sent on a connection, requested by the client.
+// Variants specifies which changes of the channels are sent to the client.
+// Delta specifies whether events carry whole payloads or only their differences from the previous events.
+// PlaybackTimeInterval limits how often playback time changes are sent (0 means every change is sent),
+// while playbackTimeOnly makes playback time changes carry only the time instead of whole playback state.
+type connectionOptions struct {
+	delta                DeltaMode
+	playbackTimeInterval time.Duration
+	playbackTimeOnly     bool
+	variants             variantFilter
 }
 
 // Write sends data through the connection
@@ -22,12 +52,27 @@ func (f *ResponseWriter) Write(data []byte) (int, error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
-	n, err := f.res.Write(data)
-	if err == nil {
-		f.flusher.Flush()
+	return f.write(data)
+}
+
+// SendHeartbeat sends SSE comment, which is ignored by clients but keeps idle connection open and reveals disconnected clients.
+func (f *ResponseWriter) SendHeartbeat() error {
+	_, err := f.Write(heartbeatComment)
+	if err != nil {
+		return fmt.Errorf("writing heartbeat failed: %w: %s", errClientWritingFailed, err)
 	}
 
-	return n, err
+	return nil
+}
+
+// SendRetry informs browsers how long they should wait before reconnecting after the connection is lost.
+func (f *ResponseWriter) SendRetry(retry time.Duration) error {
+	_, err := f.Write([]byte(fmt.Sprintf("retry:%d\n\n", retry.Milliseconds())))
+	if err != nil {
+		return fmt.Errorf("writing retry failed: %w: %s", errClientWritingFailed, err)
+	}
+
+	return nil
 }
 
 // SendChange is responsible for propgating change payload through SSE connection.
@@ -41,14 +86,108 @@ func (f *ResponseWriter) SendChange(changePayload json.Marshaler, channelVariant
 	return err
 }
 
+// SendDeltaChange propagates change payload through SSE connection, sending only its difference from the payload
+// of the same subject sent previously on the channel, when the client opted in for delta payloads.
+// Subject identifies the part of the channel state the payload holds, eg. a playlist uuid.
+// The first payload of a subject is sent whole - as a patch replacing the whole document in case of JSON Patch.
+func (f *ResponseWriter) SendDeltaChange(subject string, changePayload json.Marshaler, channelVariant state_sse.ChannelVariant, changeVariant string) error {
+	if f.options.delta == NoDelta {
+		return f.SendChange(changePayload, channelVariant, changeVariant)
+	}
+
+	out, err := json.Marshal(changePayload)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errResponseJSONCreationFailed, err)
+	}
+
+	var current interface{}
+	err = json.Unmarshal(out, &current)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errResponseJSONCreationFailed, err)
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := deltaBaseKey(string(channelVariant), subject)
+	base, ok := f.deltas[key]
+	f.deltas[key] = current
+
+	var delta interface{}
+	switch {
+	case f.options.delta == PatchDelta && ok:
+		delta = jsonPatch(base, current)
+	case f.options.delta == PatchDelta:
+		delta = []patchOperation{{Op: "replace", Path: "", Value: current}}
+	case ok:
+		delta = fieldsDelta(base, current)
+	default:
+		delta = current
+	}
+
+	out, err = json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errResponseJSONCreationFailed, err)
+	}
+
+	_, err = f.writeChangeLocked(out, channelVariant, changeVariant)
+	return err
+}
+
 // SendEmptyChange is responsible for propagating change without any payload (without "data") through SSE connection.
 func (f *ResponseWriter) SendEmptyChange(channelVariant state_sse.ChannelVariant, changeVariant string) error {
 	_, err := f.writeChange([]byte{}, channelVariant, changeVariant)
 	return err
 }
 
+// setEventID sets revision of the channel included in ids of the following events, before any event is sent on the channel.
+func (f *ResponseWriter) setEventID(channelVariant state_sse.ChannelVariant, revision uint64) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.eventIDs[channelVariant] = revision
+}
+
+// resetDeltas makes the next payloads of the channel to be sent whole, eg. after the channel was replayed.
+func (f *ResponseWriter) resetDeltas(channelVariant state_sse.ChannelVariant) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.deltas.resetChannel(string(channelVariant))
+}
+
+func (f ResponseWriter) atRevision(revision uint64) ResponseWriter {
+	f.revision = revision
+
+	return f
+}
+
+// write sends data with a deadline, so a client which stopped receiving data (eg. its network went down)
+// is detected as failed write instead of blocking the connection until TCP notices.
+// Deadline is not set when underlying writer does not support it.
+func (f *ResponseWriter) write(data []byte) (int, error) {
+	if f.controller != nil {
+		f.controller.SetWriteDeadline(time.Now().Add(writeTimeout))
+	}
+
+	n, err := f.res.Write(data)
+	if err == nil {
+		f.flusher.Flush()
+	}
+
+	return n, err
+}
+
 func (f *ResponseWriter) writeChange(out []byte, channelVariant state_sse.ChannelVariant, changeVariant string) (int, error) {
-	n, err := f.Write(formatSseEvent(channelVariant, string(changeVariant), out))
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.writeChangeLocked(out, channelVariant, changeVariant)
+}
+
+func (f *ResponseWriter) writeChangeLocked(out []byte, channelVariant state_sse.ChannelVariant, changeVariant string) (int, error) {
+	f.eventIDs[channelVariant] = f.revision
+	n, err := f.write(formatSseEvent(f.eventIDs.format(f.instance), channelVariant, string(changeVariant), out))
 	if err != nil {
 		return n, fmt.Errorf("writing change %s on %s channel failed: %w: %s", changeVariant, channelVariant, errClientWritingFailed, err)
 	}
@@ -6,21 +6,41 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/sarpt/mpv-web-api/pkg/api"
 	"github.com/sarpt/mpv-web-api/pkg/state"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/status"
 )
 
 const (
 	logPrefix = "sse.Server#"
 
+	// DefaultHeartbeatInterval is a default interval between heartbeats sent on idle connections.
+	DefaultHeartbeatInterval = 15 * time.Second
+
+	// DefaultRetryInterval is a default time browsers should wait before reconnecting.
+	DefaultRetryInterval = 3 * time.Second
+
 	name     = "SSE Server"
 	pathBase = "sse"
 )
 
 var (
 	registerPath = fmt.Sprintf("/%s/channels", pathBase)
+
+	// partialStateChannels are channels which changes carry only parts of their states.
+	partialStateChannels = map[state_sse.ChannelVariant]bool{
+		directoriesSSEChannelVariant: true,
+		logsSSEChannelVariant:        true,
+		mediaFilesSSEChannelVariant:  true,
+		playlistsSSEChannelVariant:   true,
+	}
 )
 
 // Server holds information about handled SSE connections and their observers.
@@ -29,28 +49,64 @@ type Server struct {
 	channels         map[state_sse.ChannelVariant]channel
 	ctx              context.Context
 	errLog           *log.Logger
+	heartbeat        time.Duration
+	instance         string
 	observersChanges chan ObserversChange
 	outLog           *log.Logger
+	queuePolicy      OverflowPolicy
+	queueSize        int
+	retry            time.Duration
 	statesRepository state.Repository
 }
 
 // Config controls behaviour of the SSE server.
+// QueueSize and QueuePolicy control how many changes may wait to be sent to each observer,
+// and what happens to the changes when observer does not receive them fast enough.
+// HeartbeatInterval controls how often heartbeat comments are sent to keep connections open through proxies
+// and to detect disconnected clients (0 disables heartbeats), while RetryInterval is sent to browsers
+// as time to wait before reconnecting.
 type Config struct {
-	ErrWriter        io.Writer
-	OutWriter        io.Writer
-	StatesRepository state.Repository
+	ErrWriter         io.Writer
+	HeartbeatInterval time.Duration
+	OutWriter         io.Writer
+	QueuePolicy       OverflowPolicy
+	QueueSize         int
+	RetryInterval     time.Duration
+	StatesRepository  state.Repository
 }
 
 // NewServer prepares and returns SSE server to handle SSE connections and observers.
 func NewServer(cfg Config) *Server {
+	queuePolicy := cfg.QueuePolicy
+	if queuePolicy == "" {
+		queuePolicy = DropOldestPolicy
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	retry := cfg.RetryInterval
+	if retry <= 0 {
+		retry = DefaultRetryInterval
+	}
+
+	outWriter, errWriter := cfg.StatesRepository.Logs().TeeWriters(logs.SseSource, cfg.OutWriter, cfg.ErrWriter)
+
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Server{
 		cancel:           cancel,
 		channels:         map[state_sse.ChannelVariant]channel{},
 		ctx:              ctx,
-		errLog:           log.New(cfg.ErrWriter, logPrefix, log.LstdFlags),
+		errLog:           log.New(errWriter, logPrefix, log.LstdFlags),
+		heartbeat:        cfg.HeartbeatInterval,
+		instance:         strings.Split(uuid.NewString(), "-")[0],
 		observersChanges: make(chan ObserversChange),
-		outLog:           log.New(cfg.OutWriter, logPrefix, log.LstdFlags),
+		outLog:           log.New(outWriter, logPrefix, log.LstdFlags),
+		queuePolicy:      queuePolicy,
+		queueSize:        queueSize,
+		retry:            retry,
 		statesRepository: cfg.StatesRepository,
 	}
 }
@@ -88,27 +144,48 @@ func (s *Server) Shutdown() {
 
 // subscribeToStateChanges starts listening on state changes channels for further distribution to its observers.
 func (s *Server) subscribeToStateChanges() {
-	directoriesChannel := NewDirectoriesChannel(s.statesRepository.Directories())
+	directoriesChannel := NewDirectoriesChannel(s.statesRepository.Directories(), s.queueConfig(directoriesSSEChannelVariant))
 	s.channels[directoriesSSEChannelVariant] = directoriesChannel
 	s.statesRepository.Directories().Subscribe(directoriesChannel.BroadcastToChannelObservers, func(err error) {})
 
-	playbackChannel := NewPlaybackChannel(s.statesRepository.Playback())
+	logsChannel := NewLogsChannel(s.statesRepository.Logs(), s.queueConfig(logsSSEChannelVariant))
+	s.channels[logsSSEChannelVariant] = logsChannel
+	s.statesRepository.Logs().Subscribe(logsChannel.BroadcastToChannelObservers, func(err error) {})
+
+	playbackChannel := NewPlaybackChannel(s.statesRepository.Playback(), s.queueConfig(playbackSSEChannelVariant))
 	s.channels[playbackSSEChannelVariant] = playbackChannel
 	s.statesRepository.Playback().Subscribe(playbackChannel.BroadcastToChannelObservers, func(err error) {})
 
-	playlistsChannel := NewPlaylistsChannel(s.statesRepository.Playback(), s.statesRepository.Playlists())
+	playlistsChannel := NewPlaylistsChannel(s.statesRepository.Playback(), s.statesRepository.Playlists(), s.queueConfig(playlistsSSEChannelVariant))
 	s.channels[playlistsSSEChannelVariant] = playlistsChannel
 	s.statesRepository.Playlists().Subscribe(playlistsChannel.BroadcastToChannelObservers, func(err error) {})
 
-	mediaFilesChannel := NewMediaFilesChannel(s.statesRepository.MediaFiles())
+	mediaFilesChannel := NewMediaFilesChannel(s.statesRepository.MediaFiles(), s.queueConfig(mediaFilesSSEChannelVariant))
 	s.channels[mediaFilesSSEChannelVariant] = mediaFilesChannel
 	s.statesRepository.MediaFiles().Subscribe(mediaFilesChannel.BroadcastToChannelObservers, func(err error) {})
 
-	statusChannel := NewStatusChannel(s.statesRepository.Status())
+	statusChannel := NewStatusChannel(s.statesRepository.Status(), s.queueConfig(statusSSEChannelVariant))
 	s.channels[statusSSEChannelVariant] = statusChannel
 	s.statesRepository.Status().Subscribe(statusChannel.BroadcastToChannelObservers, func(err error) {})
 }
 
+// queueConfig returns configuration of observers queues for the channel variant, which reports changes
+// not delivered to slow observers to the status state. Observers of channels with partial states are replayed after drops.
+func (s *Server) queueConfig(channelVariant state_sse.ChannelVariant) QueueConfig {
+	return QueueConfig{
+		OnOverflow: func(dropped int, disconnected bool) {
+			if disconnected {
+				s.errLog.Printf("disconnecting observer of channel '%s' due to full queue of changes\n", channelVariant)
+			}
+
+			s.statesRepository.Status().AddObserversDrops(channelVariant, dropped, disconnected)
+		},
+		Policy:       s.queuePolicy,
+		ReplayOnDrop: partialStateChannels[channelVariant],
+		Size:         s.queueSize,
+	}
+}
+
 func (s Server) watchSSEObserversChanges() {
 	for {
 		change, open := <-s.observersChanges
@@ -118,9 +195,15 @@ func (s Server) watchSSEObserversChanges() {
 
 		switch change.ChangeVariant {
 		case ObserverAdded:
-			s.statesRepository.Status().AddObservingAddress(change.RemoteAddr, change.ChannelVariant)
+			connection := status.Connection{
+				ClientName:  change.ClientName,
+				ConnectedAt: change.ConnectedAt,
+				RemoteAddr:  change.RemoteAddr,
+				UserAgent:   change.UserAgent,
+			}
+			s.statesRepository.Status().AddObservingConnection(change.ConnectionID, connection, change.ChannelVariant)
 		case ObserverRemoved:
-			s.statesRepository.Status().RemoveObservingAddress(change.RemoteAddr, change.ChannelVariant)
+			s.statesRepository.Status().RemoveObservingConnection(change.ConnectionID, change.ChannelVariant)
 		}
 	}
 }
@@ -3,50 +3,107 @@ package sse
 import (
 	"errors"
 
+	"github.com/sarpt/mpv-web-api/internal/common"
+
 	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
 )
 
 type stateChangeBroadcaster[CT Change] interface {
-	AddObserver(address string)
-	RemoveObserver(address string)
+	AddObserver(observerID string)
+	AddResumingObserver(observerID string, revision uint64) ([]revisionedChange[CT], bool)
+	RemoveObserver(observerID string)
 	Replay(res ResponseWriter) error
 	ChangeHandler(res ResponseWriter, change CT) error
-	Observer(address string) (chan CT, bool)
+	Observer(observerID string) (*observerQueue[CT], bool)
 	BroadcastToChannelObservers(change CT)
+	Revision() uint64
 }
 
+// StateChannel distributes changes of a single state to its observers.
+// ChangeVariants lists variants of the changes of the state, which clients may filter.
 type StateChannel[CT Change] struct {
 	stateChangeBroadcaster[CT]
-	variant state_sse.ChannelVariant
+	variant        state_sse.ChannelVariant
+	changeVariants []common.ChangeVariant
+}
+
+// Replay sends the whole state of the channel, identified by the current revision of the state.
+// Following delta payloads of the channel are sent whole, since replay payloads are not used as delta bases.
+func (sc *StateChannel[CT]) Replay(res ResponseWriter) error {
+	res.resetDeltas(sc.variant)
+
+	return sc.stateChangeBroadcaster.Replay(res.atRevision(sc.Revision()))
+}
+
+// ResumeObserver adds observer which already received changes up to the provided revision and sends it only the changes it missed.
+// When the missed changes are too old to be resent, the whole state is replayed instead.
+func (sc *StateChannel[CT]) ResumeObserver(observerID string, res ResponseWriter, revision uint64) error {
+	missedChanges, ok := sc.AddResumingObserver(observerID, revision)
+	if !ok {
+		return sc.Replay(res)
+	}
+
+	for _, missed := range missedChanges {
+		if !res.options.variants.allows(sc.variant, missed.change.Variant()) {
+			continue
+		}
+
+		err := sc.ChangeHandler(res.atRevision(missed.revision), missed.change)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func (sc *StateChannel[CT]) ServeObserver(address string, res ResponseWriter, done chan<- bool, errs chan<- error) {
+// ServeObserver sends changes queued for the observer until the observer is removed.
+// Changes filtered out by the client are skipped before they are serialized.
+// When the queue dropped changes which the observer cannot miss, the whole state is replayed instead.
+func (sc *StateChannel[CT]) ServeObserver(observerID string, res ResponseWriter, done chan<- bool, errs chan<- error) {
 	defer close(done)
 	defer close(errs)
 
-	changes, ok := sc.Observer(address)
+	changes, ok := sc.Observer(observerID)
 	if !ok {
-		errs <- errors.New("no observer found for provided address")
+		errs <- errors.New("no observer found for provided id")
 		done <- true
 
 		return
 	}
 
 	for {
-		change, more := <-changes
+		change, more := changes.pop()
 		if !more {
 			done <- true
 
 			return
 		}
 
-		err := sc.ChangeHandler(res, change)
+		if change.replay {
+			err := sc.Replay(res)
+			if err != nil {
+				errs <- err
+			}
+
+			continue
+		}
+
+		if !res.options.variants.allows(sc.variant, change.change.Variant()) {
+			continue
+		}
+
+		err := sc.ChangeHandler(res.atRevision(change.revision), change.change)
 		if err != nil {
 			errs <- err
 		}
 	}
 }
 
+func (sc *StateChannel[CT]) ChangeVariants() []common.ChangeVariant {
+	return sc.changeVariants
+}
+
 func (sc *StateChannel[CT]) Variant() state_sse.ChannelVariant {
 	return sc.variant
 }
@@ -23,15 +23,16 @@ func (sc *statusChangesBroadcaster) Replay(res ResponseWriter) error {
 }
 
 func (sc *statusChangesBroadcaster) ChangeHandler(res ResponseWriter, change status.Change) error {
-	return res.SendChange(sc.status, statusSSEChannelVariant, string(change.ChangeVariant))
+	return res.SendDeltaChange("", sc.status, statusSSEChannelVariant, string(change.ChangeVariant))
 }
 
-func NewStatusChannel(storage *status.Storage) *StateChannel[status.Change] {
+func NewStatusChannel(storage *status.Storage, queueCfg QueueConfig) *StateChannel[status.Change] {
 	return &StateChannel[status.Change]{
 		&statusChangesBroadcaster{
 			storage,
-			NewChangesBroadcaster[status.Change](),
+			NewChangesBroadcaster[status.Change](queueCfg),
 		},
 		statusSSEChannelVariant,
+		[]common.ChangeVariant{status.ClientObserverAdded, status.ClientObserverRemoved, status.MPVProcessChanged},
 	}
 }
@@ -0,0 +1,123 @@
+package sse
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle limits how often events are sent on a connection. Events coming more often than the interval
+// are coalesced - only the latest of them is sent once the interval passes.
+// Nil throttle sends every event immediately.
+// Errors of postponed sends are reported on errs, since nobody waits for them when they happen.
+type throttle struct {
+	errs     chan error
+	interval time.Duration
+	lastSent time.Time
+	lock     *sync.Mutex
+	pending  func() error
+	stopped  bool
+	timer    *time.Timer
+}
+
+func newThrottle(interval time.Duration) *throttle {
+	if interval <= 0 {
+		return nil
+	}
+
+	return &throttle{
+		errs:     make(chan error, 1),
+		interval: interval,
+		lock:     &sync.Mutex{},
+	}
+}
+
+// do sends the event immediately when the interval passed since the last sent event.
+// Otherwise the send is postponed until the interval passes, replacing a send postponed before.
+func (t *throttle) do(send func() error) error {
+	if t == nil {
+		return send()
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.stopped {
+		return nil
+	}
+
+	wait := t.interval - time.Since(t.lastSent)
+	if wait <= 0 && t.pending == nil {
+		t.lastSent = time.Now()
+
+		return send()
+	}
+
+	if t.pending == nil {
+		t.timer = time.AfterFunc(wait, t.sendPending)
+	}
+	t.pending = send
+
+	return nil
+}
+
+func (t *throttle) sendPending() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.stopped || t.pending == nil {
+		return
+	}
+
+	err := t.pending()
+	t.pending = nil
+	t.lastSent = time.Now()
+
+	if err == nil {
+		return
+	}
+
+	select {
+	case t.errs <- err:
+	default:
+	}
+}
+
+// errors returns channel of errors of postponed sends. Nil throttle never reports errors.
+func (t *throttle) errors() <-chan error {
+	if t == nil {
+		return nil
+	}
+
+	return t.errs
+}
+
+// cancel discards postponed send, eg. when a newer event made it outdated.
+func (t *throttle) cancel() {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.pending = nil
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
+
+// stop discards postponed send. Events are not sent after the throttle is stopped.
+func (t *throttle) stop() {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.stopped = true
+	t.pending = nil
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+}
@@ -0,0 +1,123 @@
+package sse
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type throttleTestSends struct {
+	lock *sync.Mutex
+	sent []int
+}
+
+func (tts *throttleTestSends) send(value int, err error) func() error {
+	return func() error {
+		tts.lock.Lock()
+		defer tts.lock.Unlock()
+
+		tts.sent = append(tts.sent, value)
+
+		return err
+	}
+}
+
+func (tts *throttleTestSends) values() []int {
+	tts.lock.Lock()
+	defer tts.lock.Unlock()
+
+	return append([]int{}, tts.sent...)
+}
+
+func TestThrottle_CoalescesPostponedSends(t *testing.T) {
+	// given
+	sends := &throttleTestSends{lock: &sync.Mutex{}}
+	uut := newThrottle(50 * time.Millisecond)
+
+	// when
+	for value := 1; value <= 5; value++ {
+		uut.do(sends.send(value, nil))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// then
+	sent := sends.values()
+	if len(sent) != 2 || sent[0] != 1 || sent[1] != 5 {
+		t.Errorf("Expected the first and the latest values to be sent, got %v", sent)
+	}
+}
+
+func TestThrottle_StopDiscardsPostponedSend(t *testing.T) {
+	// given
+	sends := &throttleTestSends{lock: &sync.Mutex{}}
+	uut := newThrottle(50 * time.Millisecond)
+	uut.do(sends.send(1, nil))
+	uut.do(sends.send(2, nil))
+
+	// when
+	uut.stop()
+	uut.do(sends.send(3, nil))
+	time.Sleep(100 * time.Millisecond)
+
+	// then
+	sent := sends.values()
+	if len(sent) != 1 || sent[0] != 1 {
+		t.Errorf("Expected only the value sent before stop, got %v", sent)
+	}
+}
+
+func TestThrottle_CancelDiscardsPostponedSend(t *testing.T) {
+	// given
+	sends := &throttleTestSends{lock: &sync.Mutex{}}
+	uut := newThrottle(50 * time.Millisecond)
+	uut.do(sends.send(1, nil))
+	uut.do(sends.send(2, nil))
+
+	// when
+	uut.cancel()
+	time.Sleep(100 * time.Millisecond)
+	uut.do(sends.send(3, nil))
+
+	// then
+	sent := sends.values()
+	if len(sent) != 2 || sent[0] != 1 || sent[1] != 3 {
+		t.Errorf("Expected postponed value to be discarded, got %v", sent)
+	}
+}
+
+func TestThrottle_ReportsPostponedSendErrors(t *testing.T) {
+	// given
+	sends := &throttleTestSends{lock: &sync.Mutex{}}
+	uut := newThrottle(10 * time.Millisecond)
+	expected := errors.New("write failed")
+
+	// when
+	uut.do(sends.send(1, nil))
+	uut.do(sends.send(2, expected))
+
+	// then
+	select {
+	case err := <-uut.errors():
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected error '%s', got '%s'", expected, err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected error of postponed send to be reported")
+	}
+}
+
+func TestThrottle_NilSendsImmediately(t *testing.T) {
+	// given
+	sends := &throttleTestSends{lock: &sync.Mutex{}}
+	uut := newThrottle(0)
+
+	// when
+	uut.do(sends.send(1, nil))
+	uut.do(sends.send(2, nil))
+
+	// then
+	if sent := sends.values(); len(sent) != 2 {
+		t.Errorf("Expected every value to be sent, got %v", sent)
+	}
+}
@@ -0,0 +1,126 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+const (
+	excludeVariantArg = "exclude"
+	includeVariantArg = "include"
+
+	variantFilterSeparator = "."
+)
+
+var (
+	errInvalidVariantFilter        = errors.New("variant filter should be in form of <channel>.<change variant>")
+	errUnknownVariantFilterChannel = errors.New("variant filter refers to unknown channel")
+	errUnknownVariantFilterChange  = errors.New("variant filter refers to unknown change variant")
+)
+
+// knownVariants holds change variants of each channel, which filters may refer to.
+type knownVariants map[state_sse.ChannelVariant][]common.ChangeVariant
+
+func channelsKnownVariants(channels map[state_sse.ChannelVariant]channel) knownVariants {
+	known := knownVariants{}
+	for channelVariant, channel := range channels {
+		known[channelVariant] = channel.ChangeVariants()
+	}
+
+	return known
+}
+
+func (kv knownVariants) validate(channelVariant state_sse.ChannelVariant, changeVariant common.ChangeVariant) error {
+	changeVariants, ok := kv[channelVariant]
+	if !ok {
+		return fmt.Errorf("%w: %s", errUnknownVariantFilterChannel, channelVariant)
+	}
+
+	for _, known := range changeVariants {
+		if known == changeVariant {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s.%s", errUnknownVariantFilterChange, channelVariant, changeVariant)
+}
+
+// channelVariantFilter holds change variants of a single channel that should be sent to or hidden from the client.
+type channelVariantFilter struct {
+	exclude map[common.ChangeVariant]bool
+	include map[common.ChangeVariant]bool
+}
+
+// variantFilter decides which changes are sent on a connection, based on their channel and change variant.
+// When any variant of a channel is included, only included variants of the channel are sent. Excluded variants are never sent.
+// Channels without filters send all of their changes.
+type variantFilter map[state_sse.ChannelVariant]channelVariantFilter
+
+func (vf variantFilter) allows(channelVariant state_sse.ChannelVariant, changeVariant common.ChangeVariant) bool {
+	filter, ok := vf[channelVariant]
+	if !ok {
+		return true
+	}
+
+	if filter.exclude[changeVariant] {
+		return false
+	}
+
+	return len(filter.include) == 0 || filter.include[changeVariant]
+}
+
+func (vf variantFilter) add(entry string, include bool, known knownVariants) error {
+	channel, changeVariant, found := strings.Cut(entry, variantFilterSeparator)
+	if !found || channel == "" || changeVariant == "" {
+		return fmt.Errorf("%w: %s", errInvalidVariantFilter, entry)
+	}
+
+	err := known.validate(state_sse.ChannelVariant(channel), common.ChangeVariant(changeVariant))
+	if err != nil {
+		return err
+	}
+
+	filter, ok := vf[state_sse.ChannelVariant(channel)]
+	if !ok {
+		filter = channelVariantFilter{
+			exclude: map[common.ChangeVariant]bool{},
+			include: map[common.ChangeVariant]bool{},
+		}
+	}
+
+	if include {
+		filter.include[common.ChangeVariant(changeVariant)] = true
+	} else {
+		filter.exclude[common.ChangeVariant(changeVariant)] = true
+	}
+
+	vf[state_sse.ChannelVariant(channel)] = filter
+
+	return nil
+}
+
+// parseVariantFilter reads filters from include and exclude arguments, eg. "include=playback.pauseChange&exclude=status.client-observer-added".
+// Filters referring to channels or change variants that do not exist are rejected, since they would never match.
+func parseVariantFilter(includes []string, excludes []string, known knownVariants) (variantFilter, error) {
+	filter := variantFilter{}
+
+	for _, entry := range includes {
+		err := filter.add(entry, true, known)
+		if err != nil {
+			return filter, err
+		}
+	}
+
+	for _, entry := range excludes {
+		err := filter.add(entry, false, known)
+		if err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,76 @@
+package sse
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+var testKnownVariants = knownVariants{
+	"mediaFiles": {"added", "updated", "removed"},
+	"playback":   {"pauseChange", "mediaFileChange", "playbackTimeChange"},
+	"status":     {"client-observer-added", "client-observer-removed"},
+}
+
+func TestVariantFilter(t *testing.T) {
+	// given
+	uut, err := parseVariantFilter(
+		[]string{"playback.pauseChange", "playback.mediaFileChange"},
+		[]string{"status.client-observer-added"},
+		testKnownVariants,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error while parsing filter: %s", err)
+	}
+
+	cases := []struct {
+		channel  state_sse.ChannelVariant
+		change   common.ChangeVariant
+		expected bool
+	}{
+		{"playback", "pauseChange", true},
+		{"playback", "playbackTimeChange", false},
+		{"status", "client-observer-added", false},
+		{"status", "client-observer-removed", true},
+		{"mediaFiles", "added", true},
+	}
+
+	// when & then
+	for _, c := range cases {
+		if allowed := uut.allows(c.channel, c.change); allowed != c.expected {
+			t.Errorf("Expected %s.%s to be allowed: %t, got %t", c.channel, c.change, c.expected, allowed)
+		}
+	}
+}
+
+func TestVariantFilter_RejectsEntriesWithoutChannel(t *testing.T) {
+	// when
+	_, err := parseVariantFilter([]string{"pauseChange"}, nil, testKnownVariants)
+
+	// then
+	if err == nil {
+		t.Errorf("Expected error for variant filter without channel")
+	}
+}
+
+func TestVariantFilter_RejectsUnknownChannels(t *testing.T) {
+	// when
+	_, err := parseVariantFilter(nil, []string{"playbacks.pauseChange"}, testKnownVariants)
+
+	// then
+	if !errors.Is(err, errUnknownVariantFilterChannel) {
+		t.Errorf("Expected unknown channel error, got %v", err)
+	}
+}
+
+func TestVariantFilter_RejectsUnknownChangeVariants(t *testing.T) {
+	// when
+	_, err := parseVariantFilter([]string{"playback.pausechange"}, nil, testKnownVariants)
+
+	// then
+	if !errors.Is(err, errUnknownVariantFilterChange) {
+		t.Errorf("Expected unknown change variant error, got %v", err)
+	}
+}
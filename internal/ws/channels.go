@@ -0,0 +1,146 @@
+package ws
+
+import (
+	"encoding/json"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/state"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playback"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playlists"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/status"
+)
+
+const (
+	directoriesChannelVariant state_sse.ChannelVariant = "directories"
+	logsChannelVariant        state_sse.ChannelVariant = "logs"
+	mediaFilesChannelVariant  state_sse.ChannelVariant = "mediaFiles"
+	playbackChannelVariant    state_sse.ChannelVariant = "playback"
+	playlistsChannelVariant   state_sse.ChannelVariant = "playlists"
+	statusChannelVariant      state_sse.ChannelVariant = "status"
+
+	// replayChange notifies about replay of the whole state of a channel.
+	replayChange common.ChangeVariant = "replay"
+)
+
+// sendChangeCb sends payload of a change to a subscribed connection.
+type sendChangeCb = func(changeVariant common.ChangeVariant, payload interface{})
+
+// queuedChange is a change of a channel waiting to be sent on a connection.
+type queuedChange struct {
+	payload interface{}
+	variant common.ChangeVariant
+}
+
+// MarshalJSON returns payload of the change in JSON format. Satisfies json.Marshaller.
+func (qc queuedChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(qc.payload)
+}
+
+func (qc queuedChange) Variant() common.ChangeVariant {
+	return qc.variant
+}
+
+// stateChannel describes how the changes of a single state are sent over WebSocket connections.
+// Payloads are the same as the ones sent by SSE channels of the same names.
+type stateChannel struct {
+	replay    func() interface{}
+	revision  func() uint64
+	subscribe func(send sendChangeCb) func()
+}
+
+func stateChannels(repository state.Repository) map[state_sse.ChannelVariant]stateChannel {
+	return map[state_sse.ChannelVariant]stateChannel{
+		directoriesChannelVariant: directoriesChannel(repository.Directories()),
+		logsChannelVariant:        logsChannel(repository.Logs()),
+		mediaFilesChannelVariant:  mediaFilesChannel(repository.MediaFiles()),
+		playbackChannelVariant:    playbackChannel(repository.Playback()),
+		playlistsChannelVariant:   playlistsChannel(repository.Playback(), repository.Playlists()),
+		statusChannelVariant:      statusChannel(repository.Status()),
+	}
+}
+
+func directoriesChannel(storage *directories.Storage) stateChannel {
+	return stateChannel{
+		replay:   func() interface{} { return storage.All() },
+		revision: storage.Revision,
+		subscribe: func(send sendChangeCb) func() {
+			return storage.Subscribe(func(change directories.Change) {
+				send(change.Variant(), change)
+			}, func(err error) {})
+		},
+	}
+}
+
+func logsChannel(storage *logs.Storage) stateChannel {
+	return stateChannel{
+		replay:   func() interface{} { return storage },
+		revision: storage.Revision,
+		subscribe: func(send sendChangeCb) func() {
+			return storage.Subscribe(func(change logs.Change) {
+				send(change.Variant(), change)
+			}, func(err error) {})
+		},
+	}
+}
+
+func mediaFilesChannel(storage *media_files.Storage) stateChannel {
+	return stateChannel{
+		replay:   func() interface{} { return storage.All() },
+		revision: storage.Revision,
+		subscribe: func(send sendChangeCb) func() {
+			return storage.Subscribe(func(change media_files.Change) {
+				send(change.ChangeVariant, change)
+			}, func(err error) {})
+		},
+	}
+}
+
+func playbackChannel(storage *playback.Storage) stateChannel {
+	return stateChannel{
+		replay:   func() interface{} { return storage },
+		revision: storage.Revision,
+		subscribe: func(send sendChangeCb) func() {
+			return storage.Subscribe(func(change playback.Change) {
+				if storage.Stopped {
+					send(change.ChangeVariant, nil)
+					return
+				}
+
+				send(change.ChangeVariant, storage)
+			}, func(err error) {})
+		},
+	}
+}
+
+func playlistsChannel(playbackStorage *playback.Storage, storage *playlists.Storage) stateChannel {
+	return stateChannel{
+		replay:   func() interface{} { return storage },
+		revision: storage.Revision,
+		subscribe: func(send sendChangeCb) func() {
+			return storage.Subscribe(func(change playlists.Change) {
+				if playbackStorage.Stopped {
+					send(change.ChangeVariant, nil)
+					return
+				}
+
+				send(change.ChangeVariant, change.Playlist)
+			}, func(err error) {})
+		},
+	}
+}
+
+func statusChannel(storage *status.Storage) stateChannel {
+	return stateChannel{
+		replay:   func() interface{} { return storage },
+		revision: storage.Revision,
+		subscribe: func(send sendChangeCb) func() {
+			return storage.Subscribe(func(change status.Change) {
+				send(change.ChangeVariant, storage)
+			}, func(err error) {})
+		},
+	}
+}
@@ -0,0 +1,257 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/internal/sse"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/status"
+)
+
+const (
+	clientNameArg = "client"
+
+	// pendingRequestsSize is a number of playback requests that may wait for execution before reading of next messages is held.
+	pendingRequestsSize = 16
+
+	writeTimeout = 10 * time.Second
+)
+
+// connection holds a single WebSocket connection with channels subscribed on it.
+// Playback requests are executed one at a time in the order they were sent, without holding reading of other requests.
+type connection struct {
+	clientName    string
+	connectedAt   time.Time
+	id            string
+	playback      chan request
+	req           *http.Request
+	subscriptions map[state_sse.ChannelVariant]func()
+	writeLock     *sync.Mutex
+	ws            *websocket.Conn
+}
+
+func (c *connection) String() string {
+	return fmt.Sprintf("%s (ws connection %s)", c.req.RemoteAddr, c.id)
+}
+
+// send writes a message to the client. Connection is closed when the message could not be written,
+// which ends reading of the connection.
+func (c *connection) send(message interface{}) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.ws.SetWriteDeadline(time.Now().Add(writeTimeout))
+	err := c.ws.WriteJSON(message)
+	if err != nil {
+		c.ws.Close()
+	}
+
+	return err
+}
+
+func (c *connection) statusConnection() status.Connection {
+	return status.Connection{
+		ClientName:  c.clientName,
+		ConnectedAt: c.connectedAt,
+		RemoteAddr:  c.req.RemoteAddr,
+		UserAgent:   c.req.UserAgent(),
+	}
+}
+
+func (s *Server) connectHandler(res http.ResponseWriter, req *http.Request) {
+	ws, err := s.upgrader.Upgrade(res, req, nil)
+	if err != nil {
+		s.errLog.Printf("could not upgrade connection from %s: %s\n", req.RemoteAddr, err)
+		return
+	}
+	defer ws.Close()
+
+	conn := &connection{
+		clientName:    req.URL.Query().Get(clientNameArg),
+		connectedAt:   time.Now(),
+		id:            uuid.NewString(),
+		playback:      make(chan request, pendingRequestsSize),
+		req:           req,
+		subscriptions: map[state_sse.ChannelVariant]func(){},
+		writeLock:     &sync.Mutex{},
+		ws:            ws,
+	}
+
+	s.outLog.Printf("accepted connection from %s\n", conn)
+	defer s.outLog.Printf("closed connection from %s\n", conn)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	go s.executePlaybackRequests(conn)
+	defer close(conn.playback)
+
+	defer s.unsubscribeAll(conn)
+
+	s.readRequests(conn)
+}
+
+// readRequests handles requests of the client until the connection is closed.
+func (s *Server) readRequests(conn *connection) {
+	for {
+		_, message, err := conn.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req request
+		err = json.Unmarshal(message, &req)
+		if err != nil {
+			conn.send(newResponse(req.ID, err))
+			continue
+		}
+
+		switch req.Type {
+		case playbackMessage:
+			conn.playback <- req
+		case subscribeMessage:
+			conn.send(newResponse(req.ID, s.subscribe(conn, req.Channels, req.Replay)))
+		case unsubscribeMessage:
+			conn.send(newResponse(req.ID, s.unsubscribe(conn, req.Channels)))
+		default:
+			conn.send(newResponse(req.ID, fmt.Errorf("%w: %s", errUnknownMessageType, req.Type)))
+		}
+	}
+}
+
+func (s *Server) executePlaybackRequests(conn *connection) {
+	for req := range conn.playback {
+		err := s.playbackCommands.Execute(req.Args, conn.String())
+		if err != nil {
+			s.errLog.Printf("could not handle playback request from %s: %s\n", conn, err)
+		}
+
+		conn.send(newResponse(req.ID, err))
+	}
+}
+
+// subscribe starts sending changes of the channels on the connection. When replay is requested,
+// whole state of each channel is sent right after subscribing, so no change is missed between the replay and the subscription.
+// Channels already subscribed are not replayed.
+// Changes are queued for each subscribed channel, so a slow client does not hold back broadcasting of the changes.
+func (s *Server) subscribe(conn *connection, channelVariants []state_sse.ChannelVariant, replay bool) error {
+	for _, channelVariant := range channelVariants {
+		if _, ok := s.channels[channelVariant]; !ok {
+			return fmt.Errorf("%w: %s", errUnknownChannel, channelVariant)
+		}
+	}
+
+	for _, channelVariant := range channelVariants {
+		if _, subscribed := conn.subscriptions[channelVariant]; subscribed {
+			continue
+		}
+
+		channel := s.channels[channelVariant]
+		queue := sse.NewObserverQueue[queuedChange](s.queueConfig(conn, channelVariant))
+		unsubscribe := channel.subscribe(func(changeVariant common.ChangeVariant, payload interface{}) {
+			queue.Push(queuedChange{
+				payload: payload,
+				variant: changeVariant,
+			}, channel.revision())
+		})
+		conn.subscriptions[channelVariant] = func() {
+			unsubscribe()
+			queue.Close()
+		}
+		s.statesRepository.Status().AddObservingConnection(conn.id, conn.statusConnection(), channelVariant)
+
+		var err error
+		if replay {
+			err = conn.send(replayEvent(channelVariant, channel))
+		}
+
+		go sendChanges(conn, channelVariant, channel, queue)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendChanges sends queued changes of the channel on the connection until the queue is closed or writing fails.
+// When changes were dropped from the queue of a channel with partial state, whole state of the channel is sent instead.
+func sendChanges(conn *connection, channelVariant state_sse.ChannelVariant, channel stateChannel, queue *sse.ObserverQueue[queuedChange]) {
+	for {
+		change, revision, replay, ok := queue.Pop()
+		if !ok {
+			return
+		}
+
+		ev := event{
+			Channel:  channelVariant,
+			Data:     change.payload,
+			Revision: revision,
+			Type:     eventMessage,
+			Variant:  change.variant,
+		}
+		if replay {
+			ev = replayEvent(channelVariant, channel)
+		}
+
+		err := conn.send(ev)
+		if err != nil {
+			return
+		}
+	}
+}
+
+func replayEvent(channelVariant state_sse.ChannelVariant, channel stateChannel) event {
+	return event{
+		Channel:  channelVariant,
+		Data:     channel.replay(),
+		Revision: channel.revision(),
+		Type:     eventMessage,
+		Variant:  replayChange,
+	}
+}
+
+func (s *Server) unsubscribe(conn *connection, channelVariants []state_sse.ChannelVariant) error {
+	for _, channelVariant := range channelVariants {
+		if _, ok := s.channels[channelVariant]; !ok {
+			return fmt.Errorf("%w: %s", errUnknownChannel, channelVariant)
+		}
+	}
+
+	for _, channelVariant := range channelVariants {
+		unsubscribe, ok := conn.subscriptions[channelVariant]
+		if !ok {
+			continue
+		}
+
+		unsubscribe()
+		delete(conn.subscriptions, channelVariant)
+		s.statesRepository.Status().RemoveObservingConnection(conn.id, channelVariant)
+	}
+
+	return nil
+}
+
+func (s *Server) unsubscribeAll(conn *connection) {
+	for channelVariant, unsubscribe := range conn.subscriptions {
+		unsubscribe()
+		s.statesRepository.Status().RemoveObservingConnection(conn.id, channelVariant)
+	}
+
+	conn.subscriptions = map[state_sse.ChannelVariant]func(){}
+}
@@ -0,0 +1,163 @@
+package ws
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/api"
+	"github.com/sarpt/mpv-web-api/pkg/state"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
+)
+
+type testPluginApi struct {
+	api.PluginApi
+	pause chan bool
+}
+
+func (tpa *testPluginApi) ChangePause(paused bool) error {
+	tpa.pause <- paused
+
+	return nil
+}
+
+func connectTestClient(t *testing.T) (*websocket.Conn, *testPluginApi, state.Repository) {
+	t.Helper()
+
+	testApi := &testPluginApi{pause: make(chan bool, 1)}
+	repository := state.NewRepository()
+	uut := NewServer(Config{
+		ErrWriter:        io.Discard,
+		OutWriter:        io.Discard,
+		StatesRepository: repository,
+	})
+	uut.Init(testApi)
+
+	server := httptest.NewServer(uut.Handler())
+	t.Cleanup(server.Close)
+	t.Cleanup(uut.Shutdown)
+
+	client, _, err := websocket.DefaultDialer.Dial(strings.Replace(server.URL, "http", "ws", 1)+connectPath, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error while connecting: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	return client, testApi, repository
+}
+
+func TestPlaybackRequest(t *testing.T) {
+	// given
+	client, testApi, _ := connectTestClient(t)
+
+	// when
+	err := client.WriteJSON(map[string]interface{}{"id": "1", "type": "playback", "args": map[string]interface{}{"pause": true}})
+	if err != nil {
+		t.Fatalf("Unexpected error while sending request: %s", err)
+	}
+
+	// then
+	var res response
+	err = client.ReadJSON(&res)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading response: %s", err)
+	}
+
+	if res.ID != "1" || !res.Ok {
+		t.Errorf("Expected successful response to request '1', got %+v", res)
+	}
+
+	if paused := <-testApi.pause; !paused {
+		t.Errorf("Expected pause to be changed to true")
+	}
+}
+
+func TestSubscribeToUnknownChannel(t *testing.T) {
+	// given
+	client, _, _ := connectTestClient(t)
+
+	// when
+	err := client.WriteJSON(map[string]interface{}{"id": "2", "type": "subscribe", "channels": []string{"unknown"}})
+	if err != nil {
+		t.Fatalf("Unexpected error while sending request: %s", err)
+	}
+
+	// then
+	var res response
+	err = client.ReadJSON(&res)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading response: %s", err)
+	}
+
+	if res.ID != "2" || res.Ok || res.Error == "" {
+		t.Errorf("Expected failed response to request '2', got %+v", res)
+	}
+}
+
+func TestSubscribeWithReplay(t *testing.T) {
+	// given
+	client, _, _ := connectTestClient(t)
+
+	// when
+	err := client.WriteJSON(map[string]interface{}{"id": "3", "type": "subscribe", "channels": []string{"playback"}, "replay": true})
+	if err != nil {
+		t.Fatalf("Unexpected error while sending request: %s", err)
+	}
+
+	// then
+	var replay event
+	err = client.ReadJSON(&replay)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading replay: %s", err)
+	}
+
+	if replay.Type != eventMessage || replay.Channel != playbackChannelVariant || replay.Variant != replayChange {
+		t.Errorf("Expected replay event of playback channel, got %+v", replay)
+	}
+
+	var res response
+	err = client.ReadJSON(&res)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading response: %s", err)
+	}
+
+	if res.ID != "3" || !res.Ok {
+		t.Errorf("Expected successful response to request '3', got %+v", res)
+	}
+}
+
+func TestSubscribedChangesAreSent(t *testing.T) {
+	// given
+	client, _, repository := connectTestClient(t)
+
+	err := client.WriteJSON(map[string]interface{}{"id": "4", "type": "subscribe", "channels": []string{"logs"}})
+	if err != nil {
+		t.Fatalf("Unexpected error while sending request: %s", err)
+	}
+
+	var res response
+	err = client.ReadJSON(&res)
+	if err != nil || !res.Ok {
+		t.Fatalf("Expected successful response to request '4', got %+v (error: %v)", res, err)
+	}
+
+	// when
+	repository.Logs().Add(logs.Entry{Level: logs.ErrorLevel, Message: "test"})
+
+	// then
+	var change event
+	err = client.ReadJSON(&change)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading event: %s", err)
+	}
+
+	if change.Channel != logsChannelVariant || change.Variant != common.ChangeVariant(logs.ErrorLevel) || change.Revision == 0 {
+		t.Errorf("Expected error event of logs channel with revision, got %+v", change)
+	}
+}
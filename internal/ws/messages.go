@@ -0,0 +1,74 @@
+package ws
+
+import (
+	"errors"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/internal/playback_commands"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+const (
+	// eventMessage carries a change of a subscribed channel.
+	eventMessage messageType = "event"
+
+	// playbackMessage requests playback operations, the same as POST /rest/playback.
+	playbackMessage messageType = "playback"
+
+	// responseMessage carries result of a request, with the id of the request.
+	responseMessage messageType = "response"
+
+	// subscribeMessage requests changes of channels to be sent on the connection.
+	subscribeMessage messageType = "subscribe"
+
+	// unsubscribeMessage requests changes of channels to no longer be sent on the connection.
+	unsubscribeMessage messageType = "unsubscribe"
+)
+
+var (
+	errUnknownChannel     = errors.New("unknown channel")
+	errUnknownMessageType = errors.New("unknown message type")
+)
+
+// messageType specifies what the message sent over the connection is.
+type messageType string
+
+// request is a message sent by the client. Responses to the request carry the same id,
+// which is chosen by the client to correlate responses with its requests.
+type request struct {
+	Args     playback_commands.Args     `json:"args"`
+	Channels []state_sse.ChannelVariant `json:"channels"`
+	ID       string                     `json:"id"`
+	Replay   bool                       `json:"replay"`
+	Type     messageType                `json:"type"`
+}
+
+// response informs the client whether its request succeeded.
+type response struct {
+	Error string      `json:"error,omitempty"`
+	ID    string      `json:"id"`
+	Ok    bool        `json:"ok"`
+	Type  messageType `json:"type"`
+}
+
+func newResponse(id string, err error) response {
+	res := response{
+		ID:   id,
+		Ok:   err == nil,
+		Type: responseMessage,
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+// event carries a change of a channel subscribed on the connection.
+type event struct {
+	Channel  state_sse.ChannelVariant `json:"channel"`
+	Data     interface{}              `json:"data"`
+	Revision uint64                   `json:"revision"`
+	Type     messageType              `json:"type"`
+	Variant  common.ChangeVariant     `json:"variant"`
+}
@@ -0,0 +1,151 @@
+package ws
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/sarpt/mpv-web-api/internal/playback_commands"
+	"github.com/sarpt/mpv-web-api/internal/sse"
+	"github.com/sarpt/mpv-web-api/pkg/api"
+	"github.com/sarpt/mpv-web-api/pkg/state"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
+	state_sse "github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+const (
+	logPrefix = "ws.Server#"
+
+	name     = "WebSocket Server"
+	pathBase = "ws"
+)
+
+var (
+	connectPath = fmt.Sprintf("/%s/connect", pathBase)
+)
+
+// Server handles WebSocket connections, which multiplex changes of the same state channels as SSE server
+// and accept playback commands, so clients need only a single bidirectional connection.
+type Server struct {
+	allowCORS        bool
+	cancel           context.CancelFunc
+	channels         map[state_sse.ChannelVariant]stateChannel
+	ctx              context.Context
+	errLog           *log.Logger
+	outLog           *log.Logger
+	playbackCommands *playback_commands.Executor
+	queuePolicy      sse.OverflowPolicy
+	queueSize        int
+	statesRepository state.Repository
+	upgrader         websocket.Upgrader
+}
+
+// Config controls behaviour of the WebSocket server.
+// AllowCORS allows upgrading connections requested from any origin.
+// QueueSize and QueuePolicy control how many changes may wait to be sent on each connection for each subscribed channel,
+// and what happens when the client does not receive them fast enough - the same as for SSE observers.
+type Config struct {
+	AllowCORS        bool
+	ErrWriter        io.Writer
+	OutWriter        io.Writer
+	QueuePolicy      sse.OverflowPolicy
+	QueueSize        int
+	StatesRepository state.Repository
+}
+
+// NewServer prepares and returns WebSocket server.
+func NewServer(cfg Config) *Server {
+	outWriter, errWriter := cfg.StatesRepository.Logs().TeeWriters(logs.WsSource, cfg.OutWriter, cfg.ErrWriter)
+
+	queuePolicy := cfg.QueuePolicy
+	if queuePolicy == "" {
+		queuePolicy = sse.DropOldestPolicy
+	}
+
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = sse.DefaultQueueSize
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		allowCORS:        cfg.AllowCORS,
+		cancel:           cancel,
+		channels:         map[state_sse.ChannelVariant]stateChannel{},
+		ctx:              ctx,
+		errLog:           log.New(errWriter, logPrefix, log.LstdFlags),
+		outLog:           log.New(outWriter, logPrefix, log.LstdFlags),
+		queuePolicy:      queuePolicy,
+		queueSize:        queueSize,
+		statesRepository: cfg.StatesRepository,
+	}
+
+	s.upgrader = websocket.Upgrader{
+		CheckOrigin: s.checkOrigin,
+	}
+
+	return s
+}
+
+// Handler returns handler of WebSocket connections.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(connectPath, s.connectHandler)
+
+	return mux
+}
+
+func (s *Server) Init(apiServer api.PluginApi) error {
+	s.playbackCommands = playback_commands.NewExecutor(apiServer, s.outLog)
+	s.channels = stateChannels(s.statesRepository)
+
+	return nil
+}
+
+func (s *Server) Name() string {
+	return name
+}
+
+func (s *Server) PathBase() string {
+	return pathBase
+}
+
+func (s *Server) Shutdown() {
+	s.cancel()
+}
+
+// queueConfig returns configuration of the queue of changes of the channel variant subscribed on the connection.
+// Changes not delivered to the client are reported to the status state, and the connection is closed when the queue policy says so.
+func (s *Server) queueConfig(conn *connection, channelVariant state_sse.ChannelVariant) sse.QueueConfig {
+	return sse.QueueConfig{
+		OnOverflow: func(dropped int, disconnected bool) {
+			if disconnected {
+				s.errLog.Printf("disconnecting %s due to full queue of changes of channel '%s'\n", conn, channelVariant)
+				conn.ws.Close()
+			}
+
+			s.statesRepository.Status().AddObserversDrops(channelVariant, dropped, disconnected)
+		},
+		Policy:       s.queuePolicy,
+		ReplayOnDrop: sse.IsPartialStateChannel(channelVariant),
+		Size:         s.queueSize,
+	}
+}
+
+// checkOrigin accepts only same origin connections, unless CORS is allowed.
+func (s *Server) checkOrigin(req *http.Request) bool {
+	if s.allowCORS {
+		return true
+	}
+
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	return origin == fmt.Sprintf("http://%s", req.Host) || origin == fmt.Sprintf("https://%s", req.Host)
+}
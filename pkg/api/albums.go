@@ -0,0 +1,58 @@
+package api
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playlists"
+)
+
+const (
+	albumPlaylistFilenameFormat string = "album_%s.json"
+)
+
+// LoadAlbum instructs mpv to play tracks of the album in order of their disc and track numbers.
+// Tracks are put on the temporary playlist, which is reused when the same album is loaded again.
+// Append has the same meaning as in LoadPlaylist.
+func (s *Server) LoadAlbum(id string, append bool) error {
+	album, tracks, err := s.statesRepository.MediaFiles().Album(id)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]playlists.Entry, len(tracks))
+	for idx, track := range tracks {
+		entries[idx] = playlists.Entry{
+			Path: track.Path(),
+		}
+	}
+
+	playlistPath := path.Join(s.appDir, tempPlaylistsDirname, fmt.Sprintf(albumPlaylistFilenameFormat, id))
+	uuid, ok := s.playlistUUIDByPath(playlistPath)
+	if ok {
+		err = s.statesRepository.Playlists().SetPlaylistEntries(uuid, entries)
+	} else {
+		uuid, err = s.statesRepository.Playlists().AddPlaylist(playlists.NewPlaylist(playlists.Config{
+			Description: album.Artist,
+			Entries:     entries,
+			Name:        album.Name,
+			Origin:      playlists.TempOrigin,
+			Path:        playlistPath,
+		}))
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.LoadPlaylist(uuid, append)
+}
+
+func (s *Server) playlistUUIDByPath(playlistPath string) (string, bool) {
+	for uuid, playlist := range s.statesRepository.Playlists().All() {
+		if playlist.Path() == playlistPath {
+			return uuid, true
+		}
+	}
+
+	return "", false
+}
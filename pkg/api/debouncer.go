@@ -0,0 +1,75 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// keyedDebouncer delays handling of consecutive events for the same key (eg. path of a file or uuid of a playlist)
+// until no new events for that key arrive for the settle duration.
+type keyedDebouncer struct {
+	lock       *sync.Mutex
+	settle     time.Duration
+	generation uint64
+	pending    map[string]pendingCallback
+}
+
+// pendingCallback holds the settle timer with the generation it was scheduled with.
+// Stopping the timer does not prevent an already fired callback from running, so the callback
+// checks its generation against the pending one and bails out when it was cancelled or rescheduled.
+type pendingCallback struct {
+	timer      *time.Timer
+	generation uint64
+}
+
+func newKeyedDebouncer(settle time.Duration) *keyedDebouncer {
+	return &keyedDebouncer{
+		lock:    &sync.Mutex{},
+		settle:  settle,
+		pending: map[string]pendingCallback{},
+	}
+}
+
+// Schedule (re)starts the settle timer for the key. The cb is invoked only once
+// after the key settles, with any earlier scheduled callbacks discarded.
+func (d *keyedDebouncer) Schedule(key string, cb func(key string)) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if pending, ok := d.pending[key]; ok {
+		pending.timer.Stop()
+	}
+
+	d.generation++
+	generation := d.generation
+	d.pending[key] = pendingCallback{
+		generation: generation,
+		timer: time.AfterFunc(d.settle, func() {
+			d.lock.Lock()
+			pending, ok := d.pending[key]
+			if !ok || pending.generation != generation {
+				d.lock.Unlock()
+				return
+			}
+
+			delete(d.pending, key)
+			d.lock.Unlock()
+
+			cb(key)
+		}),
+	}
+}
+
+// Cancel discards pending callback for the key (if any).
+func (d *keyedDebouncer) Cancel(key string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	pending, ok := d.pending[key]
+	if !ok {
+		return
+	}
+
+	pending.timer.Stop()
+	delete(d.pending, key)
+}
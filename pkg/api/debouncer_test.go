@@ -0,0 +1,45 @@
+package api
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testSettle = 10 * time.Millisecond
+
+func TestKeyedDebouncer_CoalescesScheduledCallbacks(t *testing.T) {
+	// given
+	var calls atomic.Int32
+	debouncer := newKeyedDebouncer(testSettle)
+
+	// when
+	for i := 0; i < 3; i++ {
+		debouncer.Schedule("key", func(string) { calls.Add(1) })
+	}
+	time.Sleep(5 * testSettle)
+
+	// then
+	if calls.Load() != 1 {
+		t.Errorf("Expected callback to be called once, got %d calls", calls.Load())
+	}
+}
+
+func TestKeyedDebouncer_CancelDiscardsFiredCallback(t *testing.T) {
+	// given
+	var calls atomic.Int32
+	debouncer := newKeyedDebouncer(testSettle)
+	debouncer.Schedule("key", func(string) { calls.Add(1) })
+
+	// when
+	debouncer.lock.Lock()
+	time.Sleep(5 * testSettle)       // timer fires and its callback waits for the lock
+	delete(debouncer.pending, "key") // same as Cancel, which cannot acquire the held lock
+	debouncer.lock.Unlock()
+	time.Sleep(5 * testSettle)
+
+	// then
+	if calls.Load() != 0 {
+		t.Errorf("Expected cancelled callback not to be called, got %d calls", calls.Load())
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playlists"
@@ -15,7 +15,9 @@ import (
 
 // readDirectory tries to read and probe directory,
 // adding found media files inside the directory.
-func (s *Server) readDirectory(path string, cacheEntry *CacheDirEntry) error {
+// Files not allowed by the directory rules or ignore files are skipped.
+func (s *Server) readDirectory(dir directories.Entry, cacheEntry *CacheDirEntry) error {
+	path := dir.Path
 	pathFs := os.DirFS(path)
 	dirEntries, err := fs.ReadDir(pathFs, ".")
 	if err != nil {
@@ -23,6 +25,14 @@ func (s *Server) readDirectory(path string, cacheEntry *CacheDirEntry) error {
 	}
 
 	s.outLog.Printf("reading directory %s\n", path)
+	var fileNames []string
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			fileNames = append(fileNames, entry.Name())
+		}
+	}
+	sidecarDir := sidecar.NewDirectory(path, fileNames)
+
 	var playlistUUIDs []string
 	var playlistEntries []playlists.Entry
 	for _, entry := range dirEntries {
@@ -31,6 +41,9 @@ func (s *Server) readDirectory(path string, cacheEntry *CacheDirEntry) error {
 		}
 
 		entryPath := filepath.Join(path, entry.Name())
+		if !s.isPathAllowed(dir, entryPath, false) {
+			continue
+		}
 
 		if s.hasPlaylistFilePrefix(entryPath) {
 			playlist, err := s.handlePlaylistFile(entryPath)
@@ -55,13 +68,17 @@ func (s *Server) readDirectory(path string, cacheEntry *CacheDirEntry) error {
 			s.errLog.Printf("could not handle file with playlist prefix: %s", err)
 		}
 
-		result := probe.File(entryPath)
+		if isPartialDownload(entryPath) || sidecarDir.IsSidecarFile(entryPath) {
+			continue
+		}
+
+		result := s.prober.File(entryPath)
 		if !result.IsMediaFile() {
 			continue
 		}
 
-		mediaFile := media_files.MapProbeResultToMediaFile(result)
-		s.statesRepository.MediaFiles().Add(mediaFile)
+		mediaFile := media_files.MapProbeResultToMediaFile(result).WithMetadata(sidecarDir.MediaFile(entryPath))
+		s.statesRepository.MediaFiles().Add(s.withUserMetadata(mediaFile))
 		if cacheEntry != nil {
 			info, err := entry.Info()
 			if err != nil {
@@ -89,8 +106,8 @@ func (s *Server) readDirectory(path string, cacheEntry *CacheDirEntry) error {
 			continue
 		}
 
-		if !playlist.DirectoryContentsAsEntries() {
-			continue
+		if !playlist.DirectoryContentsAsEntries() || playlist.Query() != nil {
+			continue // entries of smart playlists are selected by their query
 		}
 
 		s.statesRepository.Playlists().SetPlaylistEntries(uuid, playlistEntries)
@@ -99,12 +116,20 @@ func (s *Server) readDirectory(path string, cacheEntry *CacheDirEntry) error {
 	return err
 }
 
-func (s *Server) restoreDirectoryFromCache(cacheEntry *CacheDirEntry) {
-	for _, cacheEntry := range cacheEntry.MediaFiles {
-		s.statesRepository.MediaFiles().Add(cacheEntry.Entry)
+func (s *Server) restoreDirectoryFromCache(dir directories.Entry, cacheEntry *CacheDirEntry) {
+	for path, cacheEntry := range cacheEntry.MediaFiles {
+		if !s.isPathAllowed(dir, path, false) {
+			continue
+		}
+
+		s.statesRepository.MediaFiles().Add(s.withUserMetadata(cacheEntry.Entry))
 	}
 
-	for _, cacheEntry := range cacheEntry.Playlists {
+	for path, cacheEntry := range cacheEntry.Playlists {
+		if !s.isPathAllowed(dir, path, false) {
+			continue
+		}
+
 		_, err := s.statesRepository.Playlists().AddPlaylist(&cacheEntry.Playlist)
 		if err != nil {
 			s.errLog.Printf("could not restore playlist '%s'", cacheEntry.Name())
@@ -165,13 +190,19 @@ func (s *Server) AddRootDirectories(rootDirectories []directories.Entry) {
 				return fs.SkipDir
 			}
 
-			cacheEntry := s.processCacheEntry(cache, path, dirEntry)
-			subDir := directories.Entry{
-				Path:      path,
-				Recursive: rootDir.Recursive,
-				Watched:   rootDir.Watched,
+			subDir := rootDir
+			subDir.Path = path
+			if rootPath != path {
+				subDir = rootDir.Child(path)
+				if !s.isPathAllowed(subDir, path, true) {
+					s.outLog.Printf("skipping directory '%s' due to directory rules\n", path)
+
+					return fs.SkipDir
+				}
 			}
 
+			cacheEntry := s.processCacheEntry(cache, path, dirEntry)
+
 			addDirErr := s.AddDirectory(subDir, cacheEntry)
 			if addDirErr != nil {
 				s.errLog.Printf("could not add directory '%s': %s\n", path, addDirErr)
@@ -190,14 +221,14 @@ func (s *Server) AddRootDirectories(rootDirectories []directories.Entry) {
 
 func (s *Server) AddDirectory(dir directories.Entry, cacheEntry *CacheDirEntry) error {
 	prevDir, err := s.statesRepository.Directories().ByPath(dir.Path)
-	if err == nil && prevDir.Watched {
+	if err == nil && prevDir.WatchedByFsEvents() {
 		err := s.fsWatcher.Remove(prevDir.Path)
 		if err != nil {
 			return err
 		}
 	}
 
-	if dir.Watched {
+	if dir.WatchedByFsEvents() {
 		err := s.fsWatcher.Add(dir.Path)
 		if err != nil {
 			return err
@@ -206,18 +237,20 @@ func (s *Server) AddDirectory(dir directories.Entry, cacheEntry *CacheDirEntry)
 
 	if s.useCache && cacheEntry != nil && !cacheEntry.stale {
 		s.outLog.Printf("restoring \"%s\" from cache", dir.Path)
-		s.restoreDirectoryFromCache(cacheEntry)
+		s.restoreDirectoryFromCache(dir, cacheEntry)
 	} else {
 		if s.useCache {
 			s.outLog.Printf("cache unavailable or stale for entry \"%s\"", dir.Path)
 		}
 
-		err = s.readDirectory(dir.Path, cacheEntry)
+		err = s.readDirectory(dir, cacheEntry)
 		if err != nil {
 			return err
 		}
 	}
 
+	dir.Metadata = s.readDirectoryMetadata(dir.Path)
+	dir.UserMetadata = s.userMetadata.Get(directories.EnsureDirectoryPath(dir.Path))
 	s.statesRepository.Directories().Add(dir)
 
 	return nil
@@ -229,12 +262,16 @@ func (s *Server) TakeDirectory(path string) (directories.Entry, error) {
 		return directories.Entry{}, fmt.Errorf("could not remove directory '%s' - directory was not added", path)
 	}
 
-	if dir.Watched {
+	if dir.WatchedByFsEvents() {
 		if err := s.fsWatcher.Remove(dir.Path); err != nil {
 			return directories.Entry{}, fmt.Errorf("could not stop watching fs changes for a directory '%s': %s", path, err)
 		}
 	}
 
+	if dir.Polled {
+		s.fsPoller.forget(dir.Path)
+	}
+
 	dir, err = s.statesRepository.Directories().Take(path)
 	if err != nil {
 		return dir, fmt.Errorf("could not take directory '%s': %s", path, err)
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/sarpt/mpv-web-api/pkg/duplicates"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+)
+
+// MediaFileDuplicates returns groups of served media files with the same contents, along with the number
+// of media files still waiting to be fingerprinted (groups may be incomplete until it drops to 0).
+func (s *Server) MediaFileDuplicates() ([]duplicates.Group, int) {
+	return s.duplicates.Groups(), s.duplicates.Pending()
+}
+
+// watchForDuplicates schedules fingerprinting of media files whenever they are added or updated.
+func (s *Server) watchForDuplicates() {
+	s.statesRepository.MediaFiles().Subscribe(func(change media_files.Change) {
+		for path := range change.Items {
+			switch change.ChangeVariant {
+			case media_files.AddedMediaFilesChange, media_files.UpdatedMediaFilesChange:
+				s.duplicates.Add(path)
+			case media_files.RemovedMediaFilesChange:
+				s.duplicates.Remove(path)
+			}
+		}
+	}, func(err error) {})
+
+	for path := range s.statesRepository.MediaFiles().All() {
+		s.duplicates.Add(path)
+	}
+}
+
+func (s *Server) handleDuplicatesFingerprintError(path string, err error) {
+	s.errLog.Printf("could not fingerprint '%s' for duplicates detection: %s\n", path, err)
+}
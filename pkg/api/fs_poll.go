@@ -0,0 +1,202 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+)
+
+const (
+	// DefaultFsPollInterval is a default interval between listings of polled directories.
+	DefaultFsPollInterval = 30 * time.Second
+)
+
+// fsPollEntry is a snapshot of a directory child used to detect modifications between polls.
+type fsPollEntry struct {
+	isDir   bool
+	modTime time.Time
+	size    int64
+}
+
+// fsPoller periodically lists polled directories and compares them with the served state.
+// It's a fallback for file systems which do not emit inotify events (NFS, SMB, FUSE, etc.).
+type fsPoller struct {
+	interval  time.Duration
+	lock      *sync.Mutex
+	snapshots map[string]map[string]fsPollEntry
+}
+
+func newFsPoller(interval time.Duration) *fsPoller {
+	if interval <= 0 {
+		interval = DefaultFsPollInterval
+	}
+
+	return &fsPoller{
+		interval:  interval,
+		lock:      &sync.Mutex{},
+		snapshots: map[string]map[string]fsPollEntry{},
+	}
+}
+
+// forget removes snapshot of the directory, so the next poll of the directory
+// (if it's added again) is treated as an initial one.
+func (p *fsPoller) forget(dirPath string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.snapshots, directories.EnsureDirectoryPath(dirPath))
+}
+
+func (p *fsPoller) swapSnapshot(dirPath string, snapshot map[string]fsPollEntry) (map[string]fsPollEntry, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	prevSnapshot, ok := p.snapshots[dirPath]
+	p.snapshots[dirPath] = snapshot
+
+	return prevSnapshot, ok
+}
+
+func (s *Server) watchForFsChangesByPolling() {
+	go func() {
+		ticker := time.NewTicker(s.fsPoller.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			for _, dir := range s.statesRepository.Directories().All() {
+				if !dir.Watched || !dir.Polled {
+					continue
+				}
+
+				err := s.pollDirectory(dir)
+				if err != nil {
+					s.errLog.Printf("could not poll directory '%s' due to an error: %s\n", dir.Path, err)
+				}
+			}
+		}
+	}()
+}
+
+// pollDirectory lists direct children of the directory and feeds differences between the listing
+// and media files & directories states to the same handlers as fs watcher events.
+func (s *Server) pollDirectory(dir directories.Entry) error {
+	dirPath := directories.EnsureDirectoryPath(dir.Path)
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.fsPoller.forget(dirPath)
+			return s.removeFsEventTarget(dirPath)
+		}
+
+		return err
+	}
+
+	snapshot := map[string]fsPollEntry{}
+	// entries listed, but which information could not be read (eg. removed in the meantime or temporarily inaccessible) -
+	// they are kept as they were in the previous snapshot, so they are neither removed nor probed until the next poll
+	unreadable := map[string]bool{}
+	for _, dirEntry := range dirEntries {
+		path := filepath.Join(dirPath, dirEntry.Name())
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			unreadable[path] = true
+			snapshot[path] = fsPollEntry{
+				isDir: dirEntry.IsDir(),
+			}
+
+			continue
+		}
+
+		snapshot[path] = fsPollEntry{
+			isDir:   dirEntry.IsDir(),
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+
+	prevSnapshot, polledBefore := s.fsPoller.swapSnapshot(dirPath, snapshot)
+	for path := range unreadable {
+		if prevEntry, ok := prevSnapshot[path]; ok {
+			snapshot[path] = prevEntry
+		}
+	}
+
+	for _, path := range s.statesRepository.MediaFiles().PathsUnderParent(dirPath) {
+		if filepath.Dir(path) != filepath.Clean(dirPath) {
+			continue
+		}
+
+		if _, ok := snapshot[path]; ok {
+			continue
+		}
+
+		err := s.removeFsEventTarget(path)
+		if err != nil {
+			s.errLog.Printf("could not remove polled media file '%s': %s\n", path, err)
+		}
+	}
+
+	for path := range s.statesRepository.Directories().All() {
+		if filepath.Clean(path) == filepath.Clean(dirPath) || filepath.Dir(filepath.Clean(path)) != filepath.Clean(dirPath) {
+			continue
+		}
+
+		if _, ok := snapshot[filepath.Clean(path)]; ok {
+			continue
+		}
+
+		s.fsPoller.forget(path)
+		err := s.removeFsEventTarget(path)
+		if err != nil {
+			s.errLog.Printf("could not remove polled directory '%s': %s\n", path, err)
+		}
+	}
+
+	for path := range prevSnapshot {
+		if _, ok := snapshot[path]; !ok && sidecar.IsSidecarFile(path) {
+			s.scheduleSidecarMetadataRefresh(path)
+		}
+	}
+
+	for path, entry := range snapshot {
+		if unreadable[path] {
+			continue
+		}
+
+		prevEntry, existedBefore := prevSnapshot[path]
+		if entry.isDir {
+			if existedBefore || s.statesRepository.Directories().Exists(path) {
+				continue
+			}
+
+			err := s.addFsEventTarget(path)
+			if err != nil {
+				s.errLog.Printf("could not add polled directory '%s': %s\n", path, err)
+			}
+
+			continue
+		}
+
+		// the first listing of a directory is reconciled with media files state instead, since files
+		// could have been created after the directory was read, or could have failed to be probed back then
+		if !polledBefore {
+			if !s.statesRepository.MediaFiles().Exists(path) {
+				s.scheduleFsEventTargetProbe(path)
+			}
+
+			continue
+		}
+
+		modified := !existedBefore || !prevEntry.modTime.Equal(entry.modTime) || prevEntry.size != entry.size
+		if modified {
+			s.scheduleFsEventTargetProbe(path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/state"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+const (
+	testPollSettle  = time.Millisecond
+	testPollTimeout = time.Second
+)
+
+// testPollProber treats files with ".mkv" extension as media files and counts how many times each file was probed.
+type testPollProber struct {
+	lock   *sync.Mutex
+	probes map[string]int
+}
+
+func (p *testPollProber) File(path string) probe.Result {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.probes[path]++
+	if filepath.Ext(path) != ".mkv" {
+		return probe.Result{Path: path, Err: errors.New("not a media file")}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return probe.Result{Path: path, Err: err}
+	}
+
+	return probe.Result{
+		Path:         path,
+		Format:       probe.Format{Size: info.Size()},
+		AudioStreams: []probe.AudioStream{{AudioID: "1"}},
+	}
+}
+
+func (p *testPollProber) Name() string {
+	return "test"
+}
+
+func (p *testPollProber) probeCount(path string) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.probes[path]
+}
+
+func newTestPollServer(t *testing.T, dirPath string) (*Server, *testPollProber) {
+	userMetadata, err := user_metadata.Load(filepath.Join(t.TempDir(), userMetadataFilename))
+	if err != nil {
+		t.Fatalf("Could not load user metadata: %s", err)
+	}
+
+	prober := &testPollProber{lock: &sync.Mutex{}, probes: map[string]int{}}
+	server := &Server{
+		errLog:            log.New(io.Discard, "", 0),
+		fsEventsDebouncer: newKeyedDebouncer(testPollSettle),
+		fsPoller:          newFsPoller(DefaultFsPollInterval),
+		ignoreFiles:       newIgnoreFiles(),
+		outLog:            log.New(io.Discard, "", 0),
+		prober:            prober,
+		statesRepository:  state.NewRepository(),
+		userMetadata:      userMetadata,
+	}
+	server.statesRepository.Directories().Add(directories.Entry{Path: directories.EnsureDirectoryPath(dirPath), Polled: true, Watched: true})
+
+	return server, prober
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("Could not write file '%s': %s", path, err)
+	}
+}
+
+func waitUntil(t *testing.T, description string, changed func() bool) {
+	deadline := time.Now().Add(testPollTimeout)
+	for time.Now().Before(deadline) {
+		if changed() {
+			return
+		}
+
+		time.Sleep(testPollSettle)
+	}
+
+	t.Fatalf("Expected %s in %s", description, testPollTimeout)
+}
+
+func TestPollDirectory(t *testing.T) {
+	// given
+	dirPath := t.TempDir()
+	server, prober := newTestPollServer(t, dirPath)
+	dir, _ := server.statesRepository.Directories().ByPath(directories.EnsureDirectoryPath(dirPath))
+
+	served := filepath.Join(dirPath, "served.mkv")
+	writeTestFile(t, served, "served")
+	server.statesRepository.MediaFiles().Add(media_files.MapProbeResultToMediaFile(prober.File(served)))
+	waitUntil(t, "served file to be added", func() bool {
+		return server.statesRepository.MediaFiles().Exists(served)
+	})
+
+	created := filepath.Join(dirPath, "created.mkv")
+	writeTestFile(t, created, "created after the directory was read")
+	notMedia := filepath.Join(dirPath, "notes.txt")
+	writeTestFile(t, notMedia, "notes")
+
+	// when the directory is polled for the first time
+	err := server.pollDirectory(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error on the first poll: %s", err)
+	}
+
+	// then files missing from the state are probed
+	waitUntil(t, "created file to be added", func() bool {
+		return server.statesRepository.MediaFiles().Exists(created)
+	})
+	time.Sleep(10 * testPollSettle)
+	if prober.probeCount(served) != 1 {
+		t.Errorf("Expected already served file not to be probed again, got %d probes", prober.probeCount(served))
+	}
+	if prober.probeCount(notMedia) != 1 {
+		t.Errorf("Expected not served file to be probed once, got %d probes", prober.probeCount(notMedia))
+	}
+
+	// when files are modified and removed
+	writeTestFile(t, served, "served and modified")
+	err = os.Remove(created)
+	if err != nil {
+		t.Fatalf("Could not remove file: %s", err)
+	}
+
+	err = server.pollDirectory(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error on the second poll: %s", err)
+	}
+
+	// then modified files are probed again and removed ones are taken out of the state
+	waitUntil(t, "modified file to be probed again", func() bool {
+		return prober.probeCount(served) == 2
+	})
+	if server.statesRepository.MediaFiles().Exists(created) {
+		t.Errorf("Expected removed file '%s' to be taken out of the state", created)
+	}
+
+	time.Sleep(10 * testPollSettle)
+	if prober.probeCount(notMedia) != 1 {
+		t.Errorf("Expected unmodified file not to be probed again, got %d probes", prober.probeCount(notMedia))
+	}
+}
@@ -3,9 +3,31 @@ package api
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
+)
+
+const (
+	// fsWriteSettleDuration is the time during which a file has to stay unmodified
+	// before it's (re)probed after being created or written to.
+	fsWriteSettleDuration = 2 * time.Second
+)
+
+var (
+	// partialDownloadSuffixes are extensions used by browsers and download managers
+	// for files that are still being downloaded. Such files are never probed -
+	// they will be handled when renamed to their final name.
+	partialDownloadSuffixes = []string{
+		".part",
+		".partial",
+		".crdownload",
+		".download",
+		".!qb",
+	}
 )
 
 func (s *Server) addFsEventTarget(path string) error {
@@ -15,12 +37,7 @@ func (s *Server) addFsEventTarget(path string) error {
 	}
 
 	if !fileInfo.IsDir() {
-		mediaFile, err := s.probeFile(path)
-		if err != nil {
-			return err
-		}
-
-		s.statesRepository.MediaFiles().Add(mediaFile)
+		s.scheduleFsEventTargetProbe(path)
 
 		return nil
 	}
@@ -34,10 +51,9 @@ func (s *Server) addFsEventTarget(path string) error {
 		return nil
 	}
 
-	dir := directories.Entry{
-		Path:      path,
-		Recursive: true,
-		Watched:   true,
+	dir := parentDir.Child(path)
+	if !s.isPathAllowed(dir, path, true) {
+		return nil
 	}
 
 	// Directories added in runtime should be stored to on-disk cache
@@ -49,7 +65,76 @@ func (s *Server) addFsEventTarget(path string) error {
 	return s.AddDirectory(dir, nil)
 }
 
+// scheduleFsEventTargetProbe delays probing of the file until it stops being modified.
+// Files that are still being downloaded, or not allowed by directory rules, are ignored.
+func (s *Server) scheduleFsEventTargetProbe(path string) {
+	if isPartialDownload(path) {
+		return
+	}
+
+	parentDir, err := s.statesRepository.Directories().ParentByPath(path)
+	if err == nil && !s.isPathAllowed(parentDir, path, false) {
+		return
+	}
+
+	if sidecar.IsSidecarFile(path) {
+		s.scheduleSidecarMetadataRefresh(path)
+
+		return
+	}
+
+	s.fsEventsDebouncer.Schedule(path, func(path string) {
+		err := s.updateFsEventTarget(path)
+		if err != nil {
+			s.errLog.Printf("could not handle settled file '%s' due to an error: %s\n", path, err)
+		}
+	})
+}
+
+// updateFsEventTarget probes the file and adds it as a new media file or updates already existing one.
+// When an already served file stops being a valid media file, it's removed.
+func (s *Server) updateFsEventTarget(path string) error {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if fileInfo.IsDir() {
+		return nil
+	}
+
+	exists := s.statesRepository.MediaFiles().Exists(path)
+	mediaFile, err := s.probeFile(path)
+	if err != nil {
+		if exists {
+			s.outLog.Printf("removing media file '%s' since it could not be probed anymore\n", path)
+			_, takeErr := s.statesRepository.MediaFiles().Take(path)
+			if takeErr != nil {
+				return takeErr
+			}
+		}
+
+		return err
+	}
+
+	if exists {
+		return s.statesRepository.MediaFiles().Update(mediaFile)
+	}
+
+	s.statesRepository.MediaFiles().Add(mediaFile)
+
+	return nil
+}
+
 func (s *Server) removeFsEventTarget(path string) error {
+	s.fsEventsDebouncer.Cancel(path)
+
+	if sidecar.IsSidecarFile(path) {
+		s.scheduleSidecarMetadataRefresh(path)
+
+		return nil
+	}
+
 	if s.statesRepository.MediaFiles().Exists(path) {
 		s.outLog.Printf("removing media file '%s'\n", path)
 		_, err := s.statesRepository.MediaFiles().Take(path)
@@ -75,6 +160,10 @@ func (s *Server) handleFsEvent(event fsnotify.Event) error {
 		return s.addFsEventTarget(event.Name)
 	}
 
+	if shouldUpdateFsEventTarget(event.Op) {
+		s.scheduleFsEventTargetProbe(event.Name)
+	}
+
 	return nil
 }
 
@@ -111,3 +200,19 @@ func shouldAddFsEventTarget(op fsnotify.Op) bool {
 func shouldRemoveFsEventTarget(op fsnotify.Op) bool {
 	return op&(fsnotify.Rename|fsnotify.Remove) != 0
 }
+
+func shouldUpdateFsEventTarget(op fsnotify.Op) bool {
+	return op&(fsnotify.Write|fsnotify.Chmod) != 0
+}
+
+// isPartialDownload checks whether path points to a file that is still being downloaded.
+func isPartialDownload(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, suffix := range partialDownloadSuffixes {
+		if ext == suffix {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+)
+
+const (
+	// ignoreFilename is a name of a file with exclude patterns (one per line) applied
+	// to the directory it's in and all of its subdirectories.
+	ignoreFilename          = ".mwaignore"
+	ignoreFileCommentPrefix = "#"
+)
+
+type ignoreFileEntry struct {
+	modTime  time.Time
+	patterns []string
+}
+
+// ignoreFiles caches patterns read from ignore files, re-reading them when their modification time changes.
+type ignoreFiles struct {
+	entries map[string]ignoreFileEntry
+	lock    *sync.Mutex
+}
+
+func newIgnoreFiles() *ignoreFiles {
+	return &ignoreFiles{
+		entries: map[string]ignoreFileEntry{},
+		lock:    &sync.Mutex{},
+	}
+}
+
+// Patterns returns patterns from an ignore file in the directory.
+// Missing or unreadable ignore file results in no patterns.
+func (i *ignoreFiles) Patterns(dirPath string) []string {
+	ignoreFilePath := filepath.Join(dirPath, ignoreFilename)
+
+	info, err := os.Stat(ignoreFilePath)
+	if err != nil {
+		i.lock.Lock()
+		delete(i.entries, ignoreFilePath)
+		i.lock.Unlock()
+
+		return nil
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	entry, ok := i.entries[ignoreFilePath]
+	if ok && entry.modTime.Equal(info.ModTime()) {
+		return entry.patterns
+	}
+
+	patterns, err := readIgnoreFile(ignoreFilePath)
+	if err != nil {
+		return nil
+	}
+
+	i.entries[ignoreFilePath] = ignoreFileEntry{
+		modTime:  info.ModTime(),
+		patterns: patterns,
+	}
+
+	return patterns
+}
+
+func readIgnoreFile(ignoreFilePath string) ([]string, error) {
+	file, err := os.Open(ignoreFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ignoreFileCommentPrefix) {
+			continue
+		}
+
+		patterns = append(patterns, line)
+	}
+
+	return patterns, scanner.Err()
+}
+
+// isPathAllowed checks whether the path should be handled according to rules of the directory entry
+// and ignore files placed in directories between the entry's root and the path.
+func (s *Server) isPathAllowed(dir directories.Entry, path string, isDir bool) bool {
+	if filepath.Base(path) == ignoreFilename {
+		return false
+	}
+
+	if !dir.Allows(path, isDir) {
+		return false
+	}
+
+	rootPath := filepath.Clean(dir.RootPath())
+	for dirPath := filepath.Dir(filepath.Clean(path)); ; dirPath = filepath.Dir(dirPath) {
+		relPath, err := filepath.Rel(dirPath, path)
+		if err == nil && directories.MatchesAny(s.ignoreFiles.Patterns(dirPath), filepath.ToSlash(relPath)) {
+			return false
+		}
+
+		if dirPath == rootPath || dirPath == filepath.Dir(dirPath) {
+			break
+		}
+	}
+
+	return true
+}
@@ -25,12 +25,13 @@ const (
 )
 
 type PlaylistFile struct {
-	CurrentEntryIdx            int               `json:"CurrentEntryIdx"`
-	DirectoryContentsAsEntries bool              `json:"DirectoryContentsAsEntries"`
-	Entries                    []playlists.Entry `json:"Entries"`
-	MpvWebApiPlaylist          bool              `json:"MpvWebApiPlaylist"`
-	Name                       string            `json:"Name"`
-	Description                string            `json:"Description"`
+	CurrentEntryIdx            int                   `json:"CurrentEntryIdx"`
+	DirectoryContentsAsEntries bool                  `json:"DirectoryContentsAsEntries"`
+	Entries                    []playlists.Entry     `json:"Entries"`
+	MpvWebApiPlaylist          bool                  `json:"MpvWebApiPlaylist"`
+	Name                       string                `json:"Name"`
+	Description                string                `json:"Description"`
+	Query                      *playlists.SmartQuery `json:"Query,omitempty"`
 }
 
 // LoadPlaylist instructs mpv to add entries of a playlist to the mpv internal playlist.
@@ -118,6 +119,7 @@ func (s *Server) handlePlaylistFile(path string) (*playlists.Playlist, error) {
 		Name:                       playlistFile.Name,
 		Origin:                     playlists.ExternalOrigin,
 		Path:                       path,
+		Query:                      playlistFile.Query,
 	}
 
 	playlist := playlists.NewPlaylist(playlistCfg)
@@ -146,6 +148,13 @@ func (s *Server) readPlaylistFile(path string) (PlaylistFile, error) {
 		return Playlist, ErrJSONFileNotAPlaylistFile
 	}
 
+	if Playlist.Query != nil {
+		err = Playlist.Query.Validate()
+		if err != nil {
+			return Playlist, fmt.Errorf("invalid query of a smart playlist: %w", err)
+		}
+	}
+
 	return Playlist, nil
 }
 
@@ -200,6 +209,7 @@ func (s *Server) savePlaylist(uuid string) error {
 		MpvWebApiPlaylist:          true,
 		Name:                       playlist.Name(),
 		Description:                playlist.Description(),
+		Query:                      playlist.Query(),
 	}
 
 	filePayload, err := json.Marshal(playlistFile)
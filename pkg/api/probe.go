@@ -3,8 +3,9 @@ package api
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 
-	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
 )
 
@@ -16,7 +17,7 @@ var (
 func (s *Server) probeFile(path string) (media_files.Entry, error) {
 	s.outLog.Printf("probing file %s\n", path)
 
-	probeResult := probe.File(path)
+	probeResult := s.prober.File(path)
 	if probeResult.Err != nil {
 		return media_files.Entry{}, fmt.Errorf("error while probing '%s' file: %s", path, probeResult.Err)
 	}
@@ -26,7 +27,12 @@ func (s *Server) probeFile(path string) (media_files.Entry, error) {
 	}
 
 	mediaFile := media_files.MapProbeResultToMediaFile(probeResult)
+	sidecarDir, err := sidecar.ReadDirectory(filepath.Dir(path))
+	if err == nil {
+		mediaFile = mediaFile.WithMetadata(sidecarDir.MediaFile(path))
+	}
+
 	s.outLog.Printf("finished probing file %s\n", path)
 
-	return mediaFile, nil
+	return s.withUserMetadata(mediaFile), nil
 }
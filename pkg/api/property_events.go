@@ -184,5 +184,7 @@ func (s *Server) handlePlaybackTimeEvent(res mpv.ObservePropertyResponse) error
 	}
 
 	s.statesRepository.Playback().SetPlaybackTime(currentTimeNum)
+	s.markWatchedWhenFinishing(currentTimeNum)
+
 	return nil
 }
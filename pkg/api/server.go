@@ -8,14 +8,20 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path"
 	"slices"
 	"strings"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/sarpt/mpv-web-api/pkg/duplicates"
 	"github.com/sarpt/mpv-web-api/pkg/mpv"
+	"github.com/sarpt/mpv-web-api/pkg/probe"
 	"github.com/sarpt/mpv-web-api/pkg/state"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
+	"github.com/sarpt/mpv-web-api/pkg/thumbnails"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
 )
 
 const (
@@ -27,29 +33,45 @@ type observePropertyHandler = func(res mpv.ObservePropertyResponse) error
 
 // Server is used to serve API and hold state accessible to the API.
 type Server struct {
-	address               string
-	appDir                string
-	cacheDir              string
-	clearCache            bool
-	stopServing           chan string
-	errLog                *log.Logger
-	fsWatcher             *fsnotify.Watcher
-	mpvManager            *mpv.Manager
-	outLog                *log.Logger
-	statesRepository      state.Repository
-	pathMappings          []PathMapping
-	playlistFilesPrefixes []string
-	pluginServers         map[string]PluginServer
-	useCache              bool
+	address                 string
+	appDir                  string
+	cacheDir                string
+	clearCache              bool
+	stopServing             chan string
+	duplicates              *duplicates.Finder
+	errLog                  *log.Logger
+	fsEventsDebouncer       *keyedDebouncer
+	fsPoller                *fsPoller
+	fsWatcher               *fsnotify.Watcher
+	ignoreFiles             *ignoreFiles
+	mpvManager              *mpv.Manager
+	outLog                  *log.Logger
+	statesRepository        state.Repository
+	pathMappings            []PathMapping
+	playlistFilesPrefixes   []string
+	pluginServers           map[string]PluginServer
+	prober                  probe.Prober
+	smartPlaylistsDebouncer *keyedDebouncer
+	thumbnails              *thumbnails.Generator
+	useCache                bool
+	userMetadata            *user_metadata.Store
 }
 
 type PluginApi interface {
 	AddRootDirectories(directories []directories.Entry)
+	LoadAlbum(id string, append bool) error
+	MediaFileDuplicates() ([]duplicates.Group, int)
+	SetUserMetadata(path string, update user_metadata.Update) (user_metadata.Metadata, error)
+	CancelMediaFileTrickplay(uuid string) (bool, error)
 	ChangeChaptersOrder(chapters []int64, force bool) error
 	TakeDirectory(path string) (directories.Entry, error)
 	LoadPlaylist(uuid string, append bool) error
 	LoadFile(filePath string, append bool) error
 	LoadFileByUuid(uuid string, append bool) error
+	MediaFileChapterThumbnail(ctx context.Context, uuid string, chapterIdx int, width int) (string, error)
+	MediaFileThumbnail(ctx context.Context, uuid string, width int) (string, error)
+	MediaFileTrickplay(uuid string) (thumbnails.TrickplayIndex, error)
+	MediaFileTrickplaySheet(uuid string, sheet int) (string, error)
 	ChangeFullscreen(fullscreen bool) error
 	ChangeAudio(audioId string) error
 	ChangeChapter(idx int64) error
@@ -83,14 +105,18 @@ type Config struct {
 	CacheDir                string
 	ClearCache              bool
 	ErrWriter               io.Writer
+	FsPollInterval          time.Duration
 	MpvSocketPath           string
 	PathMappings            []PathMapping
 	PlaylistFilesPrefixes   []string
 	OutWriter               io.Writer
+	Prober                  probe.Prober
 	SocketConnectionTimeout time.Duration
 	StartMpvInstance        bool
 	StatesRepository        state.Repository
 	PluginServers           map[string]PluginServer
+	ThumbnailsDir           string
+	ThumbnailWorkers        int
 	UseCache                bool
 }
 
@@ -102,34 +128,59 @@ func NewServer(cfg Config) (*Server, error) {
 	if cfg.ErrWriter == nil {
 		cfg.ErrWriter = os.Stderr
 	}
+	if cfg.Prober == nil {
+		cfg.Prober = probe.NewFfprobeProber()
+	}
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize filesystem watcher: %w", err)
 	}
 
+	userMetadata, err := user_metadata.Load(path.Join(cfg.AppDir, userMetadataFilename))
+	if err != nil {
+		return nil, fmt.Errorf("could not load user metadata: %w", err)
+	}
+
+	logsStorage := cfg.StatesRepository.Logs()
+	outWriter, errWriter := logsStorage.TeeWriters(logs.ApiSource, cfg.OutWriter, cfg.ErrWriter)
+	managerOutWriter, managerErrWriter := logsStorage.TeeWriters(logs.MpvManagerSource, cfg.OutWriter, cfg.ErrWriter)
+	dispatcherOutWriter, dispatcherErrWriter := logsStorage.TeeWriters(logs.DispatcherSource, cfg.OutWriter, cfg.ErrWriter)
+
 	mpvManagerCfg := mpv.ManagerConfig{
-		ErrWriter:               cfg.ErrWriter,
+		DispatcherErrWriter:     dispatcherErrWriter,
+		DispatcherOutWriter:     dispatcherOutWriter,
+		ErrWriter:               managerErrWriter,
 		MpvSocketPath:           cfg.MpvSocketPath,
-		OutWriter:               cfg.OutWriter,
+		OutWriter:               managerOutWriter,
 		SocketConnectionTimeout: cfg.SocketConnectionTimeout,
 		StartMpvInstance:        cfg.StartMpvInstance,
 	}
 
 	server := &Server{
-		address:               cfg.Address,
-		appDir:                cfg.AppDir,
-		cacheDir:              cfg.CacheDir,
-		clearCache:            cfg.ClearCache,
-		errLog:                log.New(cfg.ErrWriter, logPrefix, log.LstdFlags),
-		fsWatcher:             watcher,
-		mpvManager:            mpv.NewManager(mpvManagerCfg),
-		outLog:                log.New(cfg.OutWriter, logPrefix, log.LstdFlags),
-		statesRepository:      cfg.StatesRepository,
-		pathMappings:          cfg.PathMappings,
-		playlistFilesPrefixes: cfg.PlaylistFilesPrefixes,
-		pluginServers:         cfg.PluginServers,
-		useCache:              cfg.UseCache,
+		address:                 cfg.Address,
+		appDir:                  cfg.AppDir,
+		cacheDir:                cfg.CacheDir,
+		clearCache:              cfg.ClearCache,
+		errLog:                  log.New(errWriter, logPrefix, log.LstdFlags),
+		fsEventsDebouncer:       newKeyedDebouncer(fsWriteSettleDuration),
+		fsPoller:                newFsPoller(cfg.FsPollInterval),
+		fsWatcher:               watcher,
+		ignoreFiles:             newIgnoreFiles(),
+		mpvManager:              mpv.NewManager(mpvManagerCfg),
+		outLog:                  log.New(outWriter, logPrefix, log.LstdFlags),
+		statesRepository:        cfg.StatesRepository,
+		pathMappings:            cfg.PathMappings,
+		playlistFilesPrefixes:   cfg.PlaylistFilesPrefixes,
+		pluginServers:           cfg.PluginServers,
+		prober:                  cfg.Prober,
+		smartPlaylistsDebouncer: newKeyedDebouncer(smartPlaylistsSettleDuration),
+		thumbnails: thumbnails.NewGenerator(thumbnails.Config{
+			Dir:     cfg.ThumbnailsDir,
+			Workers: cfg.ThumbnailWorkers,
+		}),
+		useCache:     cfg.UseCache,
+		userMetadata: userMetadata,
 	}
 
 	defaultPlaylistUUID, err := server.createTempPlaylist()
@@ -139,6 +190,10 @@ func NewServer(cfg Config) (*Server, error) {
 
 	server.statesRepository.Playback().SelectPlaylist(defaultPlaylistUUID)
 
+	server.duplicates = duplicates.NewFinder(server.handleDuplicatesFingerprintError)
+	server.watchForDuplicates()
+	server.watchForSmartPlaylists()
+
 	return server, nil
 }
 
@@ -157,6 +212,7 @@ func (s *Server) init() error {
 	}
 
 	s.watchForFsChanges()
+	s.watchForFsChangesByPolling()
 
 	return nil
 }
@@ -239,6 +295,9 @@ func (s *Server) teardown(serv *http.Server) {
 		serv.Shutdown()
 	}
 
+	s.thumbnails.Close()
+	s.duplicates.Close()
+
 	err = serv.Shutdown(context.Background())
 	if err != nil {
 		s.errLog.Printf("http server closed with an error: %s\n", err)
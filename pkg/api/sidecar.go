@@ -0,0 +1,68 @@
+package api
+
+import (
+	"path/filepath"
+
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+)
+
+// readDirectoryMetadata returns metadata of the directory read from it's sidecar files.
+// When the directory cannot be read, empty metadata is returned.
+func (s *Server) readDirectoryMetadata(path string) sidecar.Metadata {
+	sidecarDir, err := sidecar.ReadDirectory(path)
+	if err != nil {
+		s.errLog.Printf("could not read sidecar files of directory '%s': %s\n", path, err)
+
+		return sidecar.Metadata{}
+	}
+
+	return sidecarDir.Metadata()
+}
+
+// scheduleSidecarMetadataRefresh delays refreshing of metadata in the directory of the sidecar file
+// until the directory stops being modified, since NFO files and artwork are usually written in batches.
+func (s *Server) scheduleSidecarMetadataRefresh(sidecarPath string) {
+	s.fsEventsDebouncer.Schedule(filepath.Dir(sidecarPath), func(dirPath string) {
+		err := s.refreshSidecarMetadata(dirPath)
+		if err != nil {
+			s.errLog.Printf("could not refresh sidecar metadata in '%s' due to an error: %s\n", dirPath, err)
+		}
+	})
+}
+
+// refreshSidecarMetadata reads sidecar files of the directory again and updates metadata
+// of the directory and media files directly inside it. Entries are modified under the lock of the storages,
+// so changes done in the meantime (eg. user metadata) are not overwritten.
+func (s *Server) refreshSidecarMetadata(dirPath string) error {
+	sidecarDir, err := sidecar.ReadDirectory(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, mediaFile := range s.statesRepository.MediaFiles().ByParent(dirPath) {
+		if filepath.Dir(mediaFile.Path()) != filepath.Clean(dirPath) {
+			continue
+		}
+
+		_, err := s.statesRepository.MediaFiles().Modify(mediaFile.Path(), func(mediaFile media_files.Entry) (media_files.Entry, error) {
+			return mediaFile.WithMetadata(sidecarDir.MediaFile(mediaFile.Path())), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !s.statesRepository.Directories().Exists(dirPath) {
+		return nil // media files from not handled directories (eg. non recursive root) do not have directory entry
+	}
+
+	_, err = s.statesRepository.Directories().Modify(dirPath, func(dir directories.Entry) (directories.Entry, error) {
+		dir.Metadata = sidecarDir.Metadata()
+
+		return dir, nil
+	})
+
+	return err
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playlists"
+)
+
+const (
+	// smartPlaylistsSettleDuration delays re-evaluation of smart playlists, since media files are usually added in batches.
+	smartPlaylistsSettleDuration = 500 * time.Millisecond
+)
+
+// watchForSmartPlaylists schedules re-evaluation of smart playlists whenever they are added or served media files change.
+func (s *Server) watchForSmartPlaylists() {
+	s.statesRepository.Playlists().Subscribe(func(change playlists.Change) {
+		if change.ChangeVariant == playlists.PlaylistsAdded && change.Playlist.Query() != nil {
+			s.scheduleSmartPlaylistRefresh(change.Playlist.UUID())
+		}
+	}, func(err error) {})
+
+	s.statesRepository.MediaFiles().Subscribe(func(change media_files.Change) {
+		for uuid, playlist := range s.statesRepository.Playlists().All() {
+			if playlist.Query() != nil {
+				s.scheduleSmartPlaylistRefresh(uuid)
+			}
+		}
+	}, func(err error) {})
+}
+
+func (s *Server) scheduleSmartPlaylistRefresh(uuid string) {
+	s.smartPlaylistsDebouncer.Schedule(uuid, func(uuid string) {
+		err := s.refreshSmartPlaylist(uuid)
+		if err != nil {
+			s.errLog.Printf("could not refresh smart playlist with uuid '%s' due to an error: %s\n", uuid, err)
+		}
+	})
+}
+
+// refreshSmartPlaylist sets entries of the smart playlist to media files matching it's query, if they differ from current ones.
+func (s *Server) refreshSmartPlaylist(uuid string) error {
+	playlist, err := s.statesRepository.Playlists().ByUUID(uuid)
+	if err != nil {
+		return err
+	}
+
+	query := playlist.Query()
+	if query == nil {
+		return nil
+	}
+
+	page, err := s.statesRepository.MediaFiles().Query(query.Query, "", 0)
+	if err != nil {
+		return err
+	}
+
+	entries := query.SelectEntries(playlist.All(), page.Items)
+	if !playlist.EntriesDiffer(entries) {
+		return nil
+	}
+
+	return s.statesRepository.Playlists().SetPlaylistEntries(uuid, entries)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/thumbnails"
+)
+
+var (
+	// ErrChapterNotFound occurs when media file does not have a chapter with requested index.
+	ErrChapterNotFound = errors.New("chapter not found")
+)
+
+// MediaFileThumbnail returns path to the JPEG thumbnail of the media file with provided uuid.
+// Thumbnail is generated when it's requested for the first time, which blocks until generation finishes
+// or the context is done.
+func (s *Server) MediaFileThumbnail(ctx context.Context, uuid string, width int) (string, error) {
+	mediaFile, err := s.statesRepository.MediaFiles().ByUuid(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	thumbnail, err := s.thumbnails.Thumbnail(ctx, thumbnailSource(mediaFile), width)
+	if err != nil {
+		return "", fmt.Errorf("could not create thumbnail for '%s': %w", mediaFile.Path(), err)
+	}
+
+	return thumbnail, nil
+}
+
+// MediaFileChapterThumbnail returns path to the JPEG thumbnail of the chapter start.
+// Chapters are indexed from 0, in the same order as in the media file (and the same as the one used for changing chapters).
+func (s *Server) MediaFileChapterThumbnail(ctx context.Context, uuid string, chapterIdx int, width int) (string, error) {
+	mediaFile, err := s.statesRepository.MediaFiles().ByUuid(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	chapters := mediaFile.Chapters()
+	if chapterIdx < 0 || chapterIdx >= len(chapters) {
+		return "", ErrChapterNotFound
+	}
+
+	thumbnail, err := s.thumbnails.ChapterThumbnail(ctx, thumbnailSource(mediaFile), chapterIdx, chapters[chapterIdx].StartTime, width)
+	if err != nil {
+		return "", fmt.Errorf("could not create thumbnail for chapter %d of '%s': %w", chapterIdx, mediaFile.Path(), err)
+	}
+
+	return thumbnail, nil
+}
+
+// MediaFileTrickplay returns index of seek bar preview sprite sheets of the media file with provided uuid.
+// When sheets are not generated yet, their generation is started and thumbnails.ErrTrickplayPending is returned.
+func (s *Server) MediaFileTrickplay(uuid string) (thumbnails.TrickplayIndex, error) {
+	mediaFile, err := s.statesRepository.MediaFiles().ByUuid(uuid)
+	if err != nil {
+		return thumbnails.TrickplayIndex{}, err
+	}
+
+	return s.thumbnails.Trickplay(thumbnailSource(mediaFile))
+}
+
+// MediaFileTrickplaySheet returns path to the JPEG sprite sheet of the media file with provided uuid.
+func (s *Server) MediaFileTrickplaySheet(uuid string, sheet int) (string, error) {
+	mediaFile, err := s.statesRepository.MediaFiles().ByUuid(uuid)
+	if err != nil {
+		return "", err
+	}
+
+	return s.thumbnails.TrickplaySheet(thumbnailSource(mediaFile), sheet)
+}
+
+// CancelMediaFileTrickplay stops generation of sprite sheets for the media file with provided uuid.
+// Returns false when generation is not in progress.
+func (s *Server) CancelMediaFileTrickplay(uuid string) (bool, error) {
+	mediaFile, err := s.statesRepository.MediaFiles().ByUuid(uuid)
+	if err != nil {
+		return false, err
+	}
+
+	return s.thumbnails.CancelTrickplay(thumbnailSource(mediaFile))
+}
+
+func thumbnailSource(mediaFile media_files.Entry) thumbnails.Source {
+	return thumbnails.NewSource(mediaFile.Path(), mediaFile.Duration(), mediaFile.VideoStreams())
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+const (
+	userMetadataFilename string = "user_metadata.json"
+
+	// watchedPosition is a fraction of the media duration after which media file is considered watched.
+	// End credits are rarely watched till the end.
+	watchedPosition = 0.9
+)
+
+var (
+	// ErrUserMetadataTargetNotFound occurs when user metadata is set for a path which is neither a served media file nor a directory.
+	ErrUserMetadataTargetNotFound = errors.New("path does not point to a served media file or directory")
+)
+
+// SetUserMetadata changes metadata set by users on a media file or directory under the path,
+// returning the resulting metadata. Changes are persisted and broadcast as updates of the media file or directory.
+// The update is merged with the current metadata under the lock of the state, so concurrent updates
+// (eg. rating set by a client while the media file is marked as watched) are not lost.
+// Metadata is persisted after the state is unlocked, so readers of the state do not wait for the disk.
+func (s *Server) SetUserMetadata(path string, update user_metadata.Update) (user_metadata.Metadata, error) {
+	if s.statesRepository.MediaFiles().Exists(path) {
+		mediaFile, err := s.statesRepository.MediaFiles().Modify(path, func(mediaFile media_files.Entry) (media_files.Entry, error) {
+			metadata, err := s.mergeUserMetadata(path, mediaFile.UserMetadata(), update)
+			if err != nil {
+				return mediaFile, err
+			}
+
+			return mediaFile.WithUserMetadata(metadata), nil
+		})
+		if err != nil {
+			return mediaFile.UserMetadata(), err
+		}
+
+		return mediaFile.UserMetadata(), s.userMetadata.Save()
+	}
+
+	if s.statesRepository.Directories().Exists(path) {
+		dir, err := s.statesRepository.Directories().Modify(path, func(dir directories.Entry) (directories.Entry, error) {
+			metadata, err := s.mergeUserMetadata(directories.EnsureDirectoryPath(path), dir.UserMetadata, update)
+			if err != nil {
+				return dir, err
+			}
+
+			dir.UserMetadata = metadata
+
+			return dir, nil
+		})
+		if err != nil {
+			return dir.UserMetadata, err
+		}
+
+		return dir.UserMetadata, s.userMetadata.Save()
+	}
+
+	return user_metadata.Metadata{}, ErrUserMetadataTargetNotFound
+}
+
+// mergeUserMetadata applies the update to the current metadata and puts the result in the store, without persisting it.
+func (s *Server) mergeUserMetadata(key string, current user_metadata.Metadata, update user_metadata.Update) (user_metadata.Metadata, error) {
+	metadata, err := current.Apply(update)
+	if err != nil {
+		return current, err
+	}
+
+	s.userMetadata.Put(key, metadata)
+
+	return metadata, nil
+}
+
+// withUserMetadata returns a copy of the media file with metadata set by users before, eg. in previous runs of the server.
+func (s *Server) withUserMetadata(mediaFile media_files.Entry) media_files.Entry {
+	return mediaFile.WithUserMetadata(s.userMetadata.Get(mediaFile.Path()))
+}
+
+// markWatchedWhenFinishing marks currently played media file as watched, once the playback gets close to it's end.
+func (s *Server) markWatchedWhenFinishing(playbackTime float64) {
+	path := s.statesRepository.Playback().MediaFilePath()
+	mediaFile, err := s.statesRepository.MediaFiles().ByPath(path)
+	if err != nil || mediaFile.UserMetadata().Watched || mediaFile.Duration() <= 0 {
+		return
+	}
+
+	if playbackTime < mediaFile.Duration()*watchedPosition {
+		return
+	}
+
+	watched := true
+	_, err = s.SetUserMetadata(path, user_metadata.Update{Watched: &watched})
+	if err != nil {
+		s.errLog.Printf("could not mark '%s' as watched: %s\n", path, err)
+	}
+}
@@ -0,0 +1,221 @@
+package duplicates
+
+import (
+	"cmp"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Group holds paths of files sharing the same fingerprint.
+type Group struct {
+	Hash  string   `json:"Hash"`
+	Paths []string `json:"Paths"`
+	Size  int64    `json:"Size"`
+}
+
+type fingerprintedFile struct {
+	fingerprint Fingerprint
+	modTime     time.Time
+}
+
+// Finder fingerprints files in the background and groups the ones with the same contents.
+type Finder struct {
+	closed bool
+	// current is a path of the file being fingerprinted by the worker at the moment.
+	current        string
+	currentRemoved bool
+	errCb          func(path string, err error)
+	files          map[string]fingerprintedFile
+	lock           *sync.Mutex
+	pending        []string
+	wake           chan struct{}
+}
+
+// NewFinder returns a finder with a worker waiting for files to fingerprint.
+// ErrCb is called from the worker when a file cannot be fingerprinted, and can be nil.
+func NewFinder(errCb func(path string, err error)) *Finder {
+	f := &Finder{
+		errCb: errCb,
+		files: map[string]fingerprintedFile{},
+		lock:  &sync.Mutex{},
+		wake:  make(chan struct{}, 1),
+	}
+	go f.work()
+
+	return f
+}
+
+// Add schedules fingerprinting of the file. Files that were already fingerprinted are
+// fingerprinted again only when they were modified in the meantime. Add never blocks.
+func (f *Finder) Add(path string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	f.pending = append(f.pending, path)
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove forgets the file, so it's no longer reported as a duplicate.
+func (f *Finder) Remove(path string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.files, path)
+	if path == f.current {
+		f.currentRemoved = true
+	}
+
+	f.pending = slices.DeleteFunc(f.pending, func(pendingPath string) bool {
+		return pendingPath == path
+	})
+}
+
+// Groups returns groups of at least two files with the same fingerprint,
+// ordered by size of the files, the largest (and worth cleaning up the most) first.
+func (f *Finder) Groups() []Group {
+	f.lock.Lock()
+	groups := map[Fingerprint]*Group{}
+	for path, file := range f.files {
+		group, ok := groups[file.fingerprint]
+		if !ok {
+			group = &Group{
+				Hash: file.fingerprint.Hash,
+				Size: file.fingerprint.Size,
+			}
+			groups[file.fingerprint] = group
+		}
+
+		group.Paths = append(group.Paths, path)
+	}
+	f.lock.Unlock()
+
+	result := []Group{}
+	for _, group := range groups {
+		if len(group.Paths) < 2 {
+			continue
+		}
+
+		slices.Sort(group.Paths)
+		result = append(result, *group)
+	}
+
+	slices.SortFunc(result, func(a, b Group) int {
+		if bySize := cmp.Compare(b.Size, a.Size); bySize != 0 {
+			return bySize
+		}
+
+		return cmp.Compare(a.Hash, b.Hash)
+	})
+
+	return result
+}
+
+// Pending returns number of files waiting to be fingerprinted, including the one being fingerprinted at the moment.
+func (f *Finder) Pending() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.current != "" {
+		return len(f.pending) + 1
+	}
+
+	return len(f.pending)
+}
+
+// Close stops the worker. Files added after closing are ignored.
+func (f *Finder) Close() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed {
+		return
+	}
+
+	f.closed = true
+	f.pending = nil
+	close(f.wake)
+}
+
+func (f *Finder) work() {
+	for range f.wake {
+		for {
+			path, ok := f.next()
+			if !ok {
+				break
+			}
+
+			f.fingerprint(path)
+		}
+	}
+}
+
+func (f *Finder) next() (string, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.current = ""
+	f.currentRemoved = false
+	if len(f.pending) == 0 {
+		return "", false
+	}
+
+	path := f.pending[0]
+	f.pending = f.pending[1:]
+	f.current = path
+
+	return path, true
+}
+
+func (f *Finder) fingerprint(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		f.fail(path, err)
+
+		return
+	}
+
+	f.lock.Lock()
+	file, ok := f.files[path]
+	f.lock.Unlock()
+	if ok && file.modTime.Equal(info.ModTime()) && file.fingerprint.Size == info.Size() {
+		return
+	}
+
+	fingerprint, err := Compute(path)
+	if err != nil {
+		f.fail(path, err)
+
+		return
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.closed || f.currentRemoved {
+		return // removed while being fingerprinted
+	}
+
+	f.files[path] = fingerprintedFile{
+		fingerprint: fingerprint,
+		modTime:     info.ModTime(),
+	}
+}
+
+func (f *Finder) fail(path string, err error) {
+	f.lock.Lock()
+	delete(f.files, path)
+	f.lock.Unlock()
+
+	if f.errCb != nil {
+		f.errCb(path, err)
+	}
+}
@@ -0,0 +1,98 @@
+package duplicates_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/duplicates"
+)
+
+func writeFile(t *testing.T, dir string, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatalf("Could not write test file: %s", err)
+	}
+
+	return path
+}
+
+func waitForFingerprints(t *testing.T, uut *duplicates.Finder) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for uut.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Files were not fingerprinted in time")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFinderGroupsFilesWithSameContents(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	contents := make([]byte, 1<<20)
+	for idx := range contents {
+		contents[idx] = byte(idx % 251)
+	}
+
+	modified := append([]byte{}, contents...)
+	modified[len(modified)/2] ^= 0xFF
+
+	original := writeFile(t, dir, "original.mkv", contents)
+	copied := writeFile(t, dir, "copy.mkv", contents)
+	writeFile(t, dir, "modified.mkv", modified)
+	writeFile(t, dir, "other.mkv", []byte("other"))
+
+	uut := duplicates.NewFinder(nil)
+	defer uut.Close()
+
+	// when
+	for _, name := range []string{"original.mkv", "copy.mkv", "modified.mkv", "other.mkv"} {
+		uut.Add(filepath.Join(dir, name))
+	}
+	waitForFingerprints(t, uut)
+	groups := uut.Groups()
+
+	// then
+	if len(groups) != 1 {
+		t.Fatalf("Expected exactly one group of duplicates, got %+v", groups)
+	}
+
+	if len(groups[0].Paths) != 2 || groups[0].Paths[0] != copied || groups[0].Paths[1] != original {
+		t.Errorf("Unexpected paths in the group: %v", groups[0].Paths)
+	}
+
+	if groups[0].Size != int64(len(contents)) {
+		t.Errorf("Expected group size %d to equal %d", groups[0].Size, len(contents))
+	}
+}
+
+func TestFinderForgetsRemovedFiles(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	first := writeFile(t, dir, "first.mkv", []byte("contents"))
+	second := writeFile(t, dir, "second.mkv", []byte("contents"))
+
+	uut := duplicates.NewFinder(nil)
+	defer uut.Close()
+
+	uut.Add(first)
+	uut.Add(second)
+	waitForFingerprints(t, uut)
+
+	// when
+	uut.Remove(second)
+	groups := uut.Groups()
+
+	// then
+	if len(groups) != 0 {
+		t.Errorf("Expected no groups after removal, got %+v", groups)
+	}
+}
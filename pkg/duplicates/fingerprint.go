@@ -0,0 +1,70 @@
+package duplicates
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"os"
+)
+
+const (
+	// sampleSize is a size of every part of the file that is hashed.
+	// Hashing only the beginning, middle and end of the file is enough to tell apart
+	// media files of the same size, without reading gigabytes of data.
+	sampleSize = 64 << 10
+)
+
+// Fingerprint identifies contents of the file by it's size and hash of it's parts.
+// Files with the same fingerprint are considered to be copies of each other.
+type Fingerprint struct {
+	Hash string
+	Size int64
+}
+
+// Compute returns fingerprint of the file under the path.
+func Compute(path string) (Fingerprint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	size := info.Size()
+	hash := sha256.New()
+	hash.Write(binary.BigEndian.AppendUint64(nil, uint64(size)))
+
+	buf := make([]byte, sampleSize)
+	for _, offset := range sampleOffsets(size) {
+		n, err := file.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			return Fingerprint{}, err
+		}
+
+		hash.Write(buf[:n])
+	}
+
+	return Fingerprint{
+		Hash: hex.EncodeToString(hash.Sum(nil)),
+		Size: size,
+	}, nil
+}
+
+// sampleOffsets returns offsets of the hashed parts. Small files are hashed whole.
+func sampleOffsets(size int64) []int64 {
+	if size <= 3*sampleSize {
+		var offsets []int64
+		for offset := int64(0); offset < size; offset += sampleSize {
+			offsets = append(offsets, offset)
+		}
+
+		return offsets
+	}
+
+	return []int64{0, size/2 - sampleSize/2, size - sampleSize}
+}
@@ -21,7 +21,12 @@ const (
 	managerLogPrefix = "mpv.Manager#"
 )
 
+// ManagerConfig controls behaviour of the mpv manager.
+// DispatcherErrWriter and DispatcherOutWriter are outputs of the command dispatcher logs.
+// When not provided, the dispatcher logs to the same outputs as the manager.
 type ManagerConfig struct {
+	DispatcherErrWriter     io.Writer
+	DispatcherOutWriter     io.Writer
 	MpvSocketPath           string
 	ErrWriter               io.Writer
 	OutWriter               io.Writer
@@ -52,6 +57,12 @@ func NewManager(cfg ManagerConfig) *Manager {
 		socketPath:        cfg.MpvSocketPath,
 		outWriter:         outLog.Writer(),
 	}
+	if cfg.DispatcherErrWriter != nil {
+		cdCfg.errWriter = cfg.DispatcherErrWriter
+	}
+	if cfg.DispatcherOutWriter != nil {
+		cdCfg.outWriter = cfg.DispatcherOutWriter
+	}
 
 	return &Manager{
 		cd:               newCommandDispatcher(cdCfg),
@@ -0,0 +1,155 @@
+// Package naming extracts structured metadata from names of media files following common naming schemes.
+package naming
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultAnimeSeason is used for absolute episode numbering, which does not specify seasons.
+	defaultAnimeSeason = 1
+)
+
+var (
+	animePattern   = regexp.MustCompile(`^\[[^\]]+\]\s*(.+?)\s+-\s+(\d{1,4})(?:v\d)?(?:\s|\[|\(|$)`)
+	episodePattern = regexp.MustCompile(`(?i)^(.*?)(?:^|[\s._-]+)S(\d{1,2})[\s._-]?E(\d{1,4})`)
+	crossPattern   = regexp.MustCompile(`(?i)^(.*?)[\s._-]+(\d{1,2})x(\d{2,3})\b`)
+	yearPattern    = regexp.MustCompile(`[\s._(\[]((?:19|20)\d{2})(?:[\s._)\]]|$)`)
+	qualityPattern = regexp.MustCompile(`(?i)(?:^|[\s._\-\[(])(2160p|1080p|1080i|720p|576p|480p|4k|uhd)(?:[\s._\-\])]|$)`)
+	bracketPattern = regexp.MustCompile(`[\[(][^\])]*[\])]`)
+
+	separatorsReplacer = strings.NewReplacer(".", " ", "_", " ")
+)
+
+var qualityAliases = map[string]string{
+	"4k":  "2160p",
+	"uhd": "2160p",
+}
+
+// Info holds metadata deduced from the name of the media file.
+// Zero values mean that the information could not be deduced.
+type Info struct {
+	Episode int    `json:"Episode"`
+	Quality string `json:"Quality"`
+	Season  int    `json:"Season"`
+	Series  string `json:"Series"`
+	Year    int    `json:"Year"`
+}
+
+// IsEpisode checks whether the name describes an episode of a series.
+func (i Info) IsEpisode() bool {
+	return i.Series != "" && i.Episode > 0
+}
+
+// Parse extracts metadata from the file name (without directories and extension) of the path.
+// Supported naming schemes include "Show.S02E05.Title.1080p", "Show 2x05", "Movie (2019)" and "[Group] Show - 12".
+func Parse(path string) Info {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	info := Info{
+		Quality: parseQuality(name),
+	}
+
+	if match := animePattern.FindStringSubmatch(name); match != nil {
+		info.Series = cleanSeries(match[1])
+		info.Season = defaultAnimeSeason
+		info.Episode, _ = strconv.Atoi(match[2])
+
+		return info
+	}
+
+	for _, pattern := range []*regexp.Regexp{episodePattern, crossPattern} {
+		match := pattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		info.Series, info.Year = splitYear(match[1])
+		info.Season, _ = strconv.Atoi(match[2])
+		info.Episode, _ = strconv.Atoi(match[3])
+
+		return info
+	}
+
+	if match := findYear(name); match != nil {
+		info.Year, _ = strconv.Atoi(name[match[2]:match[3]])
+	}
+
+	return info
+}
+
+func parseQuality(name string) string {
+	match := qualityPattern.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+
+	quality := strings.ToLower(match[1])
+	if alias, ok := qualityAliases[quality]; ok {
+		return alias
+	}
+
+	return quality
+}
+
+// splitYear separates the year at the end of the series name, eg. "Doctor.Who.2005".
+func splitYear(series string) (string, int) {
+	match := findYear(series)
+	if match == nil || strings.TrimSpace(series[match[1]:]) != "" {
+		return cleanSeries(series), 0
+	}
+
+	year, _ := strconv.Atoi(series[match[2]:match[3]])
+
+	return cleanSeries(series[:match[0]]), year
+}
+
+// findYear returns submatch indexes of the year in the name, or nil when there is none.
+// Years in parentheses or brackets are preferred, otherwise the last one is used, since titles
+// may contain year-shaped numbers themselves (eg. "Blade Runner 2049 (2017)").
+// Numbers at the start of the name are considered a part of the title (eg. "2001 A Space Odyssey").
+func findYear(name string) []int {
+	var year []int
+	for offset := 0; offset < len(name); {
+		match := yearPattern.FindStringSubmatchIndex(name[offset:])
+		if match == nil {
+			break
+		}
+
+		for idx := range match {
+			match[idx] += offset
+		}
+		// matches share separators, so the search continues right after the year digits
+		offset = match[3]
+		if match[0] == 0 {
+			continue
+		}
+
+		if isBracketed(name, match) {
+			return match
+		}
+
+		year = match
+	}
+
+	return year
+}
+
+func isBracketed(name string, match []int) bool {
+	opening, closing := name[match[2]-1], byte(0)
+	if match[3] < len(name) {
+		closing = name[match[3]]
+	}
+
+	return (opening == '(' && closing == ')') || (opening == '[' && closing == ']')
+}
+
+func cleanSeries(series string) string {
+	series = bracketPattern.ReplaceAllString(series, "")
+	series = separatorsReplacer.Replace(series)
+
+	return strings.Join(strings.Fields(strings.Trim(series, " -")), " ")
+}
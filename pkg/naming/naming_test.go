@@ -0,0 +1,37 @@
+package naming_test
+
+import (
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/naming"
+)
+
+func TestParse(t *testing.T) {
+	testCases := map[string]naming.Info{
+		"/media/Show.Name.S02E05.Episode.Title.1080p.WEB-DL.mkv": {Series: "Show Name", Season: 2, Episode: 5, Quality: "1080p"},
+		"/media/show_name_s1e12_720p.mp4":                        {Series: "show name", Season: 1, Episode: 12, Quality: "720p"},
+		"/media/Doctor.Who.2005.S10E01.mkv":                      {Series: "Doctor Who", Season: 10, Episode: 1, Year: 2005},
+		"/media/Show Name - 3x07 - Title.avi":                    {Series: "Show Name", Season: 3, Episode: 7},
+		"/media/Movie Title (2019).mkv":                          {Year: 2019},
+		"/media/Movie.Title.2019.2160p.UHD.BluRay.mkv":           {Year: 2019, Quality: "2160p"},
+		"/media/Blade Runner 2049 (2017).mkv":                    {Year: 2017},
+		"/media/Blade.Runner.2049.2017.1080p.mkv":                {Year: 2017, Quality: "1080p"},
+		"/media/Show 1999 (2020) S01E02.mkv":                     {Series: "Show 1999", Season: 1, Episode: 2, Year: 2020},
+		"/media/[Group] Anime Show - 12 [1080p].mkv":             {Series: "Anime Show", Season: 1, Episode: 12, Quality: "1080p"},
+		"/media/[Group] Anime Show - 03v2 (720p).mkv":            {Series: "Anime Show", Season: 1, Episode: 3, Quality: "720p"},
+		"/media/2001 A Space Odyssey.mkv":                        {},
+		"/media/home video.mp4":                                  {},
+	}
+
+	for path, expected := range testCases {
+		t.Run(path, func(t *testing.T) {
+			// when
+			result := naming.Parse(path)
+
+			// then
+			if result != expected {
+				t.Errorf("Expected %+v, got %+v", expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	videoCodecType    = "video"
+	audioCodecType    = "audio"
+	subtitleCodecType = "subtitle"
+
+	ffprobeName     = "ffprobe"
+	hideBannerArg   = "-hide_banner"
+	logLevelArg     = "-loglevel"
+	quietLogLevel   = "quiet"
+	showErrorArg    = "-show_error"
+	showStreamsArg  = "-show_streams"
+	showFormatArg   = "-show_format"
+	showChaptersArg = "-show_chapters"
+	outputArg       = "-of"
+	jsonOutput      = "json"
+
+	pqColorTransfer  = "smpte2084"
+	hlgColorTransfer = "arib-std-b67"
+
+	doviSideDataType      = "DOVI configuration record"
+	hdr10PlusSideDataType = "HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"
+	frameRateSeparator    = "/"
+)
+
+// FfprobeProber checks information about the file using "ffprobe" ran as a separate process.
+type FfprobeProber struct{}
+
+// NewFfprobeProber returns prober which relies on "ffprobe" being available in PATH.
+func NewFfprobeProber() *FfprobeProber {
+	return &FfprobeProber{}
+}
+
+// Name satisfies Prober.
+func (p *FfprobeProber) Name() string {
+	return FfprobeProberName
+}
+
+// File checks information about the file format, it's streams and whether it can be used as a media file
+// using "ffprobe" ran as a separate process. Satisfies Prober.
+func (p *FfprobeProber) File(filepath string) Result {
+	result := newResult(filepath)
+
+	ffprobeResult, err := probeWithFfprobe(filepath)
+	if err != nil {
+		result.Err = fmt.Errorf("probing error: %w", err)
+
+		return result
+	}
+
+	if ffprobeResult.ProbeError.Code != 0 {
+		result.Err = fmt.Errorf("probing error from ffprobe: %d (%s)", ffprobeResult.ProbeError.Code, ffprobeResult.ProbeError.Message)
+
+		return result
+	}
+
+	parsedDuration, err := strconv.ParseFloat(ffprobeResult.Format.Duration, 64)
+	if err != nil {
+		result.Err = fmt.Errorf("could not parse duration: %w", err)
+
+		return result
+	}
+
+	formatTags := containerTags(ffprobeResult)
+	result.Format = Format{
+		BitRate:  parseOptionalInt(ffprobeResult.Format.BitRate),
+		Name:     ffprobeResult.Format.Name,
+		LongName: ffprobeResult.Format.LongName,
+		Duration: parsedDuration,
+		Size:     parseOptionalInt(ffprobeResult.Format.Size),
+		Tags:     mapFormatTags(formatTags),
+		Title:    formatTags.Title,
+	}
+
+	for _, chapter := range ffprobeResult.Chapters {
+		startTime, err := strconv.ParseFloat(chapter.StartTime, 64)
+		if err != nil {
+			result.Err = fmt.Errorf("could not parse start time: %w", err)
+
+			return result
+		}
+
+		endTime, err := strconv.ParseFloat(chapter.EndTime, 64)
+		if err != nil {
+			result.Err = fmt.Errorf("could not parse end time: %w", err)
+
+			return result
+		}
+
+		result.Chapters = append(result.Chapters, Chapter{
+			ChapterID: strconv.FormatInt(int64(len(result.Chapters)+1), 10),
+			EndTime:   endTime,
+			StartTime: startTime,
+			Title:     chapter.Tags.Title,
+		})
+	}
+
+	for _, str := range ffprobeResult.Streams {
+		switch str.CodecType {
+		case videoCodecType:
+			result.VideoStreams = append(result.VideoStreams, VideoStream{
+				BitRate:        parseOptionalInt(str.BitRate),
+				Codec:          str.CodecName,
+				ColorPrimaries: str.ColorPrimaries,
+				ColorSpace:     str.ColorSpace,
+				ColorTransfer:  str.ColorTransfer,
+				Disposition:    mapDisposition(str.Disposition),
+				FrameRate:      parseFrameRate(str),
+				HDR:            detectHDR(str),
+				Language:       str.Tags.Language,
+				PixelFormat:    str.PixFmt,
+				Profile:        str.Profile,
+				Width:          str.Width,
+				Height:         str.Height,
+				Title:          str.Tags.Title,
+			})
+		case audioCodecType:
+			result.AudioStreams = append(result.AudioStreams, AudioStream{
+				AudioID:       strconv.FormatInt(int64(len(result.AudioStreams)+1), 10),
+				BitRate:       parseOptionalInt(str.BitRate),
+				ChannelLayout: str.ChannelLayout,
+				Codec:         str.CodecName,
+				Disposition:   mapDisposition(str.Disposition),
+				Language:      str.Tags.Language,
+				Channels:      str.Channels,
+				Profile:       str.Profile,
+				SampleRate:    int(parseOptionalInt(str.SampleRate)),
+				Title:         str.Tags.Title,
+			})
+		case subtitleCodecType:
+			result.SubtitleStreams = append(result.SubtitleStreams, SubtitleStream{
+				Codec:       str.CodecName,
+				Disposition: mapDisposition(str.Disposition),
+				SubtitleID:  strconv.FormatInt(int64(len(result.SubtitleStreams)+1), 10),
+				Language:    str.Tags.Language,
+				Title:       str.Tags.Title,
+			})
+		}
+	}
+
+	return result
+}
+
+func probeWithFfprobe(filepath string) (ffprobeResult, error) {
+	result := ffprobeResult{}
+
+	ffprobeargs := []string{
+		hideBannerArg,
+		logLevelArg, quietLogLevel,
+		showErrorArg,
+		showStreamsArg,
+		showChaptersArg,
+		showFormatArg,
+		outputArg, jsonOutput,
+		filepath,
+	}
+	cmd := exec.Command(ffprobeName, ffprobeargs...)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal(output, &result)
+	return result, err
+}
+
+// parseOptionalInt parses numeric values which ffprobe reports as strings.
+// Values that are missing or not available ("N/A") result in 0.
+func parseOptionalInt(value string) int64 {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return parsed
+}
+
+// parseFrameRate converts rational frame rate (eg. "24000/1001") to a decimal number.
+// Average frame rate is preferred over the base one since it's not affected by timestamps precision.
+func parseFrameRate(str stream) float64 {
+	for _, frameRate := range []string{str.AvgFrameRate, str.RFrameRate} {
+		numerator, denominator, found := strings.Cut(frameRate, frameRateSeparator)
+		if !found {
+			continue
+		}
+
+		num, err := strconv.ParseFloat(numerator, 64)
+		if err != nil {
+			continue
+		}
+
+		den, err := strconv.ParseFloat(denominator, 64)
+		if err != nil || den == 0 || num == 0 {
+			continue
+		}
+
+		return num / den
+	}
+
+	return 0
+}
+
+// detectHDR returns HDR format of the video stream, or an empty string for SDR streams.
+func detectHDR(str stream) string {
+	for _, sideData := range str.SideDataList {
+		switch sideData.SideDataType {
+		case doviSideDataType:
+			return DolbyVision
+		case hdr10PlusSideDataType:
+			return HDR10Plus
+		}
+	}
+
+	switch str.ColorTransfer {
+	case pqColorTransfer:
+		return HDR10
+	case hlgColorTransfer:
+		return HLG
+	}
+
+	return ""
+}
+
+func mapDisposition(disp disposition) Disposition {
+	return Disposition{
+		AttachedPic:     disp.AttachedPic != 0,
+		Comment:         disp.Comment != 0,
+		Default:         disp.Default != 0,
+		Forced:          disp.Forced != 0,
+		HearingImpaired: disp.HearingImpaired != 0,
+		VisualImpaired:  disp.VisualImpaired != 0,
+	}
+}
+
+func mapFormatTags(formatTags tags) Tags {
+	episode := formatTags.EpisodeSort
+	if episode == "" {
+		episode = formatTags.EpisodeID
+	}
+
+	return Tags{
+		Album:       formatTags.Album,
+		AlbumArtist: formatTags.AlbumArtist,
+		Artist:      formatTags.Artist,
+		Date:        formatTags.Date,
+		Disc:        formatTags.Disc,
+		Episode:     episode,
+		Genre:       formatTags.Genre,
+		Season:      formatTags.SeasonNumber,
+		Show:        formatTags.Show,
+		Track:       formatTags.Track,
+	}
+}
+
+// containerTags returns tags describing the whole file. Ogg and FLAC files keep Vorbis comments
+// in the audio stream instead of the container, so for files without a video stream (cover art aside)
+// the fields missing in the container are taken from the first audio stream.
+func containerTags(result ffprobeResult) tags {
+	formatTags := result.Format.Tags
+
+	var audioTags *tags
+	for idx, str := range result.Streams {
+		switch {
+		case str.CodecType == videoCodecType && str.Disposition.AttachedPic == 0:
+			return formatTags
+		case str.CodecType == audioCodecType && audioTags == nil:
+			audioTags = &result.Streams[idx].Tags
+		}
+	}
+
+	if audioTags == nil {
+		return formatTags
+	}
+
+	fields := []struct {
+		container *string
+		stream    string
+	}{
+		{&formatTags.Album, audioTags.Album},
+		{&formatTags.AlbumArtist, audioTags.AlbumArtist},
+		{&formatTags.Artist, audioTags.Artist},
+		{&formatTags.Date, audioTags.Date},
+		{&formatTags.Disc, audioTags.Disc},
+		{&formatTags.EpisodeID, audioTags.EpisodeID},
+		{&formatTags.EpisodeSort, audioTags.EpisodeSort},
+		{&formatTags.Genre, audioTags.Genre},
+		{&formatTags.SeasonNumber, audioTags.SeasonNumber},
+		{&formatTags.Show, audioTags.Show},
+		{&formatTags.Title, audioTags.Title},
+		{&formatTags.Track, audioTags.Track},
+	}
+	for _, field := range fields {
+		if *field.container == "" {
+			*field.container = field.stream
+		}
+	}
+
+	return formatTags
+}
@@ -6,25 +6,67 @@ type chapter struct {
 	Tags      tags   `json:"tags"`
 }
 
+// tags are matched case-insensitively, since containers differ in tag names casing (eg. "ARTIST" in Matroska, "artist" in MP4).
+// ffmpeg normalizes ID3v2 frames and Vorbis comments to these names (eg. "TPE2" and "ALBUMARTIST" to "album_artist").
 type tags struct {
-	Language string `json:"language"`
-	Title    string `json:"title"`
-	Filename string `json:"filename"`
+	Album        string `json:"album"`
+	AlbumArtist  string `json:"album_artist"`
+	Artist       string `json:"artist"`
+	Date         string `json:"date"`
+	Disc         string `json:"disc"`
+	EpisodeID    string `json:"episode_id"`
+	EpisodeSort  string `json:"episode_sort"`
+	Filename     string `json:"filename"`
+	Genre        string `json:"genre"`
+	Language     string `json:"language"`
+	SeasonNumber string `json:"season_number"`
+	Show         string `json:"show"`
+	Title        string `json:"title"`
+	Track        string `json:"track"`
+}
+
+type disposition struct {
+	Default         int `json:"default"`
+	Forced          int `json:"forced"`
+	HearingImpaired int `json:"hearing_impaired"`
+	VisualImpaired  int `json:"visual_impaired"`
+	Comment         int `json:"comment"`
+	AttachedPic     int `json:"attached_pic"`
+}
+
+type sideData struct {
+	SideDataType string `json:"side_data_type"`
 }
 
 type stream struct {
-	Index     int    `json:"index"`
-	CodecType string `json:"codec_type"`
-	Tags      tags   `json:"tags"`
-	Width     int    `json:"width"`
-	Height    int    `json:"height"`
-	Channels  int    `json:"channels"`
+	Index          int         `json:"index"`
+	CodecName      string      `json:"codec_name"`
+	CodecLongName  string      `json:"codec_long_name"`
+	CodecType      string      `json:"codec_type"`
+	Profile        string      `json:"profile"`
+	BitRate        string      `json:"bit_rate"`
+	AvgFrameRate   string      `json:"avg_frame_rate"`
+	RFrameRate     string      `json:"r_frame_rate"`
+	PixFmt         string      `json:"pix_fmt"`
+	ColorTransfer  string      `json:"color_transfer"`
+	ColorPrimaries string      `json:"color_primaries"`
+	ColorSpace     string      `json:"color_space"`
+	SampleRate     string      `json:"sample_rate"`
+	ChannelLayout  string      `json:"channel_layout"`
+	Disposition    disposition `json:"disposition"`
+	SideDataList   []sideData  `json:"side_data_list"`
+	Tags           tags        `json:"tags"`
+	Width          int         `json:"width"`
+	Height         int         `json:"height"`
+	Channels       int         `json:"channels"`
 }
 
 type format struct {
 	Name     string `json:"format_name"`
 	LongName string `json:"format_long_name"`
 	Duration string `json:"duration"`
+	Size     string `json:"size"`
+	BitRate  string `json:"bit_rate"`
 	Tags     tags   `json:"tags"`
 }
 
@@ -0,0 +1,180 @@
+package probe
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseFrameRate(t *testing.T) {
+	testCases := map[string]struct {
+		avgFrameRate string
+		rFrameRate   string
+		expected     float64
+	}{
+		"average frame rate":                {"24000/1001", "24/1", 24000.0 / 1001.0},
+		"zero average falls back to real":   {"0/0", "25/1", 25},
+		"zero numerator falls back to real": {"0/1", "30/1", 30},
+		"both rates zero":                   {"0/0", "0/0", 0},
+		"malformed average":                 {"24", "50/2", 25},
+		"non-numeric rates":                 {"a/b", "c/d", 0},
+		"missing rates":                     {"", "", 0},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			str := stream{AvgFrameRate: testCase.avgFrameRate, RFrameRate: testCase.rFrameRate}
+
+			// when
+			result := parseFrameRate(str)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected frame rate %v, got %v", testCase.expected, result)
+			}
+		})
+	}
+}
+
+func TestDetectHDR(t *testing.T) {
+	testCases := map[string]struct {
+		str      stream
+		expected string
+	}{
+		"dolby vision side data": {
+			stream{ColorTransfer: pqColorTransfer, SideDataList: []sideData{{SideDataType: doviSideDataType}}},
+			DolbyVision,
+		},
+		"hdr10+ side data": {
+			stream{ColorTransfer: pqColorTransfer, SideDataList: []sideData{{SideDataType: hdr10PlusSideDataType}}},
+			HDR10Plus,
+		},
+		"pq color transfer": {
+			stream{ColorTransfer: pqColorTransfer},
+			HDR10,
+		},
+		"hlg color transfer": {
+			stream{ColorTransfer: hlgColorTransfer},
+			HLG,
+		},
+		"unknown side data with pq color transfer": {
+			stream{ColorTransfer: pqColorTransfer, SideDataList: []sideData{{SideDataType: "Display Matrix"}}},
+			HDR10,
+		},
+		"sdr color transfer": {
+			stream{ColorTransfer: "bt709", ColorPrimaries: "bt709", ColorSpace: "bt709"},
+			"",
+		},
+		"missing color fields": {
+			stream{},
+			"",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result := detectHDR(testCase.str)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected HDR %q, got %q", testCase.expected, result)
+			}
+		})
+	}
+}
+
+func TestMapDisposition(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected Disposition
+	}{
+		"all known dispositions": {
+			`{"default":1,"forced":1,"hearing_impaired":1,"visual_impaired":1,"comment":1,"attached_pic":1}`,
+			Disposition{AttachedPic: true, Comment: true, Default: true, Forced: true, HearingImpaired: true, VisualImpaired: true},
+		},
+		"no dispositions set": {
+			`{"default":0,"forced":0,"attached_pic":0}`,
+			Disposition{},
+		},
+		"non-one values are set": {
+			`{"default":2,"forced":-1}`,
+			Disposition{Default: true, Forced: true},
+		},
+		"unknown dispositions are ignored": {
+			`{"dub":1,"karaoke":1,"clean_effects":1,"default":1}`,
+			Disposition{Default: true},
+		},
+		"missing disposition": {
+			`{}`,
+			Disposition{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			var disp disposition
+			if err := json.Unmarshal([]byte(testCase.input), &disp); err != nil {
+				t.Fatalf("Could not unmarshal disposition: %s", err)
+			}
+
+			// when
+			result := mapDisposition(disp)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected disposition %+v, got %+v", testCase.expected, result)
+			}
+		})
+	}
+}
+
+func TestContainerTags(t *testing.T) {
+	audioTrack := stream{CodecType: audioCodecType, Tags: tags{Title: "Surround 5.1", Artist: "Stream Artist", Language: "eng"}}
+	video := stream{CodecType: videoCodecType}
+	coverArt := stream{CodecType: videoCodecType, Disposition: disposition{AttachedPic: 1}}
+
+	testCases := map[string]struct {
+		result   ffprobeResult
+		expected tags
+	}{
+		"video container with only date and show": {
+			ffprobeResult{
+				Format:  format{Tags: tags{Date: "2019", Show: "Show", SeasonNumber: "2"}},
+				Streams: []stream{video, audioTrack},
+			},
+			tags{Date: "2019", Show: "Show", SeasonNumber: "2"},
+		},
+		"audio without container tags": {
+			ffprobeResult{
+				Format:  format{},
+				Streams: []stream{audioTrack},
+			},
+			tags{Title: "Surround 5.1", Artist: "Stream Artist"},
+		},
+		"audio with cover art and partial container tags": {
+			ffprobeResult{
+				Format:  format{Tags: tags{Date: "1999", Title: "Container Title"}},
+				Streams: []stream{coverArt, audioTrack},
+			},
+			tags{Date: "1999", Title: "Container Title", Artist: "Stream Artist"},
+		},
+		"no streams": {
+			ffprobeResult{Format: format{Tags: tags{Title: "Container Title"}}},
+			tags{Title: "Container Title"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result := containerTags(testCase.result)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected tags %+v, got %+v", testCase.expected, result)
+			}
+		})
+	}
+}
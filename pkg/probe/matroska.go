@@ -0,0 +1,812 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	matroskaFormatName     = "matroska,webm"
+	matroskaFormatLongName = "Matroska / WebM"
+
+	// maxMatroskaMasterSize limits the size of master elements read into memory (info, tracks, chapters, tags).
+	maxMatroskaMasterSize = 16 << 20
+
+	defaultTimestampScale = 1000000
+	defaultLanguage       = "eng"
+	defaultTagTargetType  = 50
+	discTagTargetType     = 40
+	trackTagTargetType    = 30
+	nanosecondsInSecond   = 1e9
+
+	ebmlUnknownSize = math.MaxUint64
+
+	ebmlHeaderID  = 0x1A45DFA3
+	ebmlDocTypeID = 0x4282
+	ebmlVoidID    = 0xEC
+	ebmlCRC32ID   = 0xBF
+
+	mkvSegmentID  = 0x18538067
+	mkvInfoID     = 0x1549A966
+	mkvTracksID   = 0x1654AE6B
+	mkvChaptersID = 0x1043A770
+	mkvTagsID     = 0x1254C367
+
+	mkvTimestampScaleID = 0x2AD7B1
+	mkvDurationID       = 0x4489
+	mkvTitleID          = 0x7BA9
+
+	mkvTrackEntryID              = 0xAE
+	mkvTrackTypeID               = 0x83
+	mkvCodecID                   = 0x86
+	mkvNameID                    = 0x536E
+	mkvLanguageID                = 0x22B59C
+	mkvLanguageBCP47ID           = 0x22B59D
+	mkvFlagDefaultID             = 0x88
+	mkvFlagForcedID              = 0x55AA
+	mkvFlagHearingImpairedID     = 0x55AB
+	mkvFlagVisualImpairedID      = 0x55AC
+	mkvFlagCommentaryID          = 0x55AF
+	mkvDefaultDurationID         = 0x23E383
+	mkvBlockAdditionMappingID    = 0x41E4
+	mkvBlockAddIDTypeID          = 0x41E7
+	mkvVideoID                   = 0xE0
+	mkvPixelWidthID              = 0xB0
+	mkvPixelHeightID             = 0xBA
+	mkvColourID                  = 0x55B0
+	mkvMatrixCoefficientsID      = 0x55B1
+	mkvTransferCharacteristicsID = 0x55BA
+	mkvPrimariesID               = 0x55BB
+	mkvAudioID                   = 0xE1
+	mkvSamplingFrequencyID       = 0xB5
+	mkvChannelsID                = 0x9F
+
+	mkvEditionEntryID      = 0x45B9
+	mkvChapterAtomID       = 0xB6
+	mkvChapterTimeStartID  = 0x91
+	mkvChapterTimeEndID    = 0x92
+	mkvChapterFlagHiddenID = 0x98
+	mkvChapterDisplayID    = 0x80
+	mkvChapStringID        = 0x85
+
+	mkvTagID             = 0x7373
+	mkvTargetsID         = 0x63C0
+	mkvTargetTypeValueID = 0x68CA
+	mkvTagTrackUIDID     = 0x63C5
+	mkvSimpleTagID       = 0x67C8
+	mkvTagNameID         = 0x45A3
+	mkvTagStringID       = 0x4487
+
+	mkvVideoTrackType    = 1
+	mkvAudioTrackType    = 2
+	mkvSubtitleTrackType = 17
+
+	// Dolby Vision configuration block addition types ('dvcC' and 'dvvC').
+	mkvDolbyVisionConfigType  = 0x64766343
+	mkvDolbyVisionVConfigType = 0x64767643
+)
+
+var (
+	errNotMatroska       = errors.New("file is not a Matroska/WebM file")
+	errEbmlVintMalformed = errors.New("malformed EBML variable size integer")
+	errEbmlElementSize   = errors.New("EBML element exceeds its parent")
+	errMatroskaNoTracks  = errors.New("no tracks found in Matroska file")
+
+	// matroskaCodecs maps Matroska codec IDs to codec names reported by ffprobe.
+	// Codec IDs not present in the map are reported as lowercase codec ID.
+	matroskaCodecs = map[string]string{
+		"V_MPEG4/ISO/AVC":  "h264",
+		"V_MPEGH/ISO/HEVC": "hevc",
+		"V_AV1":            "av1",
+		"V_VP8":            "vp8",
+		"V_VP9":            "vp9",
+		"V_MPEG2":          "mpeg2video",
+		"V_MPEG4/ISO/ASP":  "mpeg4",
+		"V_THEORA":         "theora",
+		"A_AAC":            "aac",
+		"A_AC3":            "ac3",
+		"A_EAC3":           "eac3",
+		"A_DTS":            "dts",
+		"A_TRUEHD":         "truehd",
+		"A_FLAC":           "flac",
+		"A_OPUS":           "opus",
+		"A_VORBIS":         "vorbis",
+		"A_MPEG/L3":        "mp3",
+		"A_MPEG/L2":        "mp2",
+		"A_PCM/INT/LIT":    "pcm_s16le",
+		"S_TEXT/UTF8":      "subrip",
+		"S_TEXT/ASS":       "ass",
+		"S_TEXT/SSA":       "ass",
+		"S_TEXT/WEBVTT":    "webvtt",
+		"S_HDMV/PGS":       "hdmv_pgs_subtitle",
+		"S_VOBSUB":         "dvd_subtitle",
+		"S_DVBSUB":         "dvb_subtitle",
+	}
+)
+
+type ebmlElement struct {
+	id   uint64
+	data []byte
+}
+
+func probeMatroska(r io.ReadSeeker, fileSize int64, result *Result) error {
+	id, size, _, err := readEbmlElementHeader(r)
+	if err != nil || id != ebmlHeaderID || size > maxMatroskaMasterSize {
+		return errNotMatroska
+	}
+
+	header := make([]byte, size)
+	_, err = io.ReadFull(r, header)
+	if err != nil {
+		return fmt.Errorf("could not read EBML header: %w", err)
+	}
+
+	docType := "matroska"
+	headerChildren, err := parseEbmlChildren(header)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range headerChildren {
+		if child.id == ebmlDocTypeID {
+			docType = ebmlString(child.data)
+		}
+	}
+
+	if docType != "matroska" && docType != "webm" {
+		return fmt.Errorf("%w: unknown doc type '%s'", errNotMatroska, docType)
+	}
+
+	segmentEnd, err := findMatroskaSegment(r, fileSize)
+	if err != nil {
+		return err
+	}
+
+	result.Format.Name = matroskaFormatName
+	result.Format.LongName = matroskaFormatLongName
+
+	var (
+		timestampScale uint64 = defaultTimestampScale
+		duration       float64
+		tracksFound    bool
+		chapters       []ebmlElement
+		tagsElements   [][]ebmlElement
+	)
+
+segmentChildren:
+	for {
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil || offset >= segmentEnd {
+			break
+		}
+
+		id, size, _, err := readEbmlElementHeader(r)
+		if err != nil || size == ebmlUnknownSize {
+			// unknown sized elements (eg. live streamed clusters) cannot be skipped without parsing them
+			break
+		}
+
+		switch id {
+		case mkvInfoID, mkvTracksID, mkvChaptersID, mkvTagsID:
+			if size > maxMatroskaMasterSize {
+				return fmt.Errorf("matroska element %x too large: %d bytes", id, size)
+			}
+
+			data := make([]byte, size)
+			_, err = io.ReadFull(r, data)
+			if err != nil {
+				break segmentChildren
+			}
+
+			children, err := parseEbmlChildren(data)
+			if err != nil {
+				return err
+			}
+
+			switch id {
+			case mkvInfoID:
+				timestampScale, duration = parseMatroskaInfo(children, result)
+			case mkvTracksID:
+				tracksFound = true
+				parseMatroskaTracks(children, result)
+			case mkvChaptersID:
+				chapters = children
+			case mkvTagsID:
+				tagsElements = append(tagsElements, children)
+			}
+		default:
+			_, err = r.Seek(int64(size), io.SeekCurrent)
+			if err != nil {
+				break segmentChildren
+			}
+		}
+	}
+
+	if !tracksFound {
+		return errMatroskaNoTracks
+	}
+
+	result.Format.Duration = duration * float64(timestampScale) / nanosecondsInSecond
+	parseMatroskaChapters(chapters, result)
+	for _, tags := range tagsElements {
+		parseMatroskaTags(tags, result)
+	}
+
+	return nil
+}
+
+// findMatroskaSegment positions reader at the beginning of the segment data and returns offset of the segment end.
+func findMatroskaSegment(r io.ReadSeeker, fileSize int64) (int64, error) {
+	for {
+		id, size, _, err := readEbmlElementHeader(r)
+		if err != nil {
+			return 0, fmt.Errorf("%w: segment not found", errNotMatroska)
+		}
+
+		if id == mkvSegmentID {
+			start, err := r.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return 0, err
+			}
+
+			if size == ebmlUnknownSize || start+int64(size) > fileSize {
+				return fileSize, nil
+			}
+
+			return start + int64(size), nil
+		}
+
+		if size == ebmlUnknownSize {
+			return 0, fmt.Errorf("%w: segment not found", errNotMatroska)
+		}
+
+		_, err = r.Seek(int64(size), io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func parseMatroskaInfo(children []ebmlElement, result *Result) (uint64, float64) {
+	var timestampScale uint64 = defaultTimestampScale
+	var duration float64
+
+	for _, child := range children {
+		switch child.id {
+		case mkvTimestampScaleID:
+			timestampScale = ebmlUint(child.data)
+		case mkvDurationID:
+			duration = ebmlFloat(child.data)
+		case mkvTitleID:
+			result.Format.Title = ebmlString(child.data)
+		}
+	}
+
+	return timestampScale, duration
+}
+
+type matroskaTrack struct {
+	hdr             string
+	channels        int
+	codec           string
+	colorPrimaries  string
+	colorSpace      string
+	colorTransfer   string
+	defaultDuration uint64
+	disposition     Disposition
+	height          int
+	language        string
+	name            string
+	samplingFreq    float64
+	trackType       uint64
+	width           int
+}
+
+func parseMatroskaTracks(children []ebmlElement, result *Result) {
+	for _, child := range children {
+		if child.id != mkvTrackEntryID {
+			continue
+		}
+
+		entries, err := parseEbmlChildren(child.data)
+		if err != nil {
+			continue
+		}
+
+		track := parseMatroskaTrack(entries)
+		switch track.trackType {
+		case mkvVideoTrackType:
+			var frameRate float64
+			if track.defaultDuration > 0 {
+				frameRate = nanosecondsInSecond / float64(track.defaultDuration)
+			}
+
+			result.VideoStreams = append(result.VideoStreams, VideoStream{
+				Codec:          track.codec,
+				ColorPrimaries: track.colorPrimaries,
+				ColorSpace:     track.colorSpace,
+				ColorTransfer:  track.colorTransfer,
+				Disposition:    track.disposition,
+				FrameRate:      frameRate,
+				HDR:            track.hdr,
+				Height:         track.height,
+				Language:       track.language,
+				Title:          track.name,
+				Width:          track.width,
+			})
+		case mkvAudioTrackType:
+			result.AudioStreams = append(result.AudioStreams, AudioStream{
+				AudioID:     strconv.FormatInt(int64(len(result.AudioStreams)+1), 10),
+				Channels:    track.channels,
+				Codec:       track.codec,
+				Disposition: track.disposition,
+				Language:    track.language,
+				SampleRate:  int(track.samplingFreq),
+				Title:       track.name,
+			})
+		case mkvSubtitleTrackType:
+			result.SubtitleStreams = append(result.SubtitleStreams, SubtitleStream{
+				Codec:       track.codec,
+				Disposition: track.disposition,
+				Language:    track.language,
+				SubtitleID:  strconv.FormatInt(int64(len(result.SubtitleStreams)+1), 10),
+				Title:       track.name,
+			})
+		}
+	}
+}
+
+func parseMatroskaTrack(entries []ebmlElement) matroskaTrack {
+	track := matroskaTrack{
+		channels: 1,
+		disposition: Disposition{
+			Default: true,
+		},
+		language: defaultLanguage,
+	}
+
+	var languageBCP47 string
+	for _, entry := range entries {
+		switch entry.id {
+		case mkvTrackTypeID:
+			track.trackType = ebmlUint(entry.data)
+		case mkvCodecID:
+			track.codec = matroskaCodecName(ebmlString(entry.data))
+		case mkvNameID:
+			track.name = ebmlString(entry.data)
+		case mkvLanguageID:
+			track.language = ebmlString(entry.data)
+		case mkvLanguageBCP47ID:
+			languageBCP47 = ebmlString(entry.data)
+		case mkvFlagDefaultID:
+			track.disposition.Default = ebmlUint(entry.data) != 0
+		case mkvFlagForcedID:
+			track.disposition.Forced = ebmlUint(entry.data) != 0
+		case mkvFlagHearingImpairedID:
+			track.disposition.HearingImpaired = ebmlUint(entry.data) != 0
+		case mkvFlagVisualImpairedID:
+			track.disposition.VisualImpaired = ebmlUint(entry.data) != 0
+		case mkvFlagCommentaryID:
+			track.disposition.Comment = ebmlUint(entry.data) != 0
+		case mkvDefaultDurationID:
+			track.defaultDuration = ebmlUint(entry.data)
+		case mkvBlockAdditionMappingID:
+			if isMatroskaDolbyVisionMapping(entry.data) {
+				track.hdr = DolbyVision
+			}
+		case mkvVideoID:
+			parseMatroskaVideo(entry.data, &track)
+		case mkvAudioID:
+			parseMatroskaAudio(entry.data, &track)
+		}
+	}
+
+	if languageBCP47 != "" {
+		track.language = languageBCP47
+	}
+
+	return track
+}
+
+func parseMatroskaVideo(data []byte, track *matroskaTrack) {
+	children, err := parseEbmlChildren(data)
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		switch child.id {
+		case mkvPixelWidthID:
+			track.width = int(ebmlUint(child.data))
+		case mkvPixelHeightID:
+			track.height = int(ebmlUint(child.data))
+		case mkvColourID:
+			colour, err := parseEbmlChildren(child.data)
+			if err != nil {
+				continue
+			}
+
+			for _, colourEntry := range colour {
+				switch colourEntry.id {
+				case mkvTransferCharacteristicsID:
+					transfer := ebmlUint(colourEntry.data)
+					track.colorTransfer = colorTransferNames[transfer]
+					if track.hdr == "" {
+						track.hdr = hdrFromColorTransfer(transfer)
+					}
+				case mkvPrimariesID:
+					track.colorPrimaries = colorPrimariesNames[ebmlUint(colourEntry.data)]
+				case mkvMatrixCoefficientsID:
+					track.colorSpace = colorSpaceNames[ebmlUint(colourEntry.data)]
+				}
+			}
+		}
+	}
+}
+
+func parseMatroskaAudio(data []byte, track *matroskaTrack) {
+	children, err := parseEbmlChildren(data)
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		switch child.id {
+		case mkvSamplingFrequencyID:
+			track.samplingFreq = ebmlFloat(child.data)
+		case mkvChannelsID:
+			track.channels = int(ebmlUint(child.data))
+		}
+	}
+}
+
+func isMatroskaDolbyVisionMapping(data []byte) bool {
+	children, err := parseEbmlChildren(data)
+	if err != nil {
+		return false
+	}
+
+	for _, child := range children {
+		if child.id != mkvBlockAddIDTypeID {
+			continue
+		}
+
+		addIDType := ebmlUint(child.data)
+		return addIDType == mkvDolbyVisionConfigType || addIDType == mkvDolbyVisionVConfigType
+	}
+
+	return false
+}
+
+// parseMatroskaChapters parses chapters of the first edition.
+func parseMatroskaChapters(children []ebmlElement, result *Result) {
+	for _, child := range children {
+		if child.id != mkvEditionEntryID {
+			continue
+		}
+
+		atoms, err := parseEbmlChildren(child.data)
+		if err != nil {
+			return
+		}
+
+		for _, atom := range atoms {
+			if atom.id != mkvChapterAtomID {
+				continue
+			}
+
+			chapter, hidden := parseMatroskaChapterAtom(atom.data)
+			if hidden {
+				continue
+			}
+
+			chapter.ChapterID = strconv.FormatInt(int64(len(result.Chapters)+1), 10)
+			result.Chapters = append(result.Chapters, chapter)
+		}
+
+		break
+	}
+
+	for idx := range result.Chapters {
+		if result.Chapters[idx].EndTime != 0 {
+			continue
+		}
+
+		if idx+1 < len(result.Chapters) {
+			result.Chapters[idx].EndTime = result.Chapters[idx+1].StartTime
+		} else {
+			result.Chapters[idx].EndTime = result.Format.Duration
+		}
+	}
+}
+
+func parseMatroskaChapterAtom(data []byte) (Chapter, bool) {
+	chapter := Chapter{}
+	hidden := false
+
+	entries, err := parseEbmlChildren(data)
+	if err != nil {
+		return chapter, true
+	}
+
+	for _, entry := range entries {
+		switch entry.id {
+		case mkvChapterTimeStartID:
+			chapter.StartTime = float64(ebmlUint(entry.data)) / nanosecondsInSecond
+		case mkvChapterTimeEndID:
+			chapter.EndTime = float64(ebmlUint(entry.data)) / nanosecondsInSecond
+		case mkvChapterFlagHiddenID:
+			hidden = ebmlUint(entry.data) != 0
+		case mkvChapterDisplayID:
+			if chapter.Title != "" {
+				continue
+			}
+
+			display, err := parseEbmlChildren(entry.data)
+			if err != nil {
+				continue
+			}
+
+			for _, displayEntry := range display {
+				if displayEntry.id == mkvChapStringID {
+					chapter.Title = ebmlString(displayEntry.data)
+				}
+			}
+		}
+	}
+
+	return chapter, hidden
+}
+
+// parseMatroskaTags reads global tags (not targeting specific tracks) into format tags.
+func parseMatroskaTags(children []ebmlElement, result *Result) {
+	for _, child := range children {
+		if child.id != mkvTagID {
+			continue
+		}
+
+		entries, err := parseEbmlChildren(child.data)
+		if err != nil {
+			continue
+		}
+
+		targetType := uint64(defaultTagTargetType)
+		trackSpecific := false
+		var simpleTags []ebmlElement
+		for _, entry := range entries {
+			switch entry.id {
+			case mkvTargetsID:
+				targets, err := parseEbmlChildren(entry.data)
+				if err != nil {
+					continue
+				}
+
+				for _, target := range targets {
+					switch target.id {
+					case mkvTargetTypeValueID:
+						targetType = ebmlUint(target.data)
+					case mkvTagTrackUIDID:
+						trackSpecific = true
+					}
+				}
+			case mkvSimpleTagID:
+				simpleTags = append(simpleTags, entry)
+			}
+		}
+
+		if trackSpecific {
+			continue
+		}
+
+		for _, simpleTag := range simpleTags {
+			name, value := parseMatroskaSimpleTag(simpleTag.data)
+			applyMatroskaTag(strings.ToUpper(name), value, targetType, result)
+		}
+	}
+}
+
+func parseMatroskaSimpleTag(data []byte) (string, string) {
+	var name, value string
+
+	entries, err := parseEbmlChildren(data)
+	if err != nil {
+		return name, value
+	}
+
+	for _, entry := range entries {
+		switch entry.id {
+		case mkvTagNameID:
+			name = ebmlString(entry.data)
+		case mkvTagStringID:
+			value = ebmlString(entry.data)
+		}
+	}
+
+	return name, value
+}
+
+func applyMatroskaTag(name string, value string, targetType uint64, result *Result) {
+	tags := &result.Format.Tags
+
+	switch name {
+	case "ARTIST":
+		tags.Artist = value
+	case "ALBUM":
+		tags.Album = value
+	case "ALBUM_ARTIST", "ALBUMARTIST":
+		tags.AlbumArtist = value
+	case "GENRE":
+		tags.Genre = value
+	case "PART_NUMBER", "TRACKNUMBER":
+		switch targetType {
+		case trackTagTargetType:
+			tags.Track = value
+		case discTagTargetType:
+			tags.Disc = value
+		}
+	case "DATE", "DATE_RELEASED":
+		tags.Date = value
+	case "SHOW":
+		tags.Show = value
+	case "SEASON_NUMBER":
+		tags.Season = value
+	case "EPISODE_SORT", "EPISODE_ID":
+		tags.Episode = value
+	case "TITLE":
+		if targetType == defaultTagTargetType && result.Format.Title == "" {
+			result.Format.Title = value
+		}
+	}
+}
+
+func matroskaCodecName(codecID string) string {
+	if name, ok := matroskaCodecs[codecID]; ok {
+		return name
+	}
+
+	if strings.HasPrefix(codecID, "A_AAC") {
+		return matroskaCodecs["A_AAC"]
+	}
+
+	return strings.ToLower(codecID)
+}
+
+// readEbmlElementHeader reads element ID and data size from the reader.
+// Returned size equal to ebmlUnknownSize means that the element size is unknown.
+func readEbmlElementHeader(r io.Reader) (uint64, uint64, int, error) {
+	id, idLen, err := readEbmlVint(r, true)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	size, sizeLen, err := readEbmlVint(r, false)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return id, size, idLen + sizeLen, nil
+}
+
+func readEbmlVint(r io.Reader, keepMarker bool) (uint64, int, error) {
+	first := make([]byte, 1)
+	_, err := io.ReadFull(r, first)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	length := ebmlVintLength(first[0])
+	if length == 0 {
+		return 0, 0, errEbmlVintMalformed
+	}
+
+	buf := make([]byte, length)
+	buf[0] = first[0]
+	_, err = io.ReadFull(r, buf[1:])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	value, _ := decodeEbmlVint(buf, keepMarker)
+	return value, length, nil
+}
+
+func ebmlVintLength(first byte) int {
+	for length := 1; length <= 8; length++ {
+		if first&(0x80>>(length-1)) != 0 {
+			return length
+		}
+	}
+
+	return 0
+}
+
+// decodeEbmlVint decodes variable size integer from the beginning of data, returning value and its length.
+func decodeEbmlVint(data []byte, keepMarker bool) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+
+	length := ebmlVintLength(data[0])
+	if length == 0 || len(data) < length {
+		return 0, 0
+	}
+
+	marker := byte(0x80 >> (length - 1))
+	value := uint64(data[0])
+	allOnes := data[0]&^marker == marker-1
+	if !keepMarker {
+		value = uint64(data[0] &^ marker)
+	}
+
+	for idx := 1; idx < length; idx++ {
+		value = value<<8 | uint64(data[idx])
+		allOnes = allOnes && data[idx] == 0xFF
+	}
+
+	if !keepMarker && allOnes {
+		return ebmlUnknownSize, length
+	}
+
+	return value, length
+}
+
+func parseEbmlChildren(data []byte) ([]ebmlElement, error) {
+	var elements []ebmlElement
+
+	for offset := 0; offset < len(data); {
+		id, idLen := decodeEbmlVint(data[offset:], true)
+		if idLen == 0 {
+			return elements, errEbmlVintMalformed
+		}
+		offset += idLen
+
+		size, sizeLen := decodeEbmlVint(data[offset:], false)
+		if sizeLen == 0 {
+			return elements, errEbmlVintMalformed
+		}
+		offset += sizeLen
+
+		if size == ebmlUnknownSize || size > uint64(len(data)-offset) {
+			return elements, errEbmlElementSize
+		}
+
+		if id != ebmlVoidID && id != ebmlCRC32ID {
+			elements = append(elements, ebmlElement{
+				id:   id,
+				data: data[offset : offset+int(size)],
+			})
+		}
+		offset += int(size)
+	}
+
+	return elements, nil
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+
+	return value
+}
+
+func ebmlFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+
+	return 0
+}
+
+func ebmlString(data []byte) string {
+	return strings.TrimRight(string(data), "\x00")
+}
@@ -0,0 +1,578 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	mp4FormatName     = "mov,mp4,m4a,3gp,3g2,mj2"
+	mp4FormatLongName = "QuickTime / MOV"
+
+	// maxMp4MoovSize limits the size of "moov" box read into memory.
+	maxMp4MoovSize = 64 << 20
+
+	mp4BoxHeaderSize      = 8
+	mp4LargeBoxHeaderSize = 16
+	mp4FullBoxHeaderSize  = 4
+
+	mp4VisualSampleEntrySize = 78
+	mp4AudioSampleEntrySize  = 28
+
+	mp4UndeterminedLanguage = 0x55C4
+	mp4ChplTimescale        = 10000000
+
+	mp4NclxColourType = "nclx"
+	mp4NclcColourType = "nclc"
+)
+
+var (
+	errMp4NoMoov = errors.New("no 'moov' box found in MP4 file")
+
+	// mp4Codecs maps sample entry types to codec names reported by ffprobe.
+	// Sample entry types not present in the map are reported as is.
+	mp4Codecs = map[string]string{
+		"avc1": "h264",
+		"avc3": "h264",
+		"hvc1": "hevc",
+		"hev1": "hevc",
+		"dvh1": "hevc",
+		"dvhe": "hevc",
+		"av01": "av1",
+		"vp09": "vp9",
+		"vp08": "vp8",
+		"mp4v": "mpeg4",
+		"mp4a": "aac",
+		"ac-3": "ac3",
+		"ec-3": "eac3",
+		"Opus": "opus",
+		"fLaC": "flac",
+		"alac": "alac",
+		".mp3": "mp3",
+		"tx3g": "mov_text",
+		"wvtt": "webvtt",
+		"c608": "eia_608",
+		"stpp": "ttml",
+	}
+	mp4DolbyVisionSampleEntries = map[string]bool{
+		"dvh1": true,
+		"dvhe": true,
+		"dvav": true,
+		"dva1": true,
+	}
+)
+
+type mp4Box struct {
+	boxType string
+	data    []byte
+}
+
+func probeMp4(r io.ReadSeeker, fileSize int64, result *Result) error {
+	var moov []byte
+
+	for {
+		boxType, size, err := readMp4BoxHeader(r, fileSize)
+		if err != nil {
+			break
+		}
+
+		if boxType == "moov" {
+			if size > maxMp4MoovSize {
+				return fmt.Errorf("'moov' box too large: %d bytes", size)
+			}
+
+			moov = make([]byte, size)
+			_, err = io.ReadFull(r, moov)
+			if err != nil {
+				return fmt.Errorf("could not read 'moov' box: %w", err)
+			}
+
+			break
+		}
+
+		_, err = r.Seek(size, io.SeekCurrent)
+		if err != nil {
+			break
+		}
+	}
+
+	if moov == nil {
+		return errMp4NoMoov
+	}
+
+	result.Format.Name = mp4FormatName
+	result.Format.LongName = mp4FormatLongName
+
+	var udta []byte
+	for _, box := range parseMp4Boxes(moov) {
+		switch box.boxType {
+		case "mvhd":
+			result.Format.Duration = parseMp4MovieHeader(box.data)
+		case "trak":
+			parseMp4Track(box.data, result)
+		case "udta":
+			udta = box.data
+		case "meta":
+			parseMp4Meta(box.data, result)
+		}
+	}
+
+	if udta != nil {
+		parseMp4UserData(udta, result)
+	}
+
+	return nil
+}
+
+// readMp4BoxHeader reads box header and returns type and payload size of the box.
+func readMp4BoxHeader(r io.ReadSeeker, fileSize int64) (string, int64, error) {
+	header := make([]byte, mp4BoxHeaderSize)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return "", 0, err
+	}
+
+	boxType := string(header[4:8])
+	size := int64(binary.BigEndian.Uint32(header[0:4]))
+	switch size {
+	case 0:
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", 0, err
+		}
+
+		return boxType, fileSize - offset, nil
+	case 1:
+		largeSize := make([]byte, mp4LargeBoxHeaderSize-mp4BoxHeaderSize)
+		_, err := io.ReadFull(r, largeSize)
+		if err != nil {
+			return "", 0, err
+		}
+
+		size = int64(binary.BigEndian.Uint64(largeSize))
+		if size < mp4LargeBoxHeaderSize {
+			return "", 0, fmt.Errorf("incorrect size of '%s' box", boxType)
+		}
+
+		return boxType, size - mp4LargeBoxHeaderSize, nil
+	}
+
+	if size < mp4BoxHeaderSize {
+		return "", 0, fmt.Errorf("incorrect size of '%s' box", boxType)
+	}
+
+	return boxType, size - mp4BoxHeaderSize, nil
+}
+
+func parseMp4Boxes(data []byte) []mp4Box {
+	var boxes []mp4Box
+
+	for offset := 0; offset+mp4BoxHeaderSize <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[offset : offset+4]))
+		boxType := string(data[offset+4 : offset+8])
+		headerSize := uint64(mp4BoxHeaderSize)
+
+		switch size {
+		case 0:
+			size = uint64(len(data) - offset)
+		case 1:
+			if offset+mp4LargeBoxHeaderSize > len(data) {
+				return boxes
+			}
+
+			size = binary.BigEndian.Uint64(data[offset+8 : offset+16])
+			headerSize = mp4LargeBoxHeaderSize
+		}
+
+		if size < headerSize || size > uint64(len(data)-offset) {
+			return boxes
+		}
+
+		boxes = append(boxes, mp4Box{
+			boxType: boxType,
+			data:    data[offset+int(headerSize) : offset+int(size)],
+		})
+		offset += int(size)
+	}
+
+	return boxes
+}
+
+func findMp4Box(data []byte, boxType string) []byte {
+	for _, box := range parseMp4Boxes(data) {
+		if box.boxType == boxType {
+			return box.data
+		}
+	}
+
+	return nil
+}
+
+// parseMp4MovieHeader returns duration of the movie in seconds.
+func parseMp4MovieHeader(data []byte) float64 {
+	timescale, duration, ok := parseMp4TimescaleAndDuration(data)
+	if !ok || timescale == 0 {
+		return 0
+	}
+
+	return float64(duration) / float64(timescale)
+}
+
+func parseMp4TimescaleAndDuration(data []byte) (uint32, uint64, bool) {
+	if len(data) < 1 {
+		return 0, 0, false
+	}
+
+	if data[0] == 1 {
+		if len(data) < 32 {
+			return 0, 0, false
+		}
+
+		return binary.BigEndian.Uint32(data[20:24]), binary.BigEndian.Uint64(data[24:32]), true
+	}
+
+	if len(data) < 20 {
+		return 0, 0, false
+	}
+
+	return binary.BigEndian.Uint32(data[12:16]), uint64(binary.BigEndian.Uint32(data[16:20])), true
+}
+
+type mp4Track struct {
+	handlerType string
+	language    string
+	sampleEntry mp4Box
+	timescale   uint32
+	stts        []byte
+}
+
+func parseMp4Track(data []byte, result *Result) {
+	mdia := findMp4Box(data, "mdia")
+	if mdia == nil {
+		return
+	}
+
+	track := mp4Track{}
+	for _, box := range parseMp4Boxes(mdia) {
+		switch box.boxType {
+		case "mdhd":
+			track.timescale, _, _ = parseMp4TimescaleAndDuration(box.data)
+			track.language = parseMp4Language(box.data)
+		case "hdlr":
+			if len(box.data) >= 12 {
+				track.handlerType = string(box.data[8:12])
+			}
+		case "minf":
+			stbl := findMp4Box(box.data, "stbl")
+			if stbl == nil {
+				continue
+			}
+
+			track.stts = findMp4Box(stbl, "stts")
+			stsd := findMp4Box(stbl, "stsd")
+			if len(stsd) < mp4FullBoxHeaderSize+4 {
+				continue
+			}
+
+			entries := parseMp4Boxes(stsd[mp4FullBoxHeaderSize+4:])
+			if len(entries) > 0 {
+				track.sampleEntry = entries[0]
+			}
+		}
+	}
+
+	codec := mp4CodecName(track.sampleEntry.boxType)
+	switch track.handlerType {
+	case "vide":
+		result.VideoStreams = append(result.VideoStreams, parseMp4VisualSampleEntry(track, codec))
+	case "soun":
+		audioStream := parseMp4AudioSampleEntry(track.sampleEntry.data)
+		audioStream.AudioID = strconv.FormatInt(int64(len(result.AudioStreams)+1), 10)
+		audioStream.Codec = codec
+		audioStream.Language = track.language
+		result.AudioStreams = append(result.AudioStreams, audioStream)
+	case "sbtl", "subt", "text", "clcp":
+		result.SubtitleStreams = append(result.SubtitleStreams, SubtitleStream{
+			Codec:      codec,
+			Language:   track.language,
+			SubtitleID: strconv.FormatInt(int64(len(result.SubtitleStreams)+1), 10),
+		})
+	}
+}
+
+func parseMp4VisualSampleEntry(track mp4Track, codec string) VideoStream {
+	stream := VideoStream{
+		Codec:     codec,
+		FrameRate: parseMp4FrameRate(track.stts, track.timescale),
+		Language:  track.language,
+	}
+
+	data := track.sampleEntry.data
+	if len(data) < mp4VisualSampleEntrySize {
+		return stream
+	}
+
+	stream.Width = int(binary.BigEndian.Uint16(data[24:26]))
+	stream.Height = int(binary.BigEndian.Uint16(data[26:28]))
+
+	if mp4DolbyVisionSampleEntries[track.sampleEntry.boxType] {
+		stream.HDR = DolbyVision
+	}
+
+	for _, box := range parseMp4Boxes(data[mp4VisualSampleEntrySize:]) {
+		switch box.boxType {
+		case "dvcC", "dvvC":
+			stream.HDR = DolbyVision
+		case "colr":
+			if len(box.data) < 10 {
+				continue
+			}
+
+			colourType := string(box.data[0:4])
+			if colourType != mp4NclxColourType && colourType != mp4NclcColourType {
+				continue
+			}
+
+			primaries := uint64(binary.BigEndian.Uint16(box.data[4:6]))
+			transfer := uint64(binary.BigEndian.Uint16(box.data[6:8]))
+			matrix := uint64(binary.BigEndian.Uint16(box.data[8:10]))
+
+			stream.ColorPrimaries = colorPrimariesNames[primaries]
+			stream.ColorTransfer = colorTransferNames[transfer]
+			stream.ColorSpace = colorSpaceNames[matrix]
+			if stream.HDR == "" {
+				stream.HDR = hdrFromColorTransfer(transfer)
+			}
+		}
+	}
+
+	return stream
+}
+
+func parseMp4AudioSampleEntry(data []byte) AudioStream {
+	stream := AudioStream{}
+	if len(data) < mp4AudioSampleEntrySize {
+		return stream
+	}
+
+	stream.Channels = int(binary.BigEndian.Uint16(data[16:18]))
+	stream.SampleRate = int(binary.BigEndian.Uint32(data[24:28]) >> 16)
+
+	// QuickTime sound sample description version 2 stores sample rate and channels in extension fields
+	version := binary.BigEndian.Uint16(data[8:10])
+	if version == 2 && len(data) >= mp4AudioSampleEntrySize+16 {
+		stream.SampleRate = int(math.Float64frombits(binary.BigEndian.Uint64(data[32:40])))
+		stream.Channels = int(binary.BigEndian.Uint32(data[40:44]))
+	}
+
+	return stream
+}
+
+// parseMp4FrameRate computes average frame rate from time-to-sample table.
+func parseMp4FrameRate(stts []byte, timescale uint32) float64 {
+	if len(stts) < mp4FullBoxHeaderSize+4 || timescale == 0 {
+		return 0
+	}
+
+	entriesCount := int(binary.BigEndian.Uint32(stts[4:8]))
+	var samples, duration uint64
+	for idx := 0; idx < entriesCount; idx++ {
+		offset := 8 + idx*8
+		if offset+8 > len(stts) {
+			break
+		}
+
+		count := uint64(binary.BigEndian.Uint32(stts[offset : offset+4]))
+		delta := uint64(binary.BigEndian.Uint32(stts[offset+4 : offset+8]))
+		samples += count
+		duration += count * delta
+	}
+
+	if duration == 0 {
+		return 0
+	}
+
+	return float64(samples) * float64(timescale) / float64(duration)
+}
+
+// parseMp4Language decodes packed ISO-639-2/T language code from "mdhd" box.
+func parseMp4Language(data []byte) string {
+	offset := 20
+	if len(data) > 0 && data[0] == 1 {
+		offset = 32
+	}
+
+	if len(data) < offset+2 {
+		return ""
+	}
+
+	packed := binary.BigEndian.Uint16(data[offset : offset+2])
+	if packed == mp4UndeterminedLanguage || packed == 0 {
+		return ""
+	}
+
+	return string([]byte{
+		byte((packed>>10)&0x1F) + 0x60,
+		byte((packed>>5)&0x1F) + 0x60,
+		byte(packed&0x1F) + 0x60,
+	})
+}
+
+func parseMp4UserData(data []byte, result *Result) {
+	for _, box := range parseMp4Boxes(data) {
+		switch box.boxType {
+		case "meta":
+			parseMp4Meta(box.data, result)
+		case "chpl":
+			parseMp4NeroChapters(box.data, result)
+		}
+	}
+}
+
+// parseMp4Meta reads iTunes-style metadata items list.
+// In ISO files "meta" is a full box, while in QuickTime files it's a regular box.
+func parseMp4Meta(data []byte, result *Result) {
+	if len(data) >= 8 && string(data[4:8]) != "hdlr" {
+		data = data[mp4FullBoxHeaderSize:]
+	}
+
+	ilst := findMp4Box(data, "ilst")
+	if ilst == nil {
+		return
+	}
+
+	tags := &result.Format.Tags
+	for _, item := range parseMp4Boxes(ilst) {
+		value := parseMp4MetaItemValue(item.data)
+		switch item.boxType {
+		case "\xa9nam":
+			result.Format.Title = value
+		case "\xa9ART":
+			tags.Artist = value
+		case "aART":
+			tags.AlbumArtist = value
+		case "\xa9alb":
+			tags.Album = value
+		case "\xa9day":
+			tags.Date = value
+		case "\xa9gen":
+			tags.Genre = value
+		case "trkn":
+			tags.Track = parseMp4IndexItem(item.data)
+		case "disk":
+			tags.Disc = parseMp4IndexItem(item.data)
+		case "tvsh":
+			tags.Show = value
+		case "tvsn":
+			tags.Season = value
+		case "tves":
+			tags.Episode = value
+		}
+	}
+}
+
+func parseMp4MetaItemValue(data []byte) string {
+	value := findMp4Box(data, "data")
+	if len(value) < 8 {
+		return ""
+	}
+
+	dataType := binary.BigEndian.Uint32(value[0:4]) & 0xFFFFFF
+	payload := value[8:]
+	switch dataType {
+	case 0, 21:
+		var number uint64
+		for _, b := range payload {
+			number = number<<8 | uint64(b)
+		}
+
+		return strconv.FormatUint(number, 10)
+	}
+
+	return strings.TrimRight(string(payload), "\x00")
+}
+
+// parseMp4IndexItem reads "trkn" and "disk" items, which hold binary number and total count of tracks/discs.
+// The result uses "number/total" notation, the same as ID3v2 frames.
+func parseMp4IndexItem(data []byte) string {
+	value := findMp4Box(data, "data")
+	if len(value) < 14 {
+		return ""
+	}
+
+	payload := value[8:]
+	number := binary.BigEndian.Uint16(payload[2:4])
+	total := binary.BigEndian.Uint16(payload[4:6])
+	if number == 0 {
+		return ""
+	}
+
+	if total == 0 {
+		return strconv.FormatUint(uint64(number), 10)
+	}
+
+	return fmt.Sprintf("%d/%d", number, total)
+}
+
+// parseMp4NeroChapters reads chapters list stored in "chpl" box.
+func parseMp4NeroChapters(data []byte, result *Result) {
+	if len(data) < mp4FullBoxHeaderSize+1 {
+		return
+	}
+
+	offset := mp4FullBoxHeaderSize
+	if data[0] != 0 {
+		offset += 4
+	}
+
+	if offset >= len(data) {
+		return
+	}
+
+	chaptersCount := int(data[offset])
+	offset++
+
+	chapters := []Chapter{}
+	for idx := 0; idx < chaptersCount; idx++ {
+		if offset+9 > len(data) {
+			break
+		}
+
+		start := binary.BigEndian.Uint64(data[offset : offset+8])
+		titleLen := int(data[offset+8])
+		offset += 9
+		if offset+titleLen > len(data) {
+			break
+		}
+
+		chapters = append(chapters, Chapter{
+			ChapterID: strconv.FormatInt(int64(idx+1), 10),
+			StartTime: float64(start) / mp4ChplTimescale,
+			Title:     string(data[offset : offset+titleLen]),
+		})
+		offset += titleLen
+	}
+
+	for idx := range chapters {
+		if idx+1 < len(chapters) {
+			chapters[idx].EndTime = chapters[idx+1].StartTime
+		} else {
+			chapters[idx].EndTime = result.Format.Duration
+		}
+	}
+
+	result.Chapters = chapters
+}
+
+func mp4CodecName(sampleEntryType string) string {
+	if name, ok := mp4Codecs[sampleEntryType]; ok {
+		return name
+	}
+
+	return sampleEntryType
+}
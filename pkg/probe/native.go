@@ -0,0 +1,159 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	containerHeaderSize = 12
+)
+
+var (
+	ebmlMagic = []byte{0x1A, 0x45, 0xDF, 0xA3}
+	mp4Boxes  = [][]byte{
+		[]byte("ftyp"),
+		[]byte("moov"),
+		[]byte("mdat"),
+		[]byte("free"),
+		[]byte("wide"),
+		[]byte("skip"),
+	}
+
+	errUnsupportedContainer = errors.New("container not supported by native prober")
+
+	colorPrimariesNames = map[uint64]string{
+		1:  "bt709",
+		5:  "bt470bg",
+		6:  "smpte170m",
+		9:  "bt2020",
+		12: "smpte432",
+	}
+	colorTransferNames = map[uint64]string{
+		1:  "bt709",
+		6:  "smpte170m",
+		13: "iec61966-2-1",
+		14: "bt2020-10",
+		15: "bt2020-12",
+		16: pqColorTransfer,
+		18: hlgColorTransfer,
+	}
+	colorSpaceNames = map[uint64]string{
+		0:  "gbr",
+		1:  "bt709",
+		5:  "bt470bg",
+		6:  "smpte170m",
+		9:  "bt2020nc",
+		10: "bt2020c",
+	}
+)
+
+// NativeProber checks information about Matroska/WebM and MP4/MOV files by parsing their headers,
+// without relying on external programs. Files in other containers, and files which headers could not be parsed
+// (eg. damaged or using features not supported by the native parser), are handled by a fallback prober (if provided).
+type NativeProber struct {
+	fallback Prober
+}
+
+// NewNativeProber returns a native prober. Fallback is used for files which could not be probed natively,
+// and can be nil, in which case such files result in an error.
+func NewNativeProber(fallback Prober) *NativeProber {
+	return &NativeProber{
+		fallback: fallback,
+	}
+}
+
+// Name satisfies Prober.
+func (p *NativeProber) Name() string {
+	return NativeProberName
+}
+
+// File checks information about the file format, it's streams and whether it can be used as a media file. Satisfies Prober.
+func (p *NativeProber) File(filepath string) Result {
+	result, err := p.probe(filepath)
+	if err != nil && p.fallback != nil {
+		return p.fallback.File(filepath)
+	}
+
+	if err != nil {
+		result.Err = fmt.Errorf("probing error: %w", err)
+	}
+
+	return result
+}
+
+func (p *NativeProber) probe(filepath string) (Result, error) {
+	result := newResult(filepath)
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return result, err
+	}
+
+	header := make([]byte, containerHeaderSize)
+	_, err = io.ReadFull(file, header)
+	if err != nil {
+		return result, errUnsupportedContainer
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case bytes.HasPrefix(header, ebmlMagic):
+		err = probeMatroska(file, info.Size(), &result)
+	case isMp4Header(header):
+		err = probeMp4(file, info.Size(), &result)
+	default:
+		return result, errUnsupportedContainer
+	}
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Format.Size = info.Size()
+	if result.Format.Duration > 0 {
+		result.Format.BitRate = int64(float64(info.Size()*8) / result.Format.Duration)
+	}
+
+	return result, nil
+}
+
+func isMp4Header(header []byte) bool {
+	boxSize := binary.BigEndian.Uint32(header[0:4])
+	if boxSize != 1 && boxSize < 8 {
+		return false
+	}
+
+	for _, boxType := range mp4Boxes {
+		if bytes.Equal(header[4:8], boxType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hdrFromColorTransfer(transfer uint64) string {
+	switch colorTransferNames[transfer] {
+	case pqColorTransfer:
+		return HDR10
+	case hlgColorTransfer:
+		return HLG
+	}
+
+	return ""
+}
@@ -0,0 +1,290 @@
+package probe_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+)
+
+func ebmlElement(id []byte, children ...[]byte) []byte {
+	var payload []byte
+	for _, child := range children {
+		payload = append(payload, child...)
+	}
+
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(payload)))
+	size[0] = 0x01
+
+	out := append([]byte{}, id...)
+	out = append(out, size...)
+	return append(out, payload...)
+}
+
+func ebmlFloat(value float64) []byte {
+	out := make([]byte, 8)
+	binary.BigEndian.PutUint64(out, math.Float64bits(value))
+
+	return out
+}
+
+func mp4Box(boxType string, children ...[]byte) []byte {
+	var payload []byte
+	for _, child := range children {
+		payload = append(payload, child...)
+	}
+
+	out := make([]byte, 4)
+	binary.BigEndian.PutUint32(out, uint32(len(payload)+8))
+	out = append(out, []byte(boxType)...)
+	return append(out, payload...)
+}
+
+func be16(value uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, value)
+}
+
+func be32(value uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, value)
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, data, 0600)
+	if err != nil {
+		t.Fatalf("Could not write test file: %s", err)
+	}
+
+	return path
+}
+
+func TestNativeProber_Matroska(t *testing.T) {
+	// given
+	file := append(
+		ebmlElement([]byte{0x1A, 0x45, 0xDF, 0xA3}, ebmlElement([]byte{0x42, 0x82}, []byte("webm"))),
+		ebmlElement([]byte{0x18, 0x53, 0x80, 0x67},
+			ebmlElement([]byte{0x15, 0x49, 0xA9, 0x66},
+				ebmlElement([]byte{0x2A, 0xD7, 0xB1}, []byte{0x0F, 0x42, 0x40}),
+				ebmlElement([]byte{0x44, 0x89}, ebmlFloat(120000)),
+				ebmlElement([]byte{0x7B, 0xA9}, []byte("Test title")),
+			),
+			ebmlElement([]byte{0x16, 0x54, 0xAE, 0x6B},
+				ebmlElement([]byte{0xAE},
+					ebmlElement([]byte{0x83}, []byte{1}),
+					ebmlElement([]byte{0x86}, []byte("V_VP9")),
+					ebmlElement([]byte{0xE0},
+						ebmlElement([]byte{0xB0}, be16(1920)),
+						ebmlElement([]byte{0xBA}, be16(1080)),
+						ebmlElement([]byte{0x55, 0xB0}, ebmlElement([]byte{0x55, 0xBA}, []byte{16})),
+					),
+				),
+				ebmlElement([]byte{0xAE},
+					ebmlElement([]byte{0x83}, []byte{2}),
+					ebmlElement([]byte{0x86}, []byte("A_OPUS")),
+					ebmlElement([]byte{0x22, 0xB5, 0x9C}, []byte("pol")),
+					ebmlElement([]byte{0x88}, []byte{0}),
+					ebmlElement([]byte{0xE1},
+						ebmlElement([]byte{0xB5}, ebmlFloat(48000)),
+						ebmlElement([]byte{0x9F}, []byte{2}),
+					),
+				),
+			),
+			ebmlElement([]byte{0x1F, 0x43, 0xB6, 0x75}, []byte{0xE7, 0x81, 0x00}),
+			ebmlElement([]byte{0x10, 0x43, 0xA7, 0x70},
+				ebmlElement([]byte{0x45, 0xB9},
+					ebmlElement([]byte{0xB6},
+						ebmlElement([]byte{0x91}, []byte{0}),
+						ebmlElement([]byte{0x80}, ebmlElement([]byte{0x85}, []byte("Intro"))),
+					),
+					ebmlElement([]byte{0xB6},
+						ebmlElement([]byte{0x91}, be32(30000000)),
+					),
+				),
+			),
+		)...,
+	)
+	path := writeTestFile(t, "test.webm", file)
+	uut := probe.NewNativeProber(nil)
+
+	// when
+	result := uut.File(path)
+
+	// then
+	if result.Err != nil {
+		t.Fatalf("Unexpected error reported: %s", result.Err)
+	}
+
+	if result.Format.Duration != 120 {
+		t.Errorf("Expected duration %f to equal 120", result.Format.Duration)
+	}
+
+	if result.Format.Title != "Test title" {
+		t.Errorf("Expected title '%s' to equal 'Test title'", result.Format.Title)
+	}
+
+	if len(result.VideoStreams) != 1 || len(result.AudioStreams) != 1 {
+		t.Fatalf("Expected one video and one audio stream, got %d and %d", len(result.VideoStreams), len(result.AudioStreams))
+	}
+
+	video := result.VideoStreams[0]
+	if video.Codec != "vp9" || video.Width != 1920 || video.Height != 1080 || video.HDR != probe.HDR10 {
+		t.Errorf("Unexpected video stream: %+v", video)
+	}
+
+	audio := result.AudioStreams[0]
+	if audio.Codec != "opus" || audio.Language != "pol" || audio.Channels != 2 || audio.SampleRate != 48000 || audio.Disposition.Default {
+		t.Errorf("Unexpected audio stream: %+v", audio)
+	}
+
+	if len(result.Chapters) != 2 {
+		t.Fatalf("Expected 2 chapters, got %d", len(result.Chapters))
+	}
+
+	if result.Chapters[0].Title != "Intro" || result.Chapters[0].EndTime != 0.03 || result.Chapters[1].EndTime != 120 {
+		t.Errorf("Unexpected chapters: %+v", result.Chapters)
+	}
+}
+
+func TestNativeProber_Mp4(t *testing.T) {
+	// given
+	visualSampleEntry := make([]byte, 78)
+	binary.BigEndian.PutUint16(visualSampleEntry[24:26], 1280)
+	binary.BigEndian.PutUint16(visualSampleEntry[26:28], 720)
+
+	mdhd := make([]byte, 24)
+	binary.BigEndian.PutUint32(mdhd[12:16], 24000)
+	binary.BigEndian.PutUint16(mdhd[20:22], (('e'-0x60)<<10)|(('n'-0x60)<<5)|('g'-0x60))
+
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:20], 60000)
+
+	hdlr := make([]byte, 25)
+	copy(hdlr[8:12], "vide")
+
+	chpl := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2}
+	chpl = append(chpl, binary.BigEndian.AppendUint64(nil, 0)...)
+	chpl = append(chpl, 5)
+	chpl = append(chpl, []byte("First")...)
+	chpl = append(chpl, binary.BigEndian.AppendUint64(nil, 300000000)...)
+	chpl = append(chpl, 6)
+	chpl = append(chpl, []byte("Second")...)
+
+	file := append(
+		mp4Box("ftyp", []byte("isom"), be32(512)),
+		mp4Box("moov",
+			mp4Box("mvhd", mvhd),
+			mp4Box("trak",
+				mp4Box("mdia",
+					mp4Box("mdhd", mdhd),
+					mp4Box("hdlr", hdlr),
+					mp4Box("minf",
+						mp4Box("stbl",
+							mp4Box("stsd", be32(0), be32(1), mp4Box("avc1", visualSampleEntry)),
+							mp4Box("stts", be32(0), be32(1), be32(240), be32(1001)),
+						),
+					),
+				),
+			),
+			mp4Box("udta",
+				mp4Box("chpl", chpl),
+				mp4Box("meta", be32(0), mp4Box("ilst",
+					mp4Box("\xa9alb", mp4Box("data", be32(1), be32(0), []byte("Album"))),
+					mp4Box("aART", mp4Box("data", be32(1), be32(0), []byte("Album Artist"))),
+					mp4Box("trkn", mp4Box("data", be32(0), be32(0), be16(0), be16(3), be16(12), be16(0))),
+					mp4Box("disk", mp4Box("data", be32(0), be32(0), be16(0), be16(2), be16(0))),
+				)),
+			),
+		)...,
+	)
+	path := writeTestFile(t, "test.mp4", file)
+	uut := probe.NewNativeProber(nil)
+
+	// when
+	result := uut.File(path)
+
+	// then
+	if result.Err != nil {
+		t.Fatalf("Unexpected error reported: %s", result.Err)
+	}
+
+	if result.Format.Duration != 60 {
+		t.Errorf("Expected duration %f to equal 60", result.Format.Duration)
+	}
+
+	if len(result.VideoStreams) != 1 {
+		t.Fatalf("Expected one video stream, got %d", len(result.VideoStreams))
+	}
+
+	video := result.VideoStreams[0]
+	expectedFrameRate := 24000.0 / 1001.0
+	if video.Codec != "h264" || video.Width != 1280 || video.Height != 720 || video.Language != "eng" || math.Abs(video.FrameRate-expectedFrameRate) > 0.001 {
+		t.Errorf("Unexpected video stream: %+v", video)
+	}
+
+	if len(result.Chapters) != 2 || result.Chapters[1].StartTime != 30 || result.Chapters[1].EndTime != 60 {
+		t.Errorf("Unexpected chapters: %+v", result.Chapters)
+	}
+
+	tags := result.Format.Tags
+	if tags.Album != "Album" || tags.AlbumArtist != "Album Artist" || tags.Track != "3/12" || tags.Disc != "2" {
+		t.Errorf("Unexpected tags: %+v", tags)
+	}
+}
+
+func TestNativeProber_UnsupportedContainerWithoutFallback(t *testing.T) {
+	// given
+	path := writeTestFile(t, "test.avi", []byte("RIFF\x00\x00\x00\x00AVI LIST"))
+	uut := probe.NewNativeProber(nil)
+
+	// when
+	result := uut.File(path)
+
+	// then
+	if result.Err == nil {
+		t.Errorf("Expected error to be reported for unsupported container")
+	}
+
+	if result.IsMediaFile() {
+		t.Errorf("Expected unsupported container to not be a media file")
+	}
+}
+
+type testFallbackProber struct {
+	probed []string
+}
+
+func (tfp *testFallbackProber) File(filepath string) probe.Result {
+	tfp.probed = append(tfp.probed, filepath)
+
+	return probe.Result{Path: filepath}
+}
+
+func (tfp *testFallbackProber) Name() string {
+	return "test"
+}
+
+func TestNativeProber_FallbackOnParseError(t *testing.T) {
+	// given
+	path := writeTestFile(t, "broken.mkv", append(append([]byte{}, 0x1A, 0x45, 0xDF, 0xA3), []byte("\xff\xff\xff\xff\xff\xff\xff\xff")...))
+	fallback := &testFallbackProber{}
+	uut := probe.NewNativeProber(fallback)
+
+	// when
+	result := uut.File(path)
+
+	// then
+	if len(fallback.probed) != 1 || fallback.probed[0] != path {
+		t.Errorf("Expected file to be probed by fallback prober, probed: %v", fallback.probed)
+	}
+
+	if result.Err != nil {
+		t.Errorf("Expected result of fallback prober, got error: %s", result.Err)
+	}
+}
@@ -0,0 +1,28 @@
+package probe
+
+import "fmt"
+
+const (
+	// FfprobeProberName is a name of a prober using ffprobe.
+	FfprobeProberName = "ffprobe"
+	// NativeProberName is a name of a prober parsing container headers without external programs.
+	NativeProberName = "native"
+)
+
+// Prober checks information about the file format, it's streams and whether it can be used as a media file.
+type Prober interface {
+	File(filepath string) Result
+	Name() string
+}
+
+// NewProber returns a prober by its name.
+func NewProber(name string) (Prober, error) {
+	switch name {
+	case FfprobeProberName:
+		return NewFfprobeProber(), nil
+	case NativeProberName:
+		return NewNativeProber(NewFfprobeProber()), nil
+	default:
+		return nil, fmt.Errorf("unknown prober '%s'", name)
+	}
+}
@@ -0,0 +1,124 @@
+package probe
+
+// HDR formats recognized in video streams.
+const (
+	HDR10       = "HDR10"
+	HDR10Plus   = "HDR10+"
+	HLG         = "HLG"
+	DolbyVision = "Dolby Vision"
+)
+
+// Chapter specifies information about chapters included in the file
+type Chapter struct {
+	ChapterID string  `json:"ChapterID"`
+	EndTime   float64 `json:"EndTime"`
+	StartTime float64 `json:"StartTime"`
+	Title     string  `json:"Title"`
+}
+
+// Disposition specifies flags describing the intended use of a stream
+type Disposition struct {
+	AttachedPic     bool `json:"AttachedPic"`
+	Comment         bool `json:"Comment"`
+	Default         bool `json:"Default"`
+	Forced          bool `json:"Forced"`
+	HearingImpaired bool `json:"HearingImpaired"`
+	VisualImpaired  bool `json:"VisualImpaired"`
+}
+
+// SubtitleStream specifies information about subtitles inluded in the file
+type SubtitleStream struct {
+	Codec       string      `json:"Codec"`
+	Disposition Disposition `json:"Disposition"`
+	Language    string      `json:"Language"`
+	SubtitleID  string      `json:"SubtitleID"`
+	Title       string      `json:"Title"`
+}
+
+// AudioStream specifies information about audio the file includes
+type AudioStream struct {
+	AudioID       string      `json:"AudioID"`
+	BitRate       int64       `json:"BitRate"`
+	ChannelLayout string      `json:"ChannelLayout"`
+	Channels      int         `json:"Channels"`
+	Codec         string      `json:"Codec"`
+	Disposition   Disposition `json:"Disposition"`
+	Language      string      `json:"Language"`
+	Profile       string      `json:"Profile"`
+	SampleRate    int         `json:"SampleRate"`
+	Title         string      `json:"Title"`
+}
+
+// VideoStream specifies information about video the file includes
+type VideoStream struct {
+	BitRate        int64       `json:"BitRate"`
+	Codec          string      `json:"Codec"`
+	ColorPrimaries string      `json:"ColorPrimaries"`
+	ColorSpace     string      `json:"ColorSpace"`
+	ColorTransfer  string      `json:"ColorTransfer"`
+	Disposition    Disposition `json:"Disposition"`
+	FrameRate      float64     `json:"FrameRate"`
+	HDR            string      `json:"HDR"`
+	Height         int         `json:"Height"`
+	Language       string      `json:"Language"`
+	PixelFormat    string      `json:"PixelFormat"`
+	Profile        string      `json:"Profile"`
+	Width          int         `json:"Width"`
+	Title          string      `json:"Title"`
+}
+
+// Tags specifies descriptive metadata of the media container file
+type Tags struct {
+	Album       string `json:"Album"`
+	AlbumArtist string `json:"AlbumArtist"`
+	Artist      string `json:"Artist"`
+	Date        string `json:"Date"`
+	Disc        string `json:"Disc"`
+	Episode     string `json:"Episode"`
+	Genre       string `json:"Genre"`
+	Season      string `json:"Season"`
+	Show        string `json:"Show"`
+	Track       string `json:"Track"`
+}
+
+// Format specifies general information about media container file
+type Format struct {
+	BitRate  int64   `json:"BitRate"`
+	Name     string  `json:"Name"`
+	LongName string  `json:"LongName"`
+	Duration float64 `json:"Duration"`
+	Size     int64   `json:"Size"`
+	Tags     Tags    `json:"Tags"`
+	Title    string  `json:"Title"`
+}
+
+// Result contains information about the file
+type Result struct {
+	Path            string           `json:"Path"`
+	Format          Format           `json:"Format"`
+	Chapters        []Chapter        `json:"Chapters"`
+	VideoStreams    []VideoStream    `json:"VideoStreams"`
+	AudioStreams    []AudioStream    `json:"AudioStreams"`
+	SubtitleStreams []SubtitleStream `json:"SubtitleStreams"`
+	Err             error
+}
+
+func newResult(filepath string) Result {
+	return Result{
+		Path:            filepath,
+		Format:          Format{},
+		Chapters:        []Chapter{},
+		VideoStreams:    []VideoStream{},
+		AudioStreams:    []AudioStream{},
+		SubtitleStreams: []SubtitleStream{},
+	}
+}
+
+// IsMediaFile checks whether parsing of file was successful, and whether any video streams are present in the file (audio or subtitles optional)
+func (res Result) IsMediaFile() bool {
+	if res.Err != nil {
+		return false
+	}
+
+	return len(res.VideoStreams) != 0 || len(res.AudioStreams) != 0
+}
@@ -0,0 +1,90 @@
+package sidecar
+
+import (
+	"encoding/xml"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// nfo is a Kodi-style metadata file. The same fields are shared by "movie", "episodedetails" and "tvshow" root elements.
+type nfo struct {
+	Actors []struct {
+		Name  string `xml:"name"`
+		Role  string `xml:"role"`
+		Thumb string `xml:"thumb"`
+	} `xml:"actor"`
+	Genres  []string `xml:"genre"`
+	Plot    string   `xml:"plot"`
+	Rating  string   `xml:"rating"`
+	Ratings struct {
+		Rating []struct {
+			Default string `xml:"default,attr"`
+			Value   string `xml:"value"`
+		} `xml:"rating"`
+	} `xml:"ratings"`
+	Title string `xml:"title"`
+}
+
+// readNfo parses NFO file at the path.
+// NFO files containing only a link to the online database (which Kodi also accepts) result in an error.
+func readNfo(path string) (Metadata, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	var parsed nfo
+	err = xml.Unmarshal(content, &parsed)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	metadata := Metadata{
+		Cast:   []Actor{},
+		Genres: []string{},
+		Plot:   strings.TrimSpace(parsed.Plot),
+		Rating: parsed.rating(),
+		Title:  strings.TrimSpace(parsed.Title),
+	}
+
+	for _, genre := range parsed.Genres {
+		// older scrapers put all genres in a single element separated by slashes
+		for _, name := range strings.Split(genre, "/") {
+			if name = strings.TrimSpace(name); name != "" {
+				metadata.Genres = append(metadata.Genres, name)
+			}
+		}
+	}
+
+	for _, actor := range parsed.Actors {
+		metadata.Cast = append(metadata.Cast, Actor{
+			Name:  strings.TrimSpace(actor.Name),
+			Role:  strings.TrimSpace(actor.Role),
+			Thumb: strings.TrimSpace(actor.Thumb),
+		})
+	}
+
+	return metadata, nil
+}
+
+// rating returns default rating from "ratings" element (newer Kodi versions), or the value of "rating" element.
+func (n nfo) rating() float64 {
+	values := []string{n.Rating}
+	for _, rating := range n.Ratings.Rating {
+		if rating.Default == "true" {
+			values = append([]string{rating.Value}, values...)
+		} else {
+			values = append(values, rating.Value)
+		}
+	}
+
+	for _, value := range values {
+		rating, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err == nil {
+			return rating
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,258 @@
+// Package sidecar reads metadata from files accompanying media files, like Kodi-style NFO files and artwork images.
+package sidecar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	nfoExtension        = ".nfo"
+	tbnExtension        = ".tbn"
+	movieNfoFilename    = "movie.nfo"
+	tvshowNfoFilename   = "tvshow.nfo"
+	artworkSeparator    = "-"
+	posterArtworkName   = "poster"
+	fanartArtworkName   = "fanart"
+	folderArtworkName   = "folder"
+	thumbArtworkName    = "thumb"
+	coverArtworkName    = "cover"
+	backdropArtworkName = "backdrop"
+)
+
+var (
+	imageExtensions = []string{".jpg", ".jpeg", ".png", ".webp", tbnExtension}
+	artworkNames    = []string{posterArtworkName, fanartArtworkName, folderArtworkName, thumbArtworkName, coverArtworkName, backdropArtworkName}
+)
+
+// Actor describes a person from the cast.
+type Actor struct {
+	Name  string `json:"Name"`
+	Role  string `json:"Role"`
+	Thumb string `json:"Thumb"`
+}
+
+// Artwork holds paths to images related to the media file or directory.
+// Empty path means that the image is not present.
+type Artwork struct {
+	Fanart string `json:"Fanart"`
+	Folder string `json:"Folder"`
+	Poster string `json:"Poster"`
+	Thumb  string `json:"Thumb"`
+}
+
+// Metadata holds information read from sidecar files.
+type Metadata struct {
+	Artwork Artwork  `json:"Artwork"`
+	Cast    []Actor  `json:"Cast"`
+	Genres  []string `json:"Genres"`
+	Plot    string   `json:"Plot"`
+	Rating  float64  `json:"Rating"`
+	Title   string   `json:"Title"`
+}
+
+// IsSidecarFile checks whether the file at the path holds metadata for other files,
+// and as such should not be treated as a media file itself.
+// Images named after media files (eg. "Movie.jpg" next to "Movie.mkv") are sidecar files too,
+// which requires listing of the directory of the path.
+func IsSidecarFile(path string) bool {
+	if isSidecarName(path) {
+		return true
+	}
+
+	if !isImageExtension(strings.ToLower(filepath.Ext(path))) {
+		return false
+	}
+
+	dir, err := ReadDirectory(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+
+	return dir.hasMediaFileNamed(path)
+}
+
+// isSidecarName checks whether the name of the file alone makes it a sidecar file, eg. "Movie.nfo" or "folder.jpg".
+func isSidecarName(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == nfoExtension || ext == tbnExtension {
+		return true
+	}
+
+	if !isImageExtension(ext) {
+		return false
+	}
+
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	for _, artworkName := range artworkNames {
+		if name == artworkName || strings.HasSuffix(name, artworkSeparator+artworkName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Directory looks up sidecar files among files of a single directory.
+type Directory struct {
+	names map[string]string // lowercase name to the actual name of the file
+	path  string
+}
+
+// NewDirectory returns lookup for directory at the path with provided file names.
+func NewDirectory(path string, names []string) Directory {
+	dir := Directory{
+		names: map[string]string{},
+		path:  path,
+	}
+
+	for _, name := range names {
+		dir.names[strings.ToLower(name)] = name
+	}
+
+	return dir
+}
+
+// ReadDirectory lists the directory at the path and returns lookup for it's files.
+func ReadDirectory(path string) (Directory, error) {
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return Directory{}, err
+	}
+
+	var names []string
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return NewDirectory(path, names), nil
+}
+
+// IsSidecarFile checks whether the file at the path holds metadata for other files of the directory.
+// It's the same as package-level IsSidecarFile, but does not list the directory again.
+func (d Directory) IsSidecarFile(path string) bool {
+	if isSidecarName(path) {
+		return true
+	}
+
+	return isImageExtension(strings.ToLower(filepath.Ext(path))) && d.hasMediaFileNamed(path)
+}
+
+// Metadata returns metadata of the directory itself, read from "tvshow.nfo" and directory-level artwork.
+func (d Directory) Metadata() Metadata {
+	metadata := d.readNfo(tvshowNfoFilename)
+	metadata.Artwork = d.directoryArtwork()
+
+	return metadata
+}
+
+// MediaFile returns metadata of the media file at the path, read from "<name>.nfo" (or "movie.nfo")
+// and "<name>-poster.jpg"-like artwork. Directory-level artwork is used for images missing for the media file.
+func (d Directory) MediaFile(path string) Metadata {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	metadata := d.readNfo(base+nfoExtension, movieNfoFilename)
+
+	artwork := d.directoryArtwork()
+	metadata.Artwork = Artwork{
+		Fanart: firstNonEmpty(d.image(base+artworkSeparator+fanartArtworkName, base+artworkSeparator+backdropArtworkName), artwork.Fanart),
+		Folder: artwork.Folder,
+		Poster: firstNonEmpty(d.image(base+artworkSeparator+posterArtworkName, base+artworkSeparator+coverArtworkName), artwork.Poster),
+		Thumb:  firstNonEmpty(d.image(base+artworkSeparator+thumbArtworkName, base), artwork.Thumb),
+	}
+
+	return metadata
+}
+
+func (d Directory) directoryArtwork() Artwork {
+	return Artwork{
+		Fanart: d.image(fanartArtworkName, backdropArtworkName),
+		Folder: d.image(folderArtworkName),
+		Poster: d.image(posterArtworkName, coverArtworkName, folderArtworkName),
+		Thumb:  d.image(thumbArtworkName),
+	}
+}
+
+// readNfo returns metadata from the first existing and valid NFO file from provided names.
+func (d Directory) readNfo(names ...string) Metadata {
+	for _, name := range names {
+		path, ok := d.file(name)
+		if !ok {
+			continue
+		}
+
+		metadata, err := readNfo(path)
+		if err == nil {
+			return metadata
+		}
+	}
+
+	return Metadata{
+		Cast:   []Actor{},
+		Genres: []string{},
+	}
+}
+
+// image returns path of the first image with any of the names (without extension) that exists in the directory.
+func (d Directory) image(names ...string) string {
+	for _, name := range names {
+		for _, ext := range imageExtensions {
+			if path, ok := d.file(name + ext); ok {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+// hasMediaFileNamed checks whether the directory has other file with the same name (without extension) as the image at the path,
+// which is not a sidecar file itself - such image is used as a thumb of that file.
+func (d Directory) hasMediaFileNamed(imagePath string) bool {
+	imageName := strings.ToLower(filepath.Base(imagePath))
+	base := strings.TrimSuffix(imageName, filepath.Ext(imageName))
+	for name := range d.names {
+		ext := filepath.Ext(name)
+		if name == imageName || strings.TrimSuffix(name, ext) != base {
+			continue
+		}
+
+		if ext != nfoExtension && !isImageExtension(ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d Directory) file(name string) (string, bool) {
+	actualName, ok := d.names[strings.ToLower(name)]
+	if !ok {
+		return "", false
+	}
+
+	return filepath.Join(d.path, actualName), true
+}
+
+func isImageExtension(ext string) bool {
+	for _, imageExt := range imageExtensions {
+		if ext == imageExt {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
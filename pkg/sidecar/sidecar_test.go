@@ -0,0 +1,121 @@
+package sidecar_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
+)
+
+const movieNfo = `<?xml version="1.0" encoding="UTF-8" standalone="yes" ?>
+<movie>
+	<title>Movie Title</title>
+	<plot>Something happens.</plot>
+	<genre>Drama / Thriller</genre>
+	<genre>Crime</genre>
+	<rating>6.1</rating>
+	<ratings>
+		<rating name="themoviedb"><value>7.0</value></rating>
+		<rating name="imdb" default="true"><value>7.5</value></rating>
+	</ratings>
+	<actor><name>Actor Name</name><role>Main Character</role></actor>
+</movie>`
+
+func TestDirectory_MediaFile(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	files := map[string]string{
+		"Movie (2019).mkv":        "",
+		"Movie (2019).nfo":        movieNfo,
+		"Movie (2019)-poster.jpg": "",
+		"Fanart.JPG":              "",
+		"folder.png":              "",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatalf("Could not create file '%s': %s", name, err)
+		}
+	}
+
+	uut, err := sidecar.ReadDirectory(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading directory: %s", err)
+	}
+
+	// when
+	result := uut.MediaFile(filepath.Join(dir, "Movie (2019).mkv"))
+
+	// then
+	expected := sidecar.Metadata{
+		Artwork: sidecar.Artwork{
+			Fanart: filepath.Join(dir, "Fanart.JPG"),
+			Folder: filepath.Join(dir, "folder.png"),
+			Poster: filepath.Join(dir, "Movie (2019)-poster.jpg"),
+		},
+		Cast:   []sidecar.Actor{{Name: "Actor Name", Role: "Main Character"}},
+		Genres: []string{"Drama", "Thriller", "Crime"},
+		Plot:   "Something happens.",
+		Rating: 7.5,
+		Title:  "Movie Title",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected metadata:\n%+v\ngot:\n%+v", expected, result)
+	}
+}
+
+func TestIsSidecarFile(t *testing.T) {
+	testCases := map[string]bool{
+		"/media/Movie.nfo":        true,
+		"/media/Movie-poster.jpg": true,
+		"/media/folder.jpg":       true,
+		"/media/Episode.tbn":      true,
+		"/media/Movie.mkv":        false,
+		"/media/holidays.jpg":     false,
+	}
+
+	for path, expected := range testCases {
+		if result := sidecar.IsSidecarFile(path); result != expected {
+			t.Errorf("Expected IsSidecarFile('%s') to be %t", path, expected)
+		}
+	}
+}
+
+func TestIsSidecarFile_ImageNamedAfterMediaFile(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	for _, name := range []string{"Movie (2019).mkv", "Movie (2019).JPG", "Movie (2019).nfo", "holidays.jpg", "holidays.png", "notes.nfo", "notes.png"} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0600)
+		if err != nil {
+			t.Fatalf("Could not create file '%s': %s", name, err)
+		}
+	}
+
+	uut, err := sidecar.ReadDirectory(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading directory: %s", err)
+	}
+
+	testCases := map[string]bool{
+		"Movie (2019).JPG": true,
+		"Movie (2019).mkv": false,
+		"holidays.jpg":     false,
+		"holidays.png":     false,
+		"notes.png":        false,
+	}
+
+	for name, expected := range testCases {
+		path := filepath.Join(dir, name)
+
+		// when
+		result := sidecar.IsSidecarFile(path)
+		directoryResult := uut.IsSidecarFile(path)
+
+		// then
+		if result != expected || directoryResult != expected {
+			t.Errorf("Expected IsSidecarFile('%s') to be %t, got %t (and %t for the directory)", name, expected, result, directoryResult)
+		}
+	}
+}
@@ -1,7 +1,183 @@
 package directories
 
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
 type Entry struct {
+	// Exclude lists glob patterns of files and directories which should not be handled.
+	Exclude []string
+	// Include lists glob patterns of files which should be handled. When empty, all files are handled.
+	Include []string
+	// MaxDepth limits the depth of recursion relative to the Root. Zero means no limit.
+	MaxDepth int
+	// Metadata is read from sidecar files in the directory, like "tvshow.nfo" or "folder.jpg".
+	Metadata  sidecar.Metadata
 	Path      string
+	Polled    bool
 	Recursive bool
-	Watched   bool
+	// Root is a path of a directory which was added explicitly and from which this entry originates.
+	// Empty Root means that the entry is a root itself.
+	Root string
+	// UserMetadata is set by users, eg. tags or rating of the directory.
+	UserMetadata user_metadata.Metadata
+	Watched      bool
+}
+
+// WatchedByFsEvents checks whether the directory should be watched by file system notifications.
+// Watched directories that are polled do not rely on file system notifications.
+func (e Entry) WatchedByFsEvents() bool {
+	return e.Watched && !e.Polled
+}
+
+// RootPath returns path of the root directory from which the entry originates.
+func (e Entry) RootPath() string {
+	if e.Root == "" {
+		return EnsureDirectoryPath(e.Path)
+	}
+
+	return EnsureDirectoryPath(e.Root)
+}
+
+// Child returns an entry for a subdirectory, which inherits rules of the entry.
+func (e Entry) Child(path string) Entry {
+	child := e
+	child.Metadata = sidecar.Metadata{}
+	child.UserMetadata = user_metadata.Metadata{}
+	child.Path = path
+	child.Root = e.RootPath()
+
+	return child
+}
+
+// Depth returns how deep the path is relative to the root of the entry.
+// Returns -1 when path is not under the root.
+func (e Entry) Depth(targetPath string) int {
+	relPath, ok := relativeToDir(e.RootPath(), targetPath)
+	if !ok {
+		return -1
+	}
+
+	if relPath == "." {
+		return 0
+	}
+
+	return len(strings.Split(relPath, "/"))
+}
+
+// Allows checks whether path under the entry's root satisfies include, exclude and depth rules of the entry.
+// Include rules are only checked for files, since directories need to be traversed to find included files.
+func (e Entry) Allows(targetPath string, isDir bool) bool {
+	relPath, ok := relativeToDir(e.RootPath(), targetPath)
+	if !ok {
+		return false
+	}
+
+	if relPath == "." {
+		return true
+	}
+
+	if isDir && e.MaxDepth > 0 && e.Depth(targetPath) > e.MaxDepth {
+		return false
+	}
+
+	if MatchesAny(e.Exclude, relPath) {
+		return false
+	}
+
+	if isDir || len(e.Include) == 0 {
+		return true
+	}
+
+	return MatchesAny(e.Include, relPath)
+}
+
+// MatchesAny checks whether relative, slash separated path matches any of the patterns.
+// Patterns follow path.Match syntax with the addition of "**" segment matching any number of directories.
+// Patterns without a separator are matched against every segment of the path (like a file name in gitignore).
+func MatchesAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, relPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePattern checks whether pattern is syntactically correct.
+func ValidatePattern(pattern string) error {
+	for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if segment == "**" {
+			continue
+		}
+
+		_, err := path.Match(segment, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func matchGlob(pattern string, relPath string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return false
+	}
+
+	pathSegments := strings.Split(relPath, "/")
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		for _, segment := range pathSegments {
+			if matched, _ := path.Match(strings.TrimSuffix(pattern, "/"), segment); matched {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), pathSegments)
+}
+
+func matchSegments(patternSegments []string, pathSegments []string) bool {
+	if len(patternSegments) == 0 {
+		return len(pathSegments) == 0
+	}
+
+	if patternSegments[0] == "**" {
+		for idx := 0; idx <= len(pathSegments); idx++ {
+			if matchSegments(patternSegments[1:], pathSegments[idx:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(pathSegments) == 0 {
+		return false
+	}
+
+	matched, err := path.Match(patternSegments[0], pathSegments[0])
+	if err != nil || !matched {
+		return false
+	}
+
+	return matchSegments(patternSegments[1:], pathSegments[1:])
+}
+
+func relativeToDir(dirPath string, targetPath string) (string, bool) {
+	relPath, err := filepath.Rel(dirPath, targetPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(relPath), true
 }
@@ -0,0 +1,82 @@
+package directories_test
+
+import (
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+)
+
+func TestAllows_ExcludePatterns(t *testing.T) {
+	// given
+	uut := directories.Entry{
+		Exclude: []string{"**/Extras/**", "*.sample.mkv", ".*"},
+		Path:    "/media/",
+	}
+
+	testCases := map[string]bool{
+		"/media/Show/S01E01.mkv":        true,
+		"/media/Show/Extras":            false,
+		"/media/Show/Extras/making.mkv": false,
+		"/media/Movie/movie.sample.mkv": false,
+		"/media/.hidden/file.mkv":       false,
+		"/other/file.mkv":               false,
+	}
+
+	for path, expected := range testCases {
+		// when
+		result := uut.Allows(path, false)
+
+		// then
+		if result != expected {
+			t.Errorf("Expected Allows for '%s' to be %t, got %t", path, expected, result)
+		}
+	}
+}
+
+func TestAllows_IncludePatternsOnlyForFiles(t *testing.T) {
+	// given
+	uut := directories.Entry{
+		Include: []string{"*.mkv"},
+		Path:    "/media",
+	}
+
+	// when
+	dirAllowed := uut.Allows("/media/Show", true)
+	mkvAllowed := uut.Allows("/media/Show/episode.mkv", false)
+	srtAllowed := uut.Allows("/media/Show/episode.srt", false)
+
+	// then
+	if !dirAllowed {
+		t.Errorf("Expected directory to be allowed regardless of include patterns")
+	}
+
+	if !mkvAllowed {
+		t.Errorf("Expected file matching include pattern to be allowed")
+	}
+
+	if srtAllowed {
+		t.Errorf("Expected file not matching include pattern to not be allowed")
+	}
+}
+
+func TestAllows_MaxDepth(t *testing.T) {
+	// given
+	uut := directories.Entry{
+		MaxDepth: 1,
+		Path:     "/media",
+	}
+
+	// when
+	child := uut.Child("/media/Show")
+	shallowAllowed := child.Allows("/media/Show", true)
+	deepAllowed := child.Allows("/media/Show/Season 1", true)
+
+	// then
+	if !shallowAllowed {
+		t.Errorf("Expected directory at max depth to be allowed")
+	}
+
+	if deepAllowed {
+		t.Errorf("Expected directory deeper than max depth to not be allowed")
+	}
+}
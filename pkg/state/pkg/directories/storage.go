@@ -92,6 +92,68 @@ func (d *Storage) Add(dir Entry) {
 	})
 }
 
+// Update replaces already handled directory with a provided one, matching them by path.
+// When directory cannot be found, the error is being reported.
+func (d *Storage) Update(dir Entry) error {
+	path := EnsureDirectoryPath(dir.Path)
+
+	d.lock.Lock()
+	if _, ok := d.items[path]; !ok {
+		d.lock.Unlock()
+
+		return errNoDirectoryAvailable
+	}
+
+	d.items[path] = dir
+	d.lock.Unlock()
+
+	d.revision.Tick()
+	d.broadcaster.Send(Change{
+		variant: UpdatedDirectoriesChange,
+		items: map[string]Entry{
+			path: dir,
+		},
+	})
+
+	return nil
+}
+
+// Modify replaces already handled directory under the path with the result of modify, which is called with the handled directory
+// under the lock of the storage - so concurrent modifications of the same directory do not overwrite each other.
+// When directory cannot be found or modify fails, the error is being reported and the directory is left unchanged.
+func (d *Storage) Modify(path string, modify func(dir Entry) (Entry, error)) (Entry, error) {
+	keyPath := EnsureDirectoryPath(path)
+
+	d.lock.Lock()
+	prevDir, ok := d.items[keyPath]
+	if !ok {
+		d.lock.Unlock()
+
+		return Entry{}, errNoDirectoryAvailable
+	}
+
+	dir, err := modify(prevDir)
+	if err != nil {
+		d.lock.Unlock()
+
+		return prevDir, err
+	}
+
+	dir.Path = prevDir.Path
+	d.items[keyPath] = dir
+	d.lock.Unlock()
+
+	d.revision.Tick()
+	d.broadcaster.Send(Change{
+		variant: UpdatedDirectoriesChange,
+		items: map[string]Entry{
+			keyPath: dir,
+		},
+	})
+
+	return dir, nil
+}
+
 // All returns a copy of all Directories being handled by the instance of the server.
 func (d *Storage) All() map[string]Entry {
 	allDirectories := map[string]Entry{}
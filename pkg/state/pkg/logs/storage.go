@@ -0,0 +1,170 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/state/internal/revision"
+)
+
+type SubscriberCB = func(change Change)
+
+type logsChangeSubscriber struct {
+	cb SubscriberCB
+}
+
+func (s *logsChangeSubscriber) Receive(change Change) {
+	s.cb(change)
+}
+
+const (
+	// DefaultCapacity is a default number of the latest entries kept in the storage.
+	DefaultCapacity = 1000
+
+	// InfoLevel is a level of entries informing about regular operation of the server.
+	InfoLevel Level = "info"
+
+	// ErrorLevel is a level of entries informing about failures.
+	ErrorLevel Level = "error"
+
+	ApiSource        Source = "api"
+	DispatcherSource Source = "dispatcher"
+	MpvManagerSource Source = "mpv-manager"
+	RestSource       Source = "rest"
+	SseSource        Source = "sse"
+	WsSource         Source = "ws"
+)
+
+var (
+	ErrUnknownLevel = errors.New("unknown log level")
+
+	levelSeverities = map[Level]int{
+		InfoLevel:  0,
+		ErrorLevel: 1,
+	}
+)
+
+// Level specifies severity of a log entry.
+type Level string
+
+// ParseLevel returns level with the provided name.
+func ParseLevel(name string) (Level, error) {
+	level := Level(name)
+	if _, ok := levelSeverities[level]; !ok {
+		return level, fmt.Errorf("%w: %s", ErrUnknownLevel, name)
+	}
+
+	return level, nil
+}
+
+// AtLeast returns whether the level is at least as severe as the provided one.
+func (l Level) AtLeast(level Level) bool {
+	return levelSeverities[l] >= levelSeverities[level]
+}
+
+// Source specifies which part of the server logged an entry.
+type Source string
+
+// Entry is a single message logged by the server.
+type Entry struct {
+	Level   Level     `json:"Level"`
+	Message string    `json:"Message"`
+	Source  Source    `json:"Source"`
+	Time    time.Time `json:"Time"`
+}
+
+// Change informs about an entry added to the logs. Variant of the change is the level of the entry,
+// so observers may choose levels they are interested in.
+type Change struct {
+	Entry Entry
+}
+
+// MarshalJSON returns change items in JSON format. Satisfies json.Marshaller.
+func (c Change) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Entry)
+}
+
+func (c Change) Variant() common.ChangeVariant {
+	return common.ChangeVariant(c.Entry.Level)
+}
+
+// Storage holds the latest entries logged by the server. The oldest entries are removed when capacity is reached.
+type Storage struct {
+	broadcaster *common.ChangesBroadcaster[Change]
+	capacity    int
+	entries     []Entry
+	lock        *sync.RWMutex
+	revision    *revision.Storage
+}
+
+// NewStorage constructs Logs state.
+func NewStorage(broadcaster *common.ChangesBroadcaster[Change]) *Storage {
+	return &Storage{
+		broadcaster: broadcaster,
+		capacity:    DefaultCapacity,
+		entries:     []Entry{},
+		lock:        &sync.RWMutex{},
+		revision:    revision.NewStorage(),
+	}
+}
+
+// Add appends entry to the logs.
+func (s *Storage) Add(entry Entry) {
+	s.lock.Lock()
+	s.entries = append(s.entries, entry)
+	if len(s.entries) > s.capacity {
+		s.entries = append([]Entry{}, s.entries[len(s.entries)-s.capacity:]...)
+	}
+	s.lock.Unlock()
+
+	s.revision.Tick()
+	s.broadcaster.Send(Change{
+		Entry: entry,
+	})
+}
+
+// All returns copy of all entries kept in the storage, from the oldest.
+func (s *Storage) All() []Entry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return append([]Entry{}, s.entries...)
+}
+
+// Entries returns entries at least as severe as the level, logged after the provided time, from the oldest.
+// Zero time returns entries regardless of the time they were logged.
+func (s *Storage) Entries(level Level, since time.Time) []Entry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	entries := []Entry{}
+	for _, entry := range s.entries {
+		if !entry.Level.AtLeast(level) || !entry.Time.After(since) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// MarshalJSON satisfies json.Marshaller.
+func (s *Storage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.All())
+}
+
+func (s *Storage) Revision() revision.Identifier {
+	return s.revision.Revision()
+}
+
+func (s *Storage) Subscribe(cb SubscriberCB, onError func(err error)) func() {
+	subscriber := logsChangeSubscriber{
+		cb,
+	}
+	return s.broadcaster.Subscribe(&subscriber)
+}
@@ -0,0 +1,70 @@
+package logs
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+)
+
+func newTestStorage() *Storage {
+	broadcaster := common.NewChangesBroadcaster[Change]()
+	broadcaster.Broadcast()
+
+	return NewStorage(broadcaster)
+}
+
+func TestEntries(t *testing.T) {
+	// given
+	uut := newTestStorage()
+	since := time.Now()
+	uut.Add(Entry{Level: ErrorLevel, Message: "before", Source: ApiSource, Time: since.Add(-time.Second)})
+	uut.Add(Entry{Level: InfoLevel, Message: "info", Source: ApiSource, Time: since.Add(time.Second)})
+	uut.Add(Entry{Level: ErrorLevel, Message: "error", Source: SseSource, Time: since.Add(time.Second)})
+
+	// when
+	entries := uut.Entries(ErrorLevel, since)
+
+	// then
+	if len(entries) != 1 || entries[0].Message != "error" {
+		t.Errorf("Expected only the error entry logged after since time, got %+v", entries)
+	}
+}
+
+func TestAddRemovesOldestEntries(t *testing.T) {
+	// given
+	uut := newTestStorage()
+	uut.capacity = 2
+
+	// when
+	uut.Add(Entry{Level: InfoLevel, Message: "first"})
+	uut.Add(Entry{Level: InfoLevel, Message: "second"})
+	uut.Add(Entry{Level: InfoLevel, Message: "third"})
+
+	// then
+	entries := uut.All()
+	if len(entries) != 2 || entries[0].Message != "second" || entries[1].Message != "third" {
+		t.Errorf("Expected only the latest two entries, got %+v", entries)
+	}
+}
+
+func TestWriterStripsLoggerHeader(t *testing.T) {
+	// given
+	uut := newTestStorage()
+	logger := log.New(uut.Writer(RestSource, ErrorLevel), "rest.Server#", log.LstdFlags)
+
+	// when
+	logger.Printf("could not add directory: %s\n", "/media")
+
+	// then
+	entries := uut.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected a single entry, got %d", len(entries))
+	}
+
+	expected := Entry{Level: ErrorLevel, Message: "could not add directory: /media", Source: RestSource}
+	if entries[0].Message != expected.Message || entries[0].Level != expected.Level || entries[0].Source != expected.Source {
+		t.Errorf("Expected entry %+v, got %+v", expected, entries[0])
+	}
+}
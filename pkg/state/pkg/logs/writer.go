@@ -0,0 +1,49 @@
+package logs
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// loggerHeader matches prefix and date written by log.Logger with log.LstdFlags, eg. "sse.Server#2024/05/01 12:00:00 ".
+	loggerHeader = regexp.MustCompile(`^\S*?\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} `)
+)
+
+type writer struct {
+	level   Level
+	source  Source
+	storage *Storage
+}
+
+// Write adds every written message as an entry. Satisfies io.Writer.
+func (w writer) Write(p []byte) (int, error) {
+	message := strings.TrimRight(string(p), "\n")
+	message = loggerHeader.ReplaceAllString(message, "")
+
+	w.storage.Add(Entry{
+		Level:   w.level,
+		Message: message,
+		Source:  w.source,
+		Time:    time.Now(),
+	})
+
+	return len(p), nil
+}
+
+// Writer returns writer which adds messages written to it as entries of the source with the level.
+// It's meant to be used as an output of log.Logger - prefix and date written by the logger are not kept in messages.
+func (s *Storage) Writer(source Source, level Level) io.Writer {
+	return writer{
+		level:   level,
+		source:  source,
+		storage: s,
+	}
+}
+
+// TeeWriters returns writers writing both to the provided writers and to the storage, as info and error entries of the source.
+func (s *Storage) TeeWriters(source Source, out io.Writer, err io.Writer) (io.Writer, io.Writer) {
+	return io.MultiWriter(out, s.Writer(source, InfoLevel)), io.MultiWriter(err, s.Writer(source, ErrorLevel))
+}
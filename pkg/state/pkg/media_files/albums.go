@@ -0,0 +1,194 @@
+package media_files
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrAlbumNotFound occurs when there are no media files being tracks of an album with provided id.
+	ErrAlbumNotFound = errors.New("album not found")
+)
+
+// Album groups media files being tracks of the same album by the same artist.
+type Album struct {
+	Artist string `json:"Artist"`
+	// CoverArt is an UUID of the track with attached picture, which thumbnail can be used as the album cover.
+	CoverArt string  `json:"CoverArt"`
+	Duration float64 `json:"Duration"`
+	ID       string  `json:"ID"`
+	Name     string  `json:"Name"`
+	Tracks   int     `json:"Tracks"`
+	Year     string  `json:"Year"`
+}
+
+// Artist groups albums and tracks performed by the same artist.
+type Artist struct {
+	Albums int    `json:"Albums"`
+	ID     string `json:"ID"`
+	Name   string `json:"Name"`
+	Tracks int    `json:"Tracks"`
+}
+
+// AlbumID returns identifier of the album with provided name by the artist.
+// Names differing only in letter case and punctuation result in the same identifier.
+func AlbumID(artist string, album string) string {
+	return slug(artist + " " + album)
+}
+
+// ArtistID returns identifier of the artist with provided name.
+func ArtistID(artist string) string {
+	return slug(artist)
+}
+
+// Albums returns all albums that served media files are tracks of, ordered by their artists and names.
+// Tracks are grouped by the album artist, or the track artist when the album artist is absent.
+func (m *Storage) Albums() []Album {
+	albums := map[string]*Album{}
+	for _, mediaFile := range m.tracksByPath() {
+		id := AlbumID(mediaFile.AlbumArtist(), mediaFile.album)
+		album, ok := albums[id]
+		if !ok {
+			album = &Album{
+				Artist: mediaFile.AlbumArtist(),
+				ID:     id,
+				Name:   mediaFile.album,
+			}
+			albums[id] = album
+		}
+
+		album.addTrack(mediaFile)
+	}
+
+	result := []Album{}
+	for _, album := range albums {
+		result = append(result, *album)
+	}
+
+	slices.SortFunc(result, func(a, b Album) int {
+		if byArtist := cmp.Compare(strings.ToLower(a.Artist), strings.ToLower(b.Artist)); byArtist != 0 {
+			return byArtist
+		}
+
+		if byName := cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); byName != 0 {
+			return byName
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result
+}
+
+// Album returns the album with provided id along with it's tracks, ordered by disc and track numbers.
+// When the album cannot be found, the error is being reported.
+func (m *Storage) Album(id string) (Album, []Entry, error) {
+	var tracks []Entry
+	for _, mediaFile := range m.tracksByPath() {
+		if AlbumID(mediaFile.AlbumArtist(), mediaFile.album) == id {
+			tracks = append(tracks, mediaFile)
+		}
+	}
+
+	if len(tracks) == 0 {
+		return Album{}, nil, ErrAlbumNotFound
+	}
+
+	slices.SortStableFunc(tracks, func(a, b Entry) int {
+		if byDisc := cmp.Compare(a.disc, b.disc); byDisc != 0 {
+			return byDisc
+		}
+
+		return cmp.Compare(a.track, b.track)
+	})
+
+	album := Album{
+		Artist: tracks[0].AlbumArtist(),
+		ID:     id,
+		Name:   tracks[0].album,
+	}
+	for _, track := range tracks {
+		album.addTrack(track)
+	}
+
+	return album, tracks, nil
+}
+
+func (a *Album) addTrack(track Entry) {
+	a.Tracks++
+	a.Duration += track.duration
+	if a.Year == "" {
+		a.Year = track.tags.Date
+	}
+
+	if a.CoverArt == "" && track.hasCoverArt() {
+		a.CoverArt = track.uuid
+	}
+}
+
+// Artists returns all artists performing served media files, ordered by their names.
+func (m *Storage) Artists() []Artist {
+	artists := map[string]*Artist{}
+	albums := map[string]map[string]bool{}
+
+	m.lock.RLock()
+	for _, mediaFile := range m.items {
+		name := mediaFile.AlbumArtist()
+		if name == "" {
+			continue
+		}
+
+		id := ArtistID(name)
+		artist, ok := artists[id]
+		if !ok {
+			artist = &Artist{
+				ID:   id,
+				Name: name,
+			}
+			artists[id] = artist
+			albums[id] = map[string]bool{}
+		}
+
+		artist.Tracks++
+		if mediaFile.album != "" && !albums[id][AlbumID(name, mediaFile.album)] {
+			albums[id][AlbumID(name, mediaFile.album)] = true
+			artist.Albums++
+		}
+	}
+	m.lock.RUnlock()
+
+	result := []Artist{}
+	for _, artist := range artists {
+		result = append(result, *artist)
+	}
+
+	slices.SortFunc(result, func(a, b Artist) int {
+		if byName := cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); byName != 0 {
+			return byName
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result
+}
+
+// tracksByPath returns media files that are tracks of an album, ordered by their paths.
+func (m *Storage) tracksByPath() []Entry {
+	m.lock.RLock()
+	var tracks []Entry
+	for _, mediaFile := range m.items {
+		if mediaFile.album != "" {
+			tracks = append(tracks, mediaFile)
+		}
+	}
+	m.lock.RUnlock()
+
+	slices.SortFunc(tracks, func(a, b Entry) int {
+		return cmp.Compare(a.path, b.path)
+	})
+
+	return tracks
+}
@@ -2,9 +2,16 @@ package media_files
 
 import (
 	"encoding/json"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sarpt/mpv-web-api/pkg/naming"
 	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/sidecar"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
 )
 
 const (
@@ -15,44 +22,100 @@ const (
 
 // Entry specifies information about a media file that can be played.
 type Entry struct {
+	added           time.Time
+	album           string
+	albumArtist     string
+	artist          string
 	audioStreams    []probe.AudioStream
+	bitRate         int64
 	chapters        []probe.Chapter
+	disc            int
 	duration        float64
+	episode         int
 	formatName      string
 	formatLongName  string
+	metadata        sidecar.Metadata
 	path            string
+	quality         string
+	season          int
+	series          string
+	size            int64
 	subtitleStreams []probe.SubtitleStream
+	tags            probe.Tags
 	title           string
+	track           int
+	userMetadata    user_metadata.Metadata
 	uuid            string
 	videoStreams    []probe.VideoStream
+	year            int
+}
+
+// chapterJSON is a chapter of the media file in JSON form. HasThumbnail informs whether the thumbnail of the chapter
+// can be fetched from "/rest/media-files/{uuid}/chapters/{idx}/thumbnail" - which is the case for media files
+// with video or cover art.
+type chapterJSON struct {
+	probe.Chapter
+	HasThumbnail bool `json:"HasThumbnail"`
 }
 
 type entryJSON struct {
+	Added           time.Time              `json:"Added"`
+	Album           string                 `json:"Album"`
+	AlbumArtist     string                 `json:"AlbumArtist"`
+	Artist          string                 `json:"Artist"`
 	AudioStreams    []probe.AudioStream    `json:"AudioStreams"`
-	Chapters        []probe.Chapter        `json:"Chapters"`
+	BitRate         int64                  `json:"BitRate"`
+	Chapters        []chapterJSON          `json:"Chapters"`
+	Disc            int                    `json:"Disc"`
 	Duration        float64                `json:"Duration"`
+	Episode         int                    `json:"Episode"`
 	FormatName      string                 `json:"FormatName"`
 	FormatLongName  string                 `json:"FormatLongName"`
+	Metadata        sidecar.Metadata       `json:"Metadata"`
 	Path            string                 `json:"Path"`
+	Quality         string                 `json:"Quality"`
+	Season          int                    `json:"Season"`
+	Series          string                 `json:"Series"`
+	Size            int64                  `json:"Size"`
 	SubtitleStreams []probe.SubtitleStream `json:"SubtitleStreams"`
+	Tags            probe.Tags             `json:"Tags"`
 	Title           string                 `json:"Title"`
+	Track           int                    `json:"Track"`
+	UserMetadata    user_metadata.Metadata `json:"UserMetadata"`
 	UUID            string                 `json:"UUID"`
 	VideoStreams    []probe.VideoStream    `json:"VideoStreams"`
+	Year            int                    `json:"Year"`
 }
 
 // MarshalJSON satisifes json.Marshaller.
 func (m Entry) MarshalJSON() ([]byte, error) {
 	mJSON := entryJSON{
+		Added:           m.added,
+		Album:           m.album,
+		AlbumArtist:     m.albumArtist,
+		Artist:          m.artist,
+		Disc:            m.disc,
 		Title:           m.title,
 		FormatName:      m.formatName,
 		FormatLongName:  m.formatLongName,
-		Chapters:        m.chapters,
+		Chapters:        m.chaptersJSON(),
 		AudioStreams:    m.audioStreams,
+		BitRate:         m.bitRate,
 		Duration:        m.duration,
+		Episode:         m.episode,
+		Metadata:        m.metadata,
 		Path:            m.path,
+		Quality:         m.quality,
+		Season:          m.season,
+		Series:          m.series,
+		Size:            m.size,
 		SubtitleStreams: m.subtitleStreams,
+		Tags:            m.tags,
+		Track:           m.track,
+		UserMetadata:    m.userMetadata,
 		UUID:            m.uuid,
 		VideoStreams:    m.videoStreams,
+		Year:            m.year,
 	}
 
 	return json.Marshal(mJSON)
@@ -69,17 +132,92 @@ func (m *Entry) UnmarshalJSON(entry []byte) error {
 	m.title = unEntry.Title
 	m.formatName = unEntry.FormatName
 	m.formatLongName = unEntry.FormatLongName
-	m.chapters = unEntry.Chapters
+	m.chapters = []probe.Chapter{}
+	for _, chapter := range unEntry.Chapters {
+		m.chapters = append(m.chapters, chapter.Chapter)
+	}
 	m.audioStreams = unEntry.AudioStreams
+	m.bitRate = unEntry.BitRate
 	m.duration = unEntry.Duration
 	m.path = unEntry.Path
+	m.size = unEntry.Size
 	m.subtitleStreams = unEntry.SubtitleStreams
+	m.tags = unEntry.Tags
 	m.uuid = unEntry.UUID
 	m.videoStreams = unEntry.VideoStreams
+	m.episode = unEntry.Episode
+	m.metadata = unEntry.Metadata
+	m.quality = unEntry.Quality
+	m.season = unEntry.Season
+	m.series = unEntry.Series
+	m.year = unEntry.Year
+	m.album = unEntry.Album
+	m.albumArtist = unEntry.AlbumArtist
+	m.artist = unEntry.Artist
+	m.disc = unEntry.Disc
+	m.track = unEntry.Track
+	m.userMetadata = unEntry.UserMetadata
+	m.added = unEntry.Added
+
+	if m.album == "" && m.artist == "" {
+		m.applyAudioTags(m.tags) // entries saved before audio tags were read
+	}
+
+	if m.series == "" && m.year == 0 && m.quality == "" {
+		m.applyNameInfo(parseName(m.path, m.tags)) // entries saved before file names were parsed
+	}
 
 	return nil
 }
 
+func (m Entry) chaptersJSON() []chapterJSON {
+	hasThumbnail := len(m.videoStreams) > 0
+
+	chapters := []chapterJSON{}
+	for _, chapter := range m.chapters {
+		chapters = append(chapters, chapterJSON{
+			Chapter:      chapter,
+			HasThumbnail: hasThumbnail,
+		})
+	}
+
+	return chapters
+}
+
+// Chapters returns chapters of the mediaFile.
+func (m *Entry) Chapters() []probe.Chapter {
+	return m.chapters
+}
+
+// Duration returns mediaFile duration in seconds.
+func (m *Entry) Duration() float64 {
+	return m.duration
+}
+
+// VideoStreams returns video streams of the mediaFile.
+func (m *Entry) VideoStreams() []probe.VideoStream {
+	return m.videoStreams
+}
+
+// WithMetadata returns a copy of the mediaFile with metadata read from sidecar files.
+func (m Entry) WithMetadata(metadata sidecar.Metadata) Entry {
+	m.metadata = metadata
+
+	return m
+}
+
+// WithUserMetadata returns a copy of the mediaFile with metadata set by users.
+func (m Entry) WithUserMetadata(userMetadata user_metadata.Metadata) Entry {
+	m.userMetadata = userMetadata
+
+	return m
+}
+
+// UserMetadata returns metadata of the mediaFile set by users.
+func (m *Entry) UserMetadata() user_metadata.Metadata {
+	return m.userMetadata
+}
+
 // Path returns mediaFile path.
 func (m *Entry) Path() string {
 	return m.path
@@ -90,11 +228,105 @@ func (m *Entry) Uuid() string {
 	return m.uuid
 }
 
+// Added returns time when the media file was probed for the first time.
+// Zero time means that the media file was added before the time started being recorded.
+func (m *Entry) Added() time.Time {
+	return m.added
+}
+
+// Album returns name of the album that the media file is a track of.
+func (m *Entry) Album() string {
+	return m.album
+}
+
+// AlbumArtist returns artist credited for the whole album, falling back to the artist of the track.
+func (m *Entry) AlbumArtist() string {
+	if m.albumArtist != "" {
+		return m.albumArtist
+	}
+
+	return m.artist
+}
+
+// Artist returns artist performing the track.
+func (m *Entry) Artist() string {
+	return m.artist
+}
+
+// Disc returns number of the album disc containing the track, or 0 when unknown.
+func (m *Entry) Disc() int {
+	return m.disc
+}
+
+// Track returns number of the track on the album disc, or 0 when unknown.
+func (m *Entry) Track() int {
+	return m.track
+}
+
+// Episode returns number of the episode in the season of the series, or 0 when media file is not an episode.
+func (m *Entry) Episode() int {
+	return m.episode
+}
+
+// Season returns number of the season of the series that the media file is an episode of.
+func (m *Entry) Season() int {
+	return m.season
+}
+
+// Series returns name of the series that the media file is an episode of.
+func (m *Entry) Series() string {
+	return m.series
+}
+
+// Title returns title of the media file from it's metadata.
+func (m *Entry) Title() string {
+	return m.title
+}
+
+// displayTitle returns title of the media file or it's file name when the title is absent.
+func (m *Entry) displayTitle() string {
+	if m.title != "" {
+		return m.title
+	}
+
+	return filepath.Base(m.path)
+}
+
+// hasCoverArt checks whether the media file has a picture attached, eg. album cover in the audio file.
+func (m *Entry) hasCoverArt() bool {
+	for _, stream := range m.videoStreams {
+		if stream.Disposition.AttachedPic {
+			return true
+		}
+	}
+
+	return false
+}
+
+// height returns the highest vertical resolution among video streams of the media file.
+// Attached pictures (eg. album covers) are not considered to be videos.
+func (m *Entry) height() int {
+	var height int
+	for _, stream := range m.videoStreams {
+		if stream.Disposition.AttachedPic {
+			continue
+		}
+
+		height = max(height, stream.Height)
+	}
+
+	return height
+}
+
 // MapProbeResultToMediaFile constructs new MediaFile from results returned by probing for media files.
 func MapProbeResultToMediaFile(result probe.Result) Entry {
 	uuid := uuid.NewString()
 
-	return Entry{
+	mediaFile := Entry{
+		added:          time.Now(),
+		bitRate:        result.Format.BitRate,
+		size:           result.Format.Size,
+		tags:           result.Format.Tags,
 		title:          result.Format.Title,
 		formatName:     result.Format.Name,
 		formatLongName: result.Format.LongName,
@@ -120,4 +352,56 @@ func MapProbeResultToMediaFile(result probe.Result) Entry {
 		uuid:         uuid,
 		videoStreams: result.VideoStreams,
 	}
+	mediaFile.applyNameInfo(parseName(result.Path, result.Format.Tags))
+	mediaFile.applyAudioTags(result.Format.Tags)
+
+	return mediaFile
+}
+
+func (m *Entry) applyAudioTags(tags probe.Tags) {
+	m.album = tags.Album
+	m.albumArtist = tags.AlbumArtist
+	m.artist = tags.Artist
+	m.disc = parseIndexTag(tags.Disc)
+	m.track = parseIndexTag(tags.Track)
+}
+
+// parseIndexTag returns number of the track or disc, which tags commonly store along the total count, eg. "3/12".
+func parseIndexTag(value string) int {
+	number, _, _ := strings.Cut(value, "/")
+	index, err := strconv.Atoi(strings.TrimSpace(number))
+	if err != nil || index < 0 {
+		return 0
+	}
+
+	return index
+}
+
+func (m *Entry) applyNameInfo(nameInfo naming.Info) {
+	m.episode = nameInfo.Episode
+	m.quality = nameInfo.Quality
+	m.season = nameInfo.Season
+	m.series = nameInfo.Series
+	m.year = nameInfo.Year
+}
+
+// parseName deduces series, episode, year and quality from the file name.
+// Container tags are used for series information when the name does not follow any of known naming schemes.
+func parseName(path string, tags probe.Tags) naming.Info {
+	nameInfo := naming.Parse(path)
+	if nameInfo.IsEpisode() || tags.Show == "" {
+		return nameInfo
+	}
+
+	episode, err := strconv.Atoi(tags.Episode)
+	if err != nil || episode <= 0 {
+		return nameInfo
+	}
+
+	season, _ := strconv.Atoi(tags.Season)
+	nameInfo.Series = tags.Show
+	nameInfo.Season = season
+	nameInfo.Episode = episode
+
+	return nameInfo
 }
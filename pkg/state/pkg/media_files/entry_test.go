@@ -0,0 +1,71 @@
+package media_files
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+)
+
+func TestEntry_ChaptersHaveThumbnails(t *testing.T) {
+	// given
+	chapters := []probe.Chapter{{ChapterID: "0", StartTime: 0, EndTime: 30, Title: "Opening"}}
+	testCases := map[string]struct {
+		videoStreams []probe.VideoStream
+		expected     bool
+	}{
+		"video":      {[]probe.VideoStream{{Height: 1080}}, true},
+		"cover art":  {[]probe.VideoStream{{Disposition: probe.Disposition{AttachedPic: true}}}, true},
+		"audio only": {nil, false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			uut := Entry{path: "/media/a.mkv", chapters: chapters, videoStreams: testCase.videoStreams}
+
+			// when
+			out, err := json.Marshal(uut)
+			if err != nil {
+				t.Fatalf("Unexpected error while marshalling: %s", err)
+			}
+
+			var unmarshalled struct {
+				Chapters []struct {
+					HasThumbnail bool   `json:"HasThumbnail"`
+					Title        string `json:"Title"`
+				} `json:"Chapters"`
+			}
+			err = json.Unmarshal(out, &unmarshalled)
+			if err != nil {
+				t.Fatalf("Unexpected error while unmarshalling: %s", err)
+			}
+
+			// then
+			if len(unmarshalled.Chapters) != 1 || unmarshalled.Chapters[0].Title != "Opening" || unmarshalled.Chapters[0].HasThumbnail != testCase.expected {
+				t.Errorf("Expected chapter 'Opening' with thumbnail: %t, got %+v", testCase.expected, unmarshalled.Chapters)
+			}
+		})
+	}
+}
+
+func TestEntry_UnmarshalChapters(t *testing.T) {
+	// given
+	chapters := []probe.Chapter{{ChapterID: "1", StartTime: 30, EndTime: 60, Title: "Middle"}}
+	out, err := json.Marshal(Entry{path: "/media/a.mkv", chapters: chapters})
+	if err != nil {
+		t.Fatalf("Unexpected error while marshalling: %s", err)
+	}
+
+	// when
+	var uut Entry
+	err = json.Unmarshal(out, &uut)
+
+	// then
+	if err != nil {
+		t.Fatalf("Unexpected error while unmarshalling: %s", err)
+	}
+
+	if len(uut.Chapters()) != 1 || uut.Chapters()[0] != chapters[0] {
+		t.Errorf("Expected chapters %+v, got %+v", chapters, uut.Chapters())
+	}
+}
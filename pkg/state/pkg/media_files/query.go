@@ -0,0 +1,245 @@
+package media_files
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+// SortField specifies by which property media files are ordered in query results.
+type SortField string
+
+const (
+	// SortByPath orders media files by their path. Used when no other sort field is specified.
+	SortByPath SortField = "path"
+
+	// SortByTitle orders media files by their title, falling back to the file name when title is absent.
+	SortByTitle SortField = "title"
+
+	// SortByDuration orders media files by their duration.
+	SortByDuration SortField = "duration"
+
+	// SortBySize orders media files by their size on the disk.
+	SortBySize SortField = "size"
+)
+
+const (
+	formatNamesSeparator = ","
+)
+
+var (
+	// ErrInvalidSortField occurs when query specifies unknown sort field.
+	ErrInvalidSortField = errors.New("invalid sort field")
+
+	// ErrInvalidCursor occurs when provided cursor cannot be decoded.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Query describes criteria by which media files are selected and ordered.
+// Zero value of a field means that the criterium is not applied.
+type Query struct {
+	AddedSince        *time.Time `json:"AddedSince,omitempty"`
+	AudioLanguages    []string   `json:"AudioLanguages,omitempty"`
+	Directory         string     `json:"Directory,omitempty"`
+	Favourite         bool       `json:"Favourite,omitempty"`
+	Formats           []string   `json:"Formats,omitempty"`
+	MaxDuration       float64    `json:"MaxDuration,omitempty"`
+	MaxHeight         int        `json:"MaxHeight,omitempty"`
+	MinDuration       float64    `json:"MinDuration,omitempty"`
+	MinHeight         int        `json:"MinHeight,omitempty"`
+	MinRating         int        `json:"MinRating,omitempty"`
+	Search            string     `json:"Search,omitempty"`
+	SortBy            SortField  `json:"SortBy,omitempty"`
+	SortDescending    bool       `json:"SortDescending,omitempty"`
+	SubtitleLanguages []string   `json:"SubtitleLanguages,omitempty"`
+	Tags              []string   `json:"Tags,omitempty"`
+	Unwatched         bool       `json:"Unwatched,omitempty"`
+}
+
+// Validate checks whether query can be evaluated.
+func (q Query) Validate() error {
+	if q.MinRating < 0 || q.MinRating > user_metadata.MaxRating {
+		return user_metadata.ErrInvalidRating
+	}
+
+	switch q.SortBy {
+	case "", SortByPath, SortByTitle, SortByDuration, SortBySize:
+		return nil
+	default:
+		return ErrInvalidSortField
+	}
+}
+
+// Matches checks whether media file satisfies all criteria of the query.
+func (q Query) Matches(mediaFile Entry) bool {
+	if q.Directory != "" && !isUnderDirectory(mediaFile.path, q.Directory) {
+		return false
+	}
+
+	if q.MinDuration > 0 && mediaFile.duration < q.MinDuration {
+		return false
+	}
+
+	if q.MaxDuration > 0 && mediaFile.duration > q.MaxDuration {
+		return false
+	}
+
+	if q.MinHeight > 0 || q.MaxHeight > 0 {
+		height := mediaFile.height()
+		if height < q.MinHeight || (q.MaxHeight > 0 && height > q.MaxHeight) {
+			return false
+		}
+	}
+
+	if q.AddedSince != nil && (mediaFile.added.IsZero() || mediaFile.added.Before(*q.AddedSince)) {
+		return false
+	}
+
+	if q.Unwatched && mediaFile.userMetadata.Watched {
+		return false
+	}
+
+	if q.Favourite && !mediaFile.userMetadata.Favourite {
+		return false
+	}
+
+	if q.MinRating > 0 && mediaFile.userMetadata.Rating < q.MinRating {
+		return false
+	}
+
+	if len(q.Tags) > 0 && !mediaFile.userMetadata.HasTags(q.Tags) {
+		return false
+	}
+
+	if len(q.Formats) > 0 && !matchesFormat(mediaFile.formatName, q.Formats) {
+		return false
+	}
+
+	if len(q.AudioLanguages) > 0 && !slices.ContainsFunc(mediaFile.audioStreams, func(stream probe.AudioStream) bool {
+		return stream.AudioID != turnOffStreamId && containsFold(q.AudioLanguages, stream.Language)
+	}) {
+		return false
+	}
+
+	if len(q.SubtitleLanguages) > 0 && !slices.ContainsFunc(mediaFile.subtitleStreams, func(stream probe.SubtitleStream) bool {
+		return stream.SubtitleID != turnOffStreamId && containsFold(q.SubtitleLanguages, stream.Language)
+	}) {
+		return false
+	}
+
+	return q.matchesSearch(mediaFile)
+}
+
+// Compare returns the order of two media files according to the query sort criteria.
+// Path is used as a tie-breaker, which makes the order total.
+func (q Query) Compare(a, b Entry) int {
+	var result int
+	switch q.SortBy {
+	case SortByTitle:
+		result = cmp.Compare(strings.ToLower(a.displayTitle()), strings.ToLower(b.displayTitle()))
+	case SortByDuration:
+		result = cmp.Compare(a.duration, b.duration)
+	case SortBySize:
+		result = cmp.Compare(a.size, b.size)
+	}
+
+	if result == 0 {
+		result = cmp.Compare(a.path, b.path)
+	}
+
+	if q.SortDescending {
+		return -result
+	}
+
+	return result
+}
+
+func (q Query) matchesSearch(mediaFile Entry) bool {
+	title := strings.ToLower(mediaFile.title)
+	path := strings.ToLower(mediaFile.path)
+	for _, term := range strings.Fields(strings.ToLower(q.Search)) {
+		if !strings.Contains(title, term) && !strings.Contains(path, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Page is a part of the query results.
+type Page struct {
+	Items      []Entry
+	NextCursor string
+	Total      int
+}
+
+type cursorJSON struct {
+	Duration float64 `json:"d,omitempty"`
+	Path     string  `json:"p"`
+	Size     int64   `json:"s,omitempty"`
+	Title    string  `json:"t,omitempty"`
+}
+
+// encodeCursor returns an opaque cursor pointing after the provided media file.
+// Cursor holds values of sortable fields instead of a position, so it stays valid
+// when media files are added or removed between requests.
+func encodeCursor(mediaFile Entry) string {
+	cursor, _ := json.Marshal(cursorJSON{
+		Duration: mediaFile.duration,
+		Path:     mediaFile.path,
+		Size:     mediaFile.size,
+		Title:    mediaFile.displayTitle(),
+	})
+
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+func decodeCursor(cursor string) (Entry, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Entry{}, ErrInvalidCursor
+	}
+
+	var cursorEntry cursorJSON
+	err = json.Unmarshal(decoded, &cursorEntry)
+	if err != nil || cursorEntry.Path == "" {
+		return Entry{}, ErrInvalidCursor
+	}
+
+	return Entry{
+		duration: cursorEntry.Duration,
+		path:     cursorEntry.Path,
+		size:     cursorEntry.Size,
+		title:    cursorEntry.Title,
+	}, nil
+}
+
+func isUnderDirectory(path string, directory string) bool {
+	directory = strings.TrimSuffix(directory, string(filepath.Separator))
+
+	return strings.HasPrefix(path, directory+string(filepath.Separator))
+}
+
+func matchesFormat(formatName string, formats []string) bool {
+	for _, name := range strings.Split(formatName, formatNamesSeparator) {
+		if containsFold(formats, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
@@ -0,0 +1,202 @@
+package media_files
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+func TestQuery_PaginatesWithCursor(t *testing.T) {
+	// given
+	uut := NewStorage(common.NewChangesBroadcaster[Change]())
+	for _, mediaFile := range []Entry{
+		{path: "/media/c.mkv", duration: 30, uuid: "c"},
+		{path: "/media/a.mkv", duration: 10, uuid: "a"},
+		{path: "/media/b.mkv", duration: 20, uuid: "b"},
+		{path: "/media/d.mkv", duration: 40, uuid: "d"},
+		{path: "/other/e.mkv", duration: 50, uuid: "e"},
+	} {
+		uut.items[mediaFile.path] = mediaFile
+	}
+	query := Query{Directory: "/media", SortBy: SortByDuration, SortDescending: true}
+
+	// when
+	firstPage, err := uut.Query(query, "", 3)
+	if err != nil {
+		t.Fatalf("Unexpected error for the first page: %s", err)
+	}
+
+	delete(uut.items, "/media/b.mkv")
+	secondPage, err := uut.Query(query, firstPage.NextCursor, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error for the second page: %s", err)
+	}
+
+	// then
+	expectUuids(t, firstPage.Items, []string{"d", "c", "b"})
+	if firstPage.Total != 4 || firstPage.NextCursor == "" {
+		t.Errorf("Expected total of 4 and a cursor, got %d and '%s'", firstPage.Total, firstPage.NextCursor)
+	}
+
+	expectUuids(t, secondPage.Items, []string{"a"})
+	if secondPage.NextCursor != "" {
+		t.Errorf("Expected no cursor for the last page, got '%s'", secondPage.NextCursor)
+	}
+}
+
+func TestQuery_Matches(t *testing.T) {
+	// given
+	mediaFile := Entry{
+		path:         "/media/Show/Show.S01E01.mkv",
+		title:        "Pilot",
+		formatName:   "matroska,webm",
+		duration:     1500,
+		audioStreams: []probe.AudioStream{{AudioID: "1", Language: "jpn"}, {AudioID: turnOffStreamId, Language: turnOffStreamLanguage}},
+		videoStreams: []probe.VideoStream{{Height: 1080}},
+	}
+
+	testCases := map[string]struct {
+		query    Query
+		expected bool
+	}{
+		"search in title and path":   {Query{Search: "show pilot"}, true},
+		"search not matching":        {Query{Search: "movie"}, false},
+		"format name from the list":  {Query{Formats: []string{"webm"}}, true},
+		"audio language":             {Query{AudioLanguages: []string{"JPN"}}, true},
+		"turned off audio language":  {Query{AudioLanguages: []string{turnOffStreamLanguage}}, false},
+		"height in range":            {Query{MinHeight: 720, MaxHeight: 1080}, true},
+		"height out of range":        {Query{MinHeight: 2160}, false},
+		"duration out of range":      {Query{MaxDuration: 1200}, false},
+		"directory prefix not split": {Query{Directory: "/media/Sho"}, false},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result := testCase.query.Matches(mediaFile)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected match to be %t, got %t", testCase.expected, result)
+			}
+		})
+	}
+}
+
+func TestQuery_MatchesHeightIgnoringCoverArt(t *testing.T) {
+	// given
+	coverArt := probe.VideoStream{Disposition: probe.Disposition{AttachedPic: true}, Height: 1000}
+	audioFile := Entry{
+		path:         "/media/Album/01 Track.mp3",
+		videoStreams: []probe.VideoStream{coverArt},
+	}
+	videoFile := Entry{
+		path:         "/media/Show/Show.S01E01.mkv",
+		videoStreams: []probe.VideoStream{coverArt, {Height: 480}},
+	}
+
+	testCases := map[string]struct {
+		mediaFile Entry
+		query     Query
+		expected  bool
+	}{
+		"audio with cover art and minimum height":  {audioFile, Query{MinHeight: 720}, false},
+		"audio with cover art and maximum height":  {audioFile, Query{MaxHeight: 720}, true},
+		"video with cover art and minimum height":  {videoFile, Query{MinHeight: 720}, false},
+		"video with cover art and height in range": {videoFile, Query{MinHeight: 480, MaxHeight: 720}, true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result := testCase.query.Matches(testCase.mediaFile)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected match to be %t, got %t", testCase.expected, result)
+			}
+		})
+	}
+}
+
+func TestQuery_MatchesUserMetadata(t *testing.T) {
+	// given
+	mediaFile := Entry{
+		path: "/media/Movie (2019).mkv",
+		userMetadata: user_metadata.Metadata{
+			Favourite: true,
+			Rating:    3,
+			Tags:      []string{"Comfort", "rewatch"},
+		},
+	}
+	unratedMediaFile := Entry{
+		path: "/media/Other.mkv",
+	}
+
+	testCases := map[string]struct {
+		mediaFile Entry
+		query     Query
+		expected  bool
+	}{
+		"favourite":                   {mediaFile, Query{Favourite: true}, true},
+		"not favourite":               {unratedMediaFile, Query{Favourite: true}, false},
+		"rating at minimum":           {mediaFile, Query{MinRating: 3}, true},
+		"rating below minimum":        {mediaFile, Query{MinRating: 4}, false},
+		"unrated with minimum":        {unratedMediaFile, Query{MinRating: 1}, false},
+		"all tags case-insensitively": {mediaFile, Query{Tags: []string{"comfort", "REWATCH"}}, true},
+		"missing one of tags":         {mediaFile, Query{Tags: []string{"comfort", "horror"}}, false},
+		"untagged with tags":          {unratedMediaFile, Query{Tags: []string{"comfort"}}, false},
+		"no user metadata criteria":   {unratedMediaFile, Query{}, true},
+		"all user metadata criteria":  {mediaFile, Query{Favourite: true, MinRating: 2, Tags: []string{"comfort"}}, true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// when
+			result := testCase.query.Matches(testCase.mediaFile)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected match to be %t, got %t", testCase.expected, result)
+			}
+		})
+	}
+}
+
+func TestQuery_AddedSince(t *testing.T) {
+	// given
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	query := Query{AddedSince: &since}
+
+	// when
+	matchesNewer := query.Matches(Entry{path: "/media/a.mkv", added: since.Add(time.Hour)})
+	matchesOlder := query.Matches(Entry{path: "/media/b.mkv", added: since.Add(-time.Hour)})
+	marshalledEmpty, err := json.Marshal(Query{})
+
+	// then
+	if !matchesNewer || matchesOlder {
+		t.Errorf("Expected only media file added after %s to match, got newer: %t, older: %t", since, matchesNewer, matchesOlder)
+	}
+
+	if err != nil || string(marshalledEmpty) != "{}" {
+		t.Errorf("Expected empty query to be marshalled without criteria, got '%s' (error: %v)", marshalledEmpty, err)
+	}
+}
+
+func expectUuids(t *testing.T, mediaFiles []Entry, expected []string) {
+	t.Helper()
+
+	if len(mediaFiles) != len(expected) {
+		t.Fatalf("Expected %d media files, got %d", len(expected), len(mediaFiles))
+	}
+
+	for idx, mediaFile := range mediaFiles {
+		if mediaFile.uuid != expected[idx] {
+			t.Errorf("Expected media file %d to have uuid '%s', got '%s'", idx, expected[idx], mediaFile.uuid)
+		}
+	}
+}
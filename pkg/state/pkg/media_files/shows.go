@@ -0,0 +1,153 @@
+package media_files
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrShowNotFound occurs when there are no media files being episodes of a show with provided id.
+	ErrShowNotFound = errors.New("show not found")
+)
+
+// Show groups media files being episodes of the same series.
+type Show struct {
+	Episodes int    `json:"Episodes"`
+	ID       string `json:"ID"`
+	Name     string `json:"Name"`
+	Seasons  []int  `json:"Seasons"`
+}
+
+// Season groups episodes of the show by season number.
+type Season struct {
+	Episodes []Entry `json:"Episodes"`
+	Number   int     `json:"Number"`
+}
+
+// ShowID returns identifier of the show with provided series name.
+// Names differing only in letter case and punctuation result in the same identifier.
+func ShowID(series string) string {
+	return slug(series)
+}
+
+// slug returns lower case alphanumeric words of the name joined with dashes.
+func slug(name string) string {
+	var id strings.Builder
+	separated := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if separated && id.Len() > 0 {
+				id.WriteRune('-')
+			}
+
+			id.WriteRune(r)
+			separated = false
+		} else {
+			separated = true
+		}
+	}
+
+	return id.String()
+}
+
+// Shows returns all shows that served media files are episodes of, ordered by their names.
+func (m *Storage) Shows() []Show {
+	shows := map[string]*Show{}
+	seasons := map[string]map[int]bool{}
+
+	for _, mediaFile := range m.episodesByPath() {
+		id := ShowID(mediaFile.series)
+		show, ok := shows[id]
+		if !ok {
+			show = &Show{
+				ID:      id,
+				Name:    mediaFile.series,
+				Seasons: []int{},
+			}
+			shows[id] = show
+			seasons[id] = map[int]bool{}
+		}
+
+		show.Episodes++
+		if !seasons[id][mediaFile.season] {
+			seasons[id][mediaFile.season] = true
+			show.Seasons = append(show.Seasons, mediaFile.season)
+		}
+	}
+
+	result := []Show{}
+	for _, show := range shows {
+		slices.Sort(show.Seasons)
+		result = append(result, *show)
+	}
+
+	slices.SortFunc(result, func(a, b Show) int {
+		if byName := cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)); byName != 0 {
+			return byName
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result
+}
+
+// ShowSeasons returns seasons of the show with provided id, with episodes ordered by their numbers.
+// When the show cannot be found, the error is being reported.
+func (m *Storage) ShowSeasons(id string) ([]Season, error) {
+	seasons := map[int]*Season{}
+	for _, mediaFile := range m.episodesByPath() {
+		if ShowID(mediaFile.series) != id {
+			continue
+		}
+
+		season, ok := seasons[mediaFile.season]
+		if !ok {
+			season = &Season{
+				Number: mediaFile.season,
+			}
+			seasons[mediaFile.season] = season
+		}
+
+		season.Episodes = append(season.Episodes, mediaFile)
+	}
+
+	if len(seasons) == 0 {
+		return nil, ErrShowNotFound
+	}
+
+	var result []Season
+	for _, season := range seasons {
+		slices.SortStableFunc(season.Episodes, func(a, b Entry) int {
+			return cmp.Compare(a.episode, b.episode)
+		})
+		result = append(result, *season)
+	}
+
+	slices.SortFunc(result, func(a, b Season) int {
+		return cmp.Compare(a.Number, b.Number)
+	})
+
+	return result, nil
+}
+
+// episodesByPath returns media files that are episodes of a series, ordered by their paths.
+func (m *Storage) episodesByPath() []Entry {
+	m.lock.RLock()
+	var episodes []Entry
+	for _, mediaFile := range m.items {
+		if mediaFile.series != "" && mediaFile.episode > 0 {
+			episodes = append(episodes, mediaFile)
+		}
+	}
+	m.lock.RUnlock()
+
+	slices.SortFunc(episodes, func(a, b Entry) int {
+		return cmp.Compare(a.path, b.path)
+	})
+
+	return episodes
+}
@@ -3,6 +3,7 @@ package media_files
 import (
 	"encoding/json"
 	"errors"
+	"slices"
 	"strings"
 	"sync"
 
@@ -165,6 +166,126 @@ func (m *Storage) Exists(path string) bool {
 	return err == nil
 }
 
+// Query returns a page of media files matching the query, ordered according to it's sort criteria.
+// When cursor is provided, the page starts after the media file the cursor was created for.
+// When limit is not positive, all remaining matching media files are returned.
+func (m *Storage) Query(query Query, cursor string, limit int) (Page, error) {
+	err := query.Validate()
+	if err != nil {
+		return Page{}, err
+	}
+
+	var after *Entry
+	if cursor != "" {
+		cursorEntry, err := decodeCursor(cursor)
+		if err != nil {
+			return Page{}, err
+		}
+
+		after = &cursorEntry
+	}
+
+	var matching []Entry
+	m.lock.RLock()
+	for _, mediaFile := range m.items {
+		if query.Matches(mediaFile) {
+			matching = append(matching, mediaFile)
+		}
+	}
+	m.lock.RUnlock()
+
+	slices.SortFunc(matching, query.Compare)
+
+	page := Page{
+		Total: len(matching),
+	}
+
+	start := 0
+	if after != nil {
+		start, _ = slices.BinarySearchFunc(matching, *after, query.Compare)
+		if start < len(matching) && matching[start].path == after.path {
+			start++
+		}
+	}
+
+	end := len(matching)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+		page.NextCursor = encodeCursor(matching[end-1])
+	}
+
+	page.Items = matching[start:end]
+
+	return page, nil
+}
+
+// Update replaces already served media file with a provided one, matching them by path.
+// UUID and time of addition of the served media file are preserved, so clients can keep referencing it.
+// When media file cannot be found, the error is being reported.
+func (m *Storage) Update(mediaFile Entry) error {
+	m.lock.Lock()
+	prevMediaFile, ok := m.items[mediaFile.path]
+	if !ok {
+		m.lock.Unlock()
+
+		return errNoMediaFileAvailable
+	}
+
+	mediaFile.uuid = prevMediaFile.uuid
+	if !prevMediaFile.added.IsZero() {
+		mediaFile.added = prevMediaFile.added
+	}
+	m.items[mediaFile.path] = mediaFile
+	m.lock.Unlock()
+
+	m.revision.Tick()
+	m.broadcaster.Send(Change{
+		ChangeVariant: UpdatedMediaFilesChange,
+		Items: map[string]Entry{
+			mediaFile.path: mediaFile,
+		},
+	})
+
+	return nil
+}
+
+// Modify replaces already served media file under the path with the result of modify, which is called with the served media file
+// under the lock of the storage - so concurrent modifications of the same media file do not overwrite each other.
+// UUID and time of addition of the served media file are preserved.
+// When media file cannot be found or modify fails, the error is being reported and the media file is left unchanged.
+func (m *Storage) Modify(path string, modify func(mediaFile Entry) (Entry, error)) (Entry, error) {
+	m.lock.Lock()
+	prevMediaFile, ok := m.items[path]
+	if !ok {
+		m.lock.Unlock()
+
+		return Entry{}, errNoMediaFileAvailable
+	}
+
+	mediaFile, err := modify(prevMediaFile)
+	if err != nil {
+		m.lock.Unlock()
+
+		return prevMediaFile, err
+	}
+
+	mediaFile.path = prevMediaFile.path
+	mediaFile.uuid = prevMediaFile.uuid
+	mediaFile.added = prevMediaFile.added
+	m.items[path] = mediaFile
+	m.lock.Unlock()
+
+	m.revision.Tick()
+	m.broadcaster.Send(Change{
+		ChangeVariant: UpdatedMediaFilesChange,
+		Items: map[string]Entry{
+			path: mediaFile,
+		},
+	})
+
+	return mediaFile, nil
+}
+
 // PathsUnderParent returns paths of media files under provided parent
 // (path to directory).
 func (m *Storage) PathsUnderParent(parentPath string) []string {
@@ -0,0 +1,65 @@
+package media_files
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+)
+
+func TestStorage_ModifyKeepsConcurrentModifications(t *testing.T) {
+	// given
+	broadcaster := common.NewChangesBroadcaster[Change]()
+	broadcaster.Broadcast()
+	uut := NewStorage(broadcaster)
+	uut.items["/media/a.mkv"] = Entry{path: "/media/a.mkv", uuid: "a"}
+
+	modifications := 50
+	wg := sync.WaitGroup{}
+	wg.Add(modifications)
+
+	// when
+	for i := 0; i < modifications; i++ {
+		go func() {
+			defer wg.Done()
+
+			_, err := uut.Modify("/media/a.mkv", func(mediaFile Entry) (Entry, error) {
+				metadata := mediaFile.UserMetadata()
+				metadata.Tags = append(append([]string{}, metadata.Tags...), "tag")
+
+				return mediaFile.WithUserMetadata(metadata), nil
+			})
+			if err != nil {
+				t.Errorf("Unexpected error while modifying: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// then
+	mediaFile, err := uut.ByPath("/media/a.mkv")
+	if err != nil {
+		t.Fatalf("Unexpected error while getting media file: %s", err)
+	}
+
+	if len(mediaFile.UserMetadata().Tags) != modifications || mediaFile.uuid != "a" {
+		t.Errorf("Expected %d tags and preserved uuid, got %d tags and uuid '%s'", modifications, len(mediaFile.UserMetadata().Tags), mediaFile.uuid)
+	}
+}
+
+func TestStorage_ModifyMissingMediaFile(t *testing.T) {
+	// given
+	uut := NewStorage(common.NewChangesBroadcaster[Change]())
+
+	// when
+	_, err := uut.Modify("/media/missing.mkv", func(mediaFile Entry) (Entry, error) {
+		t.Errorf("Expected modify to not be called for missing media file")
+
+		return mediaFile, nil
+	})
+
+	// then
+	if err == nil {
+		t.Errorf("Expected error for missing media file")
+	}
+}
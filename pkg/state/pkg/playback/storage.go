@@ -132,6 +132,11 @@ func (p *Storage) Clear() {
 	}
 }
 
+// CurrentTime returns current time of a playback, in seconds.
+func (p *Storage) CurrentTime() float64 {
+	return p.currentTime
+}
+
 func (p *Storage) LoopFile() bool {
 	return p.loop.variant == fileLoop
 }
@@ -266,6 +271,7 @@ func (p *Storage) SetPlaybackTime(time float64) {
 	p.revision.Tick()
 	p.broadcaster.Send(Change{
 		ChangeVariant: PlaybackTimeChange,
+		Value:         time,
 	})
 }
 
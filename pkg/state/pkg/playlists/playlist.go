@@ -26,17 +26,19 @@ type Playlist struct {
 	lock                       *sync.RWMutex
 	path                       string
 	origin                     PlaylistOrigin
+	query                      *SmartQuery
 	uuid                       string
 }
 
 type playlistJSON struct {
-	CurrentEntryIdx            int     `json:"CurrentEntryIdx"`
-	Description                string  `json:"Description"`
-	DirectoryContentsAsEntries bool    `json:"DirectoryContentsAsEntries"`
-	Entries                    []Entry `json:"Entries"`
-	Name                       string  `json:"Name"`
-	Path                       string  `json:"Path"`
-	UUID                       string  `json:"UUID"`
+	CurrentEntryIdx            int         `json:"CurrentEntryIdx"`
+	Description                string      `json:"Description"`
+	DirectoryContentsAsEntries bool        `json:"DirectoryContentsAsEntries"`
+	Entries                    []Entry     `json:"Entries"`
+	Name                       string      `json:"Name"`
+	Path                       string      `json:"Path"`
+	Query                      *SmartQuery `json:"Query,omitempty"`
+	UUID                       string      `json:"UUID"`
 }
 
 type Config struct {
@@ -47,6 +49,8 @@ type Config struct {
 	Name                       string
 	Origin                     PlaylistOrigin
 	Path                       string
+	// Query makes the playlist a smart playlist, which entries are selected from served media files.
+	Query *SmartQuery
 }
 
 // NewPlaylist constructs Playlist state.
@@ -60,6 +64,7 @@ func NewPlaylist(cfg Config) *Playlist {
 		lock:                       &sync.RWMutex{},
 		path:                       cfg.Path,
 		origin:                     cfg.Origin,
+		query:                      cfg.Query,
 		uuid:                       uuid.NewString(),
 	}
 }
@@ -72,6 +77,11 @@ func (p *Playlist) All() []Entry {
 	return slices.Clone(p.entries)
 }
 
+// Query returns query selecting entries of the smart playlist, or nil when the playlist is not a smart one.
+func (p *Playlist) Query() *SmartQuery {
+	return p.query
+}
+
 func (p *Playlist) DirectoryContentsAsEntries() bool {
 	return p.directoryContentsAsEntries
 }
@@ -115,6 +125,7 @@ func (p *Playlist) MarshalJSON() ([]byte, error) {
 		Name:                       p.name,
 		UUID:                       p.uuid,
 		Path:                       p.path,
+		Query:                      p.query,
 	}
 	p.lock.Unlock()
 
@@ -139,6 +150,7 @@ func (p *Playlist) UnmarshalJSON(entry []byte) error {
 	p.name = unEntry.Name
 	p.uuid = unEntry.UUID
 	p.path = unEntry.Path
+	p.query = unEntry.Query
 
 	return nil
 }
@@ -0,0 +1,67 @@
+package playlists
+
+import (
+	"math/rand"
+
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+)
+
+// SmartQuery describes how entries of a smart playlist are selected from media files being served.
+// It extends media files query with options applied to the matching media files.
+type SmartQuery struct {
+	media_files.Query
+	// Limit is a maximum number of entries in the playlist. Zero means no limit.
+	Limit int `json:"Limit,omitempty"`
+	// Random selects entries in random order instead of the query sort order.
+	Random bool `json:"Random,omitempty"`
+}
+
+// SelectEntries returns entries of the playlist for media files matching the query, ordered according to the query.
+// When random selection is enabled, current entries that still match the query are kept in their place,
+// so re-evaluation after changes to media files does not shuffle the playlist that might be playing.
+func (q SmartQuery) SelectEntries(current []Entry, matching []media_files.Entry) []Entry {
+	if !q.Random {
+		var entries []Entry
+		for _, mediaFile := range matching {
+			if q.Limit > 0 && len(entries) == q.Limit {
+				break
+			}
+
+			entries = append(entries, Entry{Path: mediaFile.Path()})
+		}
+
+		return entries
+	}
+
+	var remaining []string
+	matchingPaths := map[string]bool{}
+	for _, mediaFile := range matching {
+		matchingPaths[mediaFile.Path()] = true
+	}
+
+	var entries []Entry
+	for _, entry := range current {
+		if matchingPaths[entry.Path] && (q.Limit <= 0 || len(entries) < q.Limit) {
+			entries = append(entries, entry)
+			delete(matchingPaths, entry.Path)
+		}
+	}
+
+	for path := range matchingPaths {
+		remaining = append(remaining, path)
+	}
+
+	rand.Shuffle(len(remaining), func(i, j int) {
+		remaining[i], remaining[j] = remaining[j], remaining[i]
+	})
+
+	for _, path := range remaining {
+		if q.Limit > 0 && len(entries) == q.Limit {
+			break
+		}
+
+		entries = append(entries, Entry{Path: path})
+	}
+
+	return entries
+}
@@ -0,0 +1,53 @@
+package playlists_test
+
+import (
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playlists"
+)
+
+func mediaFiles(paths ...string) []media_files.Entry {
+	var entries []media_files.Entry
+	for _, path := range paths {
+		entries = append(entries, media_files.MapProbeResultToMediaFile(probe.Result{Path: path}))
+	}
+
+	return entries
+}
+
+func TestSmartQuerySelectEntriesLimitsMatchingMediaFiles(t *testing.T) {
+	// given
+	uut := playlists.SmartQuery{Limit: 2}
+
+	// when
+	entries := uut.SelectEntries(nil, mediaFiles("/a.mkv", "/b.mkv", "/c.mkv"))
+
+	// then
+	if len(entries) != 2 || entries[0].Path != "/a.mkv" || entries[1].Path != "/b.mkv" {
+		t.Errorf("Unexpected entries selected: %+v", entries)
+	}
+}
+
+func TestSmartQuerySelectEntriesKeepsRandomEntriesStillMatching(t *testing.T) {
+	// given
+	uut := playlists.SmartQuery{Limit: 3, Random: true}
+	current := []playlists.Entry{{Path: "/removed.mkv"}, {Path: "/b.mkv"}, {Path: "/a.mkv"}}
+
+	// when
+	entries := uut.SelectEntries(current, mediaFiles("/a.mkv", "/b.mkv", "/c.mkv", "/d.mkv"))
+
+	// then
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", entries)
+	}
+
+	if entries[0].Path != "/b.mkv" || entries[1].Path != "/a.mkv" {
+		t.Errorf("Expected entries still matching to be kept in their order, got %+v", entries)
+	}
+
+	if entries[2].Path != "/c.mkv" && entries[2].Path != "/d.mkv" {
+		t.Errorf("Expected the last entry to be selected from new matching media files, got %s", entries[2].Path)
+	}
+}
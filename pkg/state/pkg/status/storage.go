@@ -3,6 +3,7 @@ package status
 import (
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/sarpt/mpv-web-api/internal/common"
 	"github.com/sarpt/mpv-web-api/pkg/state/internal/revision"
@@ -32,7 +33,26 @@ const (
 
 // storageJSON is a status information in JSON form.
 type storageJSON struct {
-	ObservingAddresses map[string][]sse.ChannelVariant `json:"ObservingAddresses"`
+	Connections        map[string]Connection                 `json:"Connections"`
+	ObserversDrops     map[sse.ChannelVariant]ObserversDrops `json:"ObserversDrops"`
+	ObservingAddresses map[string][]sse.ChannelVariant       `json:"ObservingAddresses"`
+}
+
+// Connection holds information about a client connected to the SSE or WebSocket channels.
+// Client name is optionally provided by the client to distinguish its connections from others.
+type Connection struct {
+	Channels    []sse.ChannelVariant `json:"Channels"`
+	ClientName  string               `json:"ClientName"`
+	ConnectedAt time.Time            `json:"ConnectedAt"`
+	RemoteAddr  string               `json:"RemoteAddr"`
+	UserAgent   string               `json:"UserAgent"`
+}
+
+// ObserversDrops holds numbers of changes that were not delivered to observers of a channel,
+// because observers did not receive them fast enough.
+type ObserversDrops struct {
+	DisconnectedObservers uint64 `json:"DisconnectedObservers"`
+	DroppedChanges        uint64 `json:"DroppedChanges"`
 }
 
 // Change holds information about changes to the server misc status.
@@ -51,42 +71,56 @@ func (d Change) Variant() common.ChangeVariant {
 
 // Storage holds information about server misc status.
 type Storage struct {
-	broadcaster        *common.ChangesBroadcaster[Change]
-	lock               *sync.RWMutex
-	observingAddresses map[string][]sse.ChannelVariant
-	revision           *revision.Storage
+	broadcaster    *common.ChangesBroadcaster[Change]
+	connections    map[string]Connection
+	lock           *sync.RWMutex
+	observersDrops map[sse.ChannelVariant]ObserversDrops
+	revision       *revision.Storage
 }
 
 // NewStorage constructs Status state.
 func NewStorage(broadcaster *common.ChangesBroadcaster[Change]) *Storage {
 	return &Storage{
-		broadcaster:        broadcaster,
-		lock:               &sync.RWMutex{},
-		observingAddresses: map[string][]sse.ChannelVariant{},
-		revision:           revision.NewStorage(),
+		broadcaster:    broadcaster,
+		connections:    map[string]Connection{},
+		lock:           &sync.RWMutex{},
+		observersDrops: map[sse.ChannelVariant]ObserversDrops{},
+		revision:       revision.NewStorage(),
 	}
 }
 
-// ObservingAddresses returns a mapping of a remote address to the channel variants.
+// ObservingAddresses returns a mapping of a remote address to the channel variants observed by all connections from the address.
 func (s *Storage) ObservingAddresses() map[string][]sse.ChannelVariant {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
-	return s.observingAddresses
+	return s.observingAddresses()
 }
 
-// AddObservingAddress adds remote address listening on specific channel variant to the status state.
-func (s *Storage) AddObservingAddress(remoteAddr string, observerVariant sse.ChannelVariant) {
-	var observers []sse.ChannelVariant
-	var ok bool
+// Connections returns a mapping of a connection id to the information about the connection.
+func (s *Storage) Connections() map[string]Connection {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	connections := map[string]Connection{}
+	for id, connection := range s.connections {
+		connections[id] = connection
+	}
+
+	return connections
+}
 
+// AddObservingConnection adds connection listening on specific channel variant to the status state.
+// When the connection already observes other channels, only the channel variant is added.
+func (s *Storage) AddObservingConnection(id string, connection Connection, observerVariant sse.ChannelVariant) {
 	s.lock.Lock()
-	observers, ok = s.observingAddresses[remoteAddr]
-	if !ok {
-		observers = []sse.ChannelVariant{}
+	existing, ok := s.connections[id]
+	if ok {
+		connection = existing
 	}
 
-	s.observingAddresses[remoteAddr] = append(observers, observerVariant)
+	connection.Channels = append(append([]sse.ChannelVariant{}, connection.Channels...), observerVariant)
+	s.connections[id] = connection
 	s.lock.Unlock()
 
 	s.revision.Tick()
@@ -95,13 +129,44 @@ func (s *Storage) AddObservingAddress(remoteAddr string, observerVariant sse.Cha
 	})
 }
 
+// AddObserversDrops adds changes not delivered to an observer of the channel variant to the status state.
+// The drops are not broadcasted as a change, since they may happen on status channel itself - a slow observer
+// would cause more changes to be dropped. They are visible the next time the status state is sent.
+func (s *Storage) AddObserversDrops(channelVariant sse.ChannelVariant, droppedChanges int, disconnected bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	drops := s.observersDrops[channelVariant]
+	drops.DroppedChanges += uint64(droppedChanges)
+	if disconnected {
+		drops.DisconnectedObservers++
+	}
+
+	s.observersDrops[channelVariant] = drops
+}
+
+// ObserversDrops returns a mapping of a channel variant to the numbers of changes not delivered to its observers.
+func (s *Storage) ObserversDrops() map[sse.ChannelVariant]ObserversDrops {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	drops := map[sse.ChannelVariant]ObserversDrops{}
+	for channelVariant, channelDrops := range s.observersDrops {
+		drops[channelVariant] = channelDrops
+	}
+
+	return drops
+}
+
 // MarshalJSON satisfies json.Marshaller.
 func (s *Storage) MarshalJSON() ([]byte, error) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
 	sJSON := storageJSON{
-		ObservingAddresses: s.observingAddresses,
+		Connections:        s.connections,
+		ObserversDrops:     s.observersDrops,
+		ObservingAddresses: s.observingAddresses(),
 	}
 	return json.Marshal(&sJSON)
 }
@@ -110,31 +175,30 @@ func (s *Storage) Revision() revision.Identifier {
 	return s.revision.Revision()
 }
 
-// RemoveObservingAddress removes remote address listening on specific channel variant from the state.
-func (s *Storage) RemoveObservingAddress(remoteAddr string, observerVariant sse.ChannelVariant) {
-	var observers []sse.ChannelVariant
-	var ok bool
-
+// RemoveObservingConnection removes connection listening on specific channel variant from the state.
+// The connection is removed when it does not observe any other channel.
+func (s *Storage) RemoveObservingConnection(id string, observerVariant sse.ChannelVariant) {
 	s.lock.Lock()
-
-	observers, ok = s.observingAddresses[remoteAddr]
+	connection, ok := s.connections[id]
 	if !ok {
+		s.lock.Unlock()
+
 		return
 	}
 
 	filteredObservers := []sse.ChannelVariant{}
-	for _, observer := range observers {
+	for _, observer := range connection.Channels {
 		if observer != observerVariant {
 			filteredObservers = append(filteredObservers, observer)
 		}
 	}
 
 	if len(filteredObservers) == 0 {
-		delete(s.observingAddresses, remoteAddr)
+		delete(s.connections, id)
 	} else {
-		s.observingAddresses[remoteAddr] = filteredObservers
+		connection.Channels = filteredObservers
+		s.connections[id] = connection
 	}
-
 	s.lock.Unlock()
 
 	s.revision.Tick()
@@ -143,6 +207,15 @@ func (s *Storage) RemoveObservingAddress(remoteAddr string, observerVariant sse.
 	})
 }
 
+func (s *Storage) observingAddresses() map[string][]sse.ChannelVariant {
+	addresses := map[string][]sse.ChannelVariant{}
+	for _, connection := range s.connections {
+		addresses[connection.RemoteAddr] = append(addresses[connection.RemoteAddr], connection.Channels...)
+	}
+
+	return addresses
+}
+
 func (p *Storage) Subscribe(cb SubscriberCB, onError func(err error)) func() {
 	subscriber := statusChangeSubscriber{
 		cb,
@@ -0,0 +1,111 @@
+package status
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/internal/common"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/sse"
+)
+
+func newTestStorage() *Storage {
+	broadcaster := common.NewChangesBroadcaster[Change]()
+	broadcaster.Broadcast()
+
+	return NewStorage(broadcaster)
+}
+
+func sortedChannels(channels []sse.ChannelVariant) []sse.ChannelVariant {
+	sorted := append([]sse.ChannelVariant{}, channels...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return sorted
+}
+
+func expectChannels(t *testing.T, name string, channels []sse.ChannelVariant, expected []sse.ChannelVariant) {
+	t.Helper()
+
+	sorted := sortedChannels(channels)
+	if len(sorted) != len(expected) {
+		t.Fatalf("Expected %s to have channels %v, got %v", name, expected, sorted)
+	}
+
+	for idx := range expected {
+		if sorted[idx] != expected[idx] {
+			t.Errorf("Expected %s to have channels %v, got %v", name, expected, sorted)
+		}
+	}
+}
+
+func TestStorage_AddObservingConnection(t *testing.T) {
+	// given
+	uut := newTestStorage()
+	connection := Connection{ClientName: "tab", RemoteAddr: "192.168.1.2"}
+
+	// when
+	uut.AddObservingConnection("1", connection, "playback")
+	uut.AddObservingConnection("1", Connection{ClientName: "ignored"}, "status")
+
+	// then
+	connections := uut.Connections()
+	if len(connections) != 1 {
+		t.Fatalf("Expected a single connection, got %d", len(connections))
+	}
+
+	if connections["1"].ClientName != "tab" {
+		t.Errorf("Expected connection to keep information provided when it was added, got %+v", connections["1"])
+	}
+
+	expectChannels(t, "connection", connections["1"].Channels, []sse.ChannelVariant{"playback", "status"})
+}
+
+func TestStorage_ConnectionsFromTheSameAddress(t *testing.T) {
+	// given
+	uut := newTestStorage()
+	uut.AddObservingConnection("1", Connection{ClientName: "phone", RemoteAddr: "192.168.1.2"}, "playback")
+	uut.AddObservingConnection("2", Connection{ClientName: "tablet", RemoteAddr: "192.168.1.2"}, "playlists")
+	uut.AddObservingConnection("2", Connection{}, "status")
+	uut.AddObservingConnection("3", Connection{RemoteAddr: "192.168.1.3"}, "logs")
+
+	// when
+	uut.RemoveObservingConnection("2", "playlists")
+	uut.RemoveObservingConnection("3", "logs")
+
+	// then
+	connections := uut.Connections()
+	if len(connections) != 2 {
+		t.Fatalf("Expected connections '1' and '2' to remain, got %+v", connections)
+	}
+
+	if _, ok := connections["3"]; ok {
+		t.Errorf("Expected connection without channels to be removed")
+	}
+
+	expectChannels(t, "connection '2'", connections["2"].Channels, []sse.ChannelVariant{"status"})
+
+	addresses := uut.ObservingAddresses()
+	if len(addresses) != 1 {
+		t.Fatalf("Expected only address with remaining connections, got %+v", addresses)
+	}
+
+	expectChannels(t, "address", addresses["192.168.1.2"], []sse.ChannelVariant{"playback", "status"})
+}
+
+func TestStorage_RemoveUnknownConnection(t *testing.T) {
+	// given
+	uut := newTestStorage()
+	uut.AddObservingConnection("1", Connection{RemoteAddr: "192.168.1.2"}, "playback")
+	revision := uut.Revision()
+
+	// when
+	uut.RemoveObservingConnection("2", "playback")
+
+	// then
+	if len(uut.Connections()) != 1 {
+		t.Errorf("Expected other connections to be left intact, got %+v", uut.Connections())
+	}
+
+	if uut.Revision() != revision {
+		t.Errorf("Expected revision to not change, got %d instead of %d", uut.Revision(), revision)
+	}
+}
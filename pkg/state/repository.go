@@ -3,6 +3,7 @@ package state
 import (
 	"github.com/sarpt/mpv-web-api/internal/common"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/directories"
+	"github.com/sarpt/mpv-web-api/pkg/state/pkg/logs"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/media_files"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playback"
 	"github.com/sarpt/mpv-web-api/pkg/state/pkg/playlists"
@@ -11,6 +12,7 @@ import (
 
 type Repository interface {
 	Directories() *directories.Storage
+	Logs() *logs.Storage
 	MediaFiles() *media_files.Storage
 	Playback() *playback.Storage
 	Playlists() *playlists.Storage
@@ -19,6 +21,7 @@ type Repository interface {
 
 type inMemoryRepository struct {
 	directories *directories.Storage
+	logs        *logs.Storage
 	mediaFiles  *media_files.Storage
 	playback    *playback.Storage
 	playlists   *playlists.Storage
@@ -29,6 +32,10 @@ func (r *inMemoryRepository) Directories() *directories.Storage {
 	return r.directories
 }
 
+func (r *inMemoryRepository) Logs() *logs.Storage {
+	return r.logs
+}
+
 func (r *inMemoryRepository) MediaFiles() *media_files.Storage {
 	return r.mediaFiles
 }
@@ -47,6 +54,7 @@ func (r *inMemoryRepository) Status() *status.Storage {
 
 func NewRepository() Repository {
 	directoriesBroadcaster := createAndInitChangesBroadcaster[directories.Change]()
+	logsBroadcaster := createAndInitChangesBroadcaster[logs.Change]()
 	mediaFilesBroadcaster := createAndInitChangesBroadcaster[media_files.Change]()
 	playbackBroadcaster := createAndInitChangesBroadcaster[playback.Change]()
 	playlistsBroadcaster := createAndInitChangesBroadcaster[playlists.Change]()
@@ -54,6 +62,7 @@ func NewRepository() Repository {
 
 	return &inMemoryRepository{
 		directories: directories.NewStorage(directoriesBroadcaster),
+		logs:        logs.NewStorage(logsBroadcaster),
 		mediaFiles:  media_files.NewStorage(mediaFilesBroadcaster),
 		playback:    playback.NewStorage(playbackBroadcaster),
 		playlists:   playlists.NewStorage(playlistsBroadcaster),
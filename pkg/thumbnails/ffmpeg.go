@@ -0,0 +1,137 @@
+package thumbnails
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+const (
+	ffmpegName = "ffmpeg"
+
+	hideBannerArg     = "-hide_banner"
+	logLevelArg       = "-loglevel"
+	errorLogLevel     = "error"
+	seekArg           = "-ss"
+	inputArg          = "-i"
+	mapArg            = "-map"
+	videoFilterArg    = "-vf"
+	framesArg         = "-frames:v"
+	qualityArg        = "-q:v"
+	jpegQuality       = "4"
+	formatArg         = "-f"
+	imageFormat       = "image2"
+	codecArg          = "-c:v"
+	jpegCodec         = "mjpeg"
+	overwriteArg      = "-y"
+	skipFrameArg      = "-skip_frame"
+	keyFramesOnly     = "nokey"
+	noAudioArg        = "-an"
+	noSubtitlesArg    = "-sn"
+	startNumberArg    = "-start_number"
+	trickplayQuality  = "5"
+	partialFileSuffix = ".part"
+
+	// thumbnailFilterFrames is a number of frames from which ffmpeg's thumbnail filter selects the most representative one.
+	thumbnailFilterFrames = 50
+)
+
+// scaleFilter returns ffmpeg filter scaling image to the width while keeping aspect ratio.
+// Images narrower than the width are not upscaled.
+func scaleFilter(width int) string {
+	return fmt.Sprintf("scale=w=min(%d\\,iw):h=-2", width)
+}
+
+// videoStream returns ffmpeg specifier of the video stream with the index among video streams of the input.
+func videoStream(idx int) string {
+	return fmt.Sprintf("0:v:%d", idx)
+}
+
+// extractFrame saves a representative frame near the provided position of the video stream as a JPEG image.
+func extractFrame(ctx context.Context, path string, stream int, position float64, width int, out string) error {
+	args := []string{
+		hideBannerArg,
+		logLevelArg, errorLogLevel,
+		seekArg, strconv.FormatFloat(position, 'f', 3, 64),
+		inputArg, path,
+		mapArg, videoStream(stream),
+		videoFilterArg, fmt.Sprintf("thumbnail=%d,%s", thumbnailFilterFrames, scaleFilter(width)),
+		framesArg, "1",
+	}
+
+	return runFfmpeg(ctx, args, out)
+}
+
+// extractCoverArt saves the picture attached to the file (eg. album cover embedded in audio file) as a JPEG image.
+func extractCoverArt(ctx context.Context, path string, stream int, width int, out string) error {
+	args := []string{
+		hideBannerArg,
+		logLevelArg, errorLogLevel,
+		inputArg, path,
+		mapArg, videoStream(stream),
+		videoFilterArg, scaleFilter(width),
+		framesArg, "1",
+	}
+
+	return runFfmpeg(ctx, args, out)
+}
+
+// extractTiles saves frames taken every index interval as sprite sheets with index dimensions.
+// Only key frames are decoded, which makes extraction much faster at the cost of frames being slightly off the interval.
+// outPattern should contain a printf-like number verb, eg. "%d.jpg", which is replaced by a number of a sheet starting at 0.
+func extractTiles(ctx context.Context, path string, stream int, index TrickplayIndex, outPattern string) error {
+	args := []string{
+		hideBannerArg,
+		logLevelArg, errorLogLevel,
+		skipFrameArg, keyFramesOnly,
+		inputArg, path,
+		mapArg, videoStream(stream),
+		noAudioArg,
+		noSubtitlesArg,
+		videoFilterArg, fmt.Sprintf("fps=1/%s,scale=%d:%d,tile=%dx%d", strconv.FormatFloat(index.Interval, 'f', -1, 64), index.TileWidth, index.TileHeight, index.Columns, index.Rows),
+		qualityArg, trickplayQuality,
+		codecArg, jpegCodec,
+		formatArg, imageFormat,
+		startNumberArg, "0",
+		overwriteArg, outPattern,
+	}
+
+	return ffmpeg(ctx, args)
+}
+
+func ffmpeg(ctx context.Context, args []string) error {
+	cmd := exec.CommandContext(ctx, ffmpegName, args...)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, output)
+	}
+
+	return nil
+}
+
+// runFfmpeg writes output to a partial file first and renames it when ffmpeg succeeds,
+// so incomplete images are never served from the cache.
+func runFfmpeg(ctx context.Context, args []string, out string) error {
+	partialOut := out + partialFileSuffix
+	args = append(args,
+		qualityArg, jpegQuality,
+		codecArg, jpegCodec,
+		formatArg, imageFormat,
+		overwriteArg, partialOut,
+	)
+
+	err := ffmpeg(ctx, args)
+	if err != nil {
+		os.Remove(partialOut)
+
+		return err
+	}
+
+	return os.Rename(partialOut, out)
+}
@@ -0,0 +1,25 @@
+package thumbnails
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+const (
+	fingerprintLength = 20
+)
+
+// Fingerprint returns an identifier of the file contents based on it's path, size and modification time.
+// Fingerprint changes whenever the file is replaced or modified, which invalidates images generated for the file.
+func Fingerprint(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", path, info.Size(), info.ModTime().UnixNano())))
+
+	return hex.EncodeToString(hash[:])[:fingerprintLength], nil
+}
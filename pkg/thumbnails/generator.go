@@ -0,0 +1,191 @@
+package thumbnails
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+)
+
+const (
+	// DefaultWidth is a width of thumbnails when none is requested explicitly.
+	DefaultWidth = 320
+
+	// MaxWidth is the largest width of thumbnails that can be requested.
+	MaxWidth = 1920
+
+	defaultWorkers   = 2
+	defaultQueueSize = 64
+	// trickplayWorkers is kept separate from thumbnail workers, so long running generation
+	// of sprite sheets does not hold back thumbnails.
+	trickplayWorkers = 1
+
+	// thumbnailPosition is a fraction of the media duration at which frame for the thumbnail is looked for.
+	// Beginning of the file is usually a black screen or studio logos.
+	thumbnailPosition = 0.1
+	// maxThumbnailOffset limits the position for long media, to not seek too far into the file.
+	maxThumbnailOffset = 300
+
+	thumbnailFileFormat        = "-w%d.jpg"
+	chapterThumbnailFileFormat = "-c%d-w%d.jpg"
+)
+
+var (
+	// ErrNoImage occurs when media file has neither video stream nor attached picture.
+	ErrNoImage = errors.New("media file has no image to create a thumbnail from")
+
+	// ErrInvalidWidth occurs when requested width is out of supported bounds.
+	ErrInvalidWidth = fmt.Errorf("width should be between 1 and %d", MaxWidth)
+)
+
+// Source describes a media file for which images are generated.
+// VideoStream and CoverArtStream are indices of the streams among video streams of the file
+// (attached pictures are video streams too), from which frames and cover art are taken.
+type Source struct {
+	CoverArtStream int
+	Duration       float64
+	HasCoverArt    bool
+	HasVideo       bool
+	Height         int
+	Path           string
+	VideoStream    int
+	Width          int
+}
+
+// NewSource describes the media file by its video streams, which should be in the same order as in the file.
+// Frames are taken from the first stream which is not an attached picture, while cover art is the first attached picture.
+func NewSource(path string, duration float64, videoStreams []probe.VideoStream) Source {
+	src := Source{
+		Duration: duration,
+		Path:     path,
+	}
+
+	for idx, stream := range videoStreams {
+		if stream.Disposition.AttachedPic {
+			if !src.HasCoverArt {
+				src.HasCoverArt = true
+				src.CoverArtStream = idx
+			}
+		} else if !src.HasVideo {
+			src.HasVideo = true
+			src.VideoStream = idx
+			src.Width = stream.Width
+			src.Height = stream.Height
+		}
+	}
+
+	return src
+}
+
+// Config controls behaviour of the generator.
+type Config struct {
+	// Dir is a directory in which generated images are stored.
+	Dir string
+	// QueueSize is a maximum number of images waiting for generation.
+	QueueSize int
+	// Workers is a number of ffmpeg processes that can run at the same time.
+	Workers int
+}
+
+// Generator creates images for media files lazily - only when they are requested for the first time.
+// Generated images are cached in the directory and reused until the media file changes.
+type Generator struct {
+	dir               string
+	queue             *queue
+	trickplayFailures *trickplayFailures
+	trickplayQueue    *queue
+}
+
+// NewGenerator returns generator with workers waiting for jobs.
+func NewGenerator(cfg Config) *Generator {
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaultWorkers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	return &Generator{
+		dir:   cfg.Dir,
+		queue: newQueue(cfg.Workers, cfg.QueueSize),
+		trickplayFailures: &trickplayFailures{
+			errs: map[string]error{},
+			lock: &sync.Mutex{},
+		},
+		trickplayQueue: newQueue(trickplayWorkers, cfg.QueueSize),
+	}
+}
+
+// Thumbnail returns path to the JPEG thumbnail of the media file, generating it when it's not cached yet.
+// Videos use a representative frame, while audio files use their attached cover art.
+func (g *Generator) Thumbnail(ctx context.Context, src Source, width int) (string, error) {
+	position := min(src.Duration*thumbnailPosition, maxThumbnailOffset)
+
+	return g.frame(ctx, src, width, position, fmt.Sprintf(thumbnailFileFormat, width))
+}
+
+// ChapterThumbnail returns path to the JPEG thumbnail of the chapter, which is a representative frame
+// from the first seconds of the chapter. Audio files use their attached cover art for every chapter.
+func (g *Generator) ChapterThumbnail(ctx context.Context, src Source, chapterIdx int, startTime float64, width int) (string, error) {
+	if !src.HasVideo {
+		return g.Thumbnail(ctx, src, width)
+	}
+
+	return g.frame(ctx, src, width, startTime, fmt.Sprintf(chapterThumbnailFileFormat, chapterIdx, width))
+}
+
+// frame generates the image of the frame around the position, which is saved with the fingerprint of the file prepended to the name.
+func (g *Generator) frame(ctx context.Context, src Source, width int, position float64, name string) (string, error) {
+	if width < 1 || width > MaxWidth {
+		return "", ErrInvalidWidth
+	}
+
+	if !src.HasVideo && !src.HasCoverArt {
+		return "", ErrNoImage
+	}
+
+	fingerprint, err := Fingerprint(src.Path)
+	if err != nil {
+		return "", err
+	}
+
+	out := filepath.Join(g.dir, fingerprint+name)
+
+	return out, g.generate(ctx, out, func(ctx context.Context) error {
+		if !src.HasVideo {
+			return extractCoverArt(ctx, src.Path, src.CoverArtStream, width, out)
+		}
+
+		return extractFrame(ctx, src.Path, src.VideoStream, position, width, out)
+	})
+}
+
+// Close stops generation of images. Generator cannot be used after closing.
+func (g *Generator) Close() {
+	g.queue.Close()
+	g.trickplayQueue.Close()
+}
+
+// generate runs the job creating out file, unless the file already exists.
+func (g *Generator) generate(ctx context.Context, out string, run jobFunc) error {
+	if _, err := os.Stat(out); err == nil {
+		return nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(out), 0750)
+	if err != nil {
+		return err
+	}
+
+	return g.queue.Do(ctx, out, func(ctx context.Context) error {
+		if _, err := os.Stat(out); err == nil {
+			return nil // generated by a job which finished after the check above
+		}
+
+		return run(ctx)
+	})
+}
@@ -0,0 +1,54 @@
+package thumbnails
+
+import (
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/probe"
+)
+
+func TestNewSource(t *testing.T) {
+	coverArt := probe.VideoStream{Disposition: probe.Disposition{AttachedPic: true}, Width: 600, Height: 600}
+	video := probe.VideoStream{Width: 1920, Height: 1080}
+
+	testCases := map[string]struct {
+		videoStreams []probe.VideoStream
+		expected     Source
+	}{
+		"video only": {
+			[]probe.VideoStream{video},
+			Source{HasVideo: true, VideoStream: 0, Width: 1920, Height: 1080},
+		},
+		"cover art before video": {
+			[]probe.VideoStream{coverArt, video},
+			Source{HasCoverArt: true, CoverArtStream: 0, HasVideo: true, VideoStream: 1, Width: 1920, Height: 1080},
+		},
+		"cover art after video": {
+			[]probe.VideoStream{video, coverArt, coverArt},
+			Source{HasCoverArt: true, CoverArtStream: 1, HasVideo: true, VideoStream: 0, Width: 1920, Height: 1080},
+		},
+		"cover art only": {
+			[]probe.VideoStream{coverArt},
+			Source{HasCoverArt: true, CoverArtStream: 0},
+		},
+		"no video streams": {
+			nil,
+			Source{},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// given
+			testCase.expected.Duration = 60
+			testCase.expected.Path = "/media/a.mkv"
+
+			// when
+			result := NewSource("/media/a.mkv", 60, testCase.videoStreams)
+
+			// then
+			if result != testCase.expected {
+				t.Errorf("Expected source %+v, got %+v", testCase.expected, result)
+			}
+		})
+	}
+}
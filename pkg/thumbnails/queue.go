@@ -0,0 +1,146 @@
+package thumbnails
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrQueueFull occurs when there are too many images waiting for generation.
+	ErrQueueFull = errors.New("generation queue is full")
+
+	// ErrClosed occurs when generation is requested after the generator has been closed.
+	ErrClosed = errors.New("generator is closed")
+)
+
+type jobFunc = func(ctx context.Context) error
+
+type job struct {
+	cancel context.CancelFunc
+	ctx    context.Context
+	done   chan struct{}
+	err    error
+	key    string
+	run    jobFunc
+}
+
+// queue runs jobs on a bounded number of workers.
+// Jobs with the same key are deduplicated - requesting a job which is already
+// queued or running results in waiting for the pending one.
+type queue struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	jobs    chan *job
+	lock    *sync.Mutex
+	pending map[string]*job
+}
+
+func newQueue(workers int, size int) *queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &queue{
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(chan *job, size),
+		lock:    &sync.Mutex{},
+		pending: map[string]*job{},
+	}
+
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// Do schedules the job and waits until it finishes or the context is done.
+// Job is not cancelled when the context is done, since other callers might wait for it.
+func (q *queue) Do(ctx context.Context, key string, run jobFunc) error {
+	j, err := q.submit(key, run)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-j.done:
+		return j.err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.ctx.Done():
+		return ErrClosed
+	}
+}
+
+func (q *queue) submit(key string, run jobFunc) (*job, error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.ctx.Err() != nil {
+		return nil, ErrClosed
+	}
+
+	if j, ok := q.pending[key]; ok {
+		return j, nil
+	}
+
+	ctx, cancel := context.WithCancel(q.ctx)
+	j := &job{
+		cancel: cancel,
+		ctx:    ctx,
+		done:   make(chan struct{}),
+		key:    key,
+		run:    run,
+	}
+
+	select {
+	case q.jobs <- j:
+	default:
+		cancel()
+
+		return nil, ErrQueueFull
+	}
+
+	q.pending[key] = j
+
+	return j, nil
+}
+
+func (q *queue) work() {
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case j := <-q.jobs:
+			j.err = j.ctx.Err() // job cancelled while waiting in the queue is not run at all
+			if j.err == nil {
+				j.err = j.run(j.ctx)
+			}
+			j.cancel()
+
+			q.lock.Lock()
+			delete(q.pending, j.key)
+			q.lock.Unlock()
+
+			close(j.done)
+		}
+	}
+}
+
+// Cancel cancels context of the job with the key, when it's waiting in the queue or running.
+// Returns false when there is no such job.
+func (q *queue) Cancel(key string) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	j, ok := q.pending[key]
+	if ok {
+		j.cancel()
+	}
+
+	return ok
+}
+
+// Close stops workers and cancels running jobs.
+func (q *queue) Close() {
+	q.cancel()
+}
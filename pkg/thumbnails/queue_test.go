@@ -0,0 +1,78 @@
+package thumbnails
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestQueue_DeduplicatesPendingJobs(t *testing.T) {
+	// given
+	uut := newQueue(1, 1)
+	defer uut.Close()
+
+	var runs atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	job := func(ctx context.Context) error {
+		runs.Add(1)
+		close(started)
+		<-release
+
+		return nil
+	}
+
+	// when
+	result := make(chan error)
+	go func() { result <- uut.Do(context.Background(), "key", job) }()
+	<-started
+
+	duplicate, err := uut.submit("key", job)
+	if err != nil {
+		t.Fatalf("Unexpected error while submitting duplicate: %s", err)
+	}
+	close(release)
+
+	// then
+	if err := <-result; err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	<-duplicate.done
+	if runs.Load() != 1 {
+		t.Errorf("Expected job to run once, ran %d times", runs.Load())
+	}
+}
+
+func TestQueue_RejectsJobsWhenFull(t *testing.T) {
+	// given
+	uut := newQueue(1, 1)
+	defer uut.Close()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	blocking := func(ctx context.Context) error {
+		close(started)
+		<-release
+
+		return nil
+	}
+	noop := func(ctx context.Context) error { return nil }
+
+	go uut.Do(context.Background(), "running", blocking)
+	<-started
+	_, err := uut.submit("queued", noop)
+	if err != nil {
+		t.Fatalf("Unexpected error while filling the queue: %s", err)
+	}
+
+	// when
+	err = uut.Do(context.Background(), "rejected", noop)
+
+	// then
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected '%s' error, got '%v'", ErrQueueFull, err)
+	}
+}
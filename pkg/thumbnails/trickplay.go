@@ -0,0 +1,281 @@
+package thumbnails
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TrickplayTileWidth is a width of a single frame in trickplay sprite sheets.
+	TrickplayTileWidth = 160
+	// TrickplayColumns is a number of tiles in a row of a sprite sheet.
+	TrickplayColumns = 10
+	// TrickplayRows is a number of tiles in a column of a sprite sheet.
+	TrickplayRows = 10
+
+	// minTrickplayInterval is a time in seconds between frames for shorter media.
+	minTrickplayInterval = 10
+	// maxTrickplayTiles limits number of frames for long media, by increasing the interval between frames.
+	maxTrickplayTiles = 1000
+	// defaultTileAspectRatio is used when video dimensions are unknown.
+	defaultTileAspectRatio = 9.0 / 16.0
+
+	trickplayDirName       = "trickplay"
+	trickplayIndexFilename = "index.json"
+	trickplaySheetFormat   = "%d.jpg"
+	webVTTHeader           = "WEBVTT\n\n"
+	webVTTCueFormat        = "%s --> %s\n%s#xywh=%d,%d,%d,%d\n\n"
+)
+
+var (
+	// ErrTrickplayPending occurs when sprite sheets are not generated yet, but their generation is in progress.
+	ErrTrickplayPending = errors.New("trickplay generation is in progress")
+
+	// ErrNoTrickplaySheet occurs when requested sprite sheet does not exist.
+	ErrNoTrickplaySheet = errors.New("trickplay sheet does not exist")
+)
+
+// TrickplayTile maps a time range of the media to a position of the frame on a sprite sheet.
+type TrickplayTile struct {
+	End   float64 `json:"End"`
+	Sheet int     `json:"Sheet"`
+	Start float64 `json:"Start"`
+	X     int     `json:"X"`
+	Y     int     `json:"Y"`
+}
+
+// TrickplayIndex describes sprite sheets with frames taken periodically from the media, used for seek bar previews.
+type TrickplayIndex struct {
+	Columns    int             `json:"Columns"`
+	Interval   float64         `json:"Interval"`
+	Rows       int             `json:"Rows"`
+	Sheets     int             `json:"Sheets"`
+	TileHeight int             `json:"TileHeight"`
+	TileWidth  int             `json:"TileWidth"`
+	Tiles      []TrickplayTile `json:"Tiles"`
+}
+
+// WebVTT returns index in WebVTT format with media fragments pointing to tiles on sheets.
+// sheetURL should return URL of the sheet, eg. relative to the URL of the WebVTT file.
+func (idx TrickplayIndex) WebVTT(sheetURL func(sheet int) string) []byte {
+	var vtt strings.Builder
+	vtt.WriteString(webVTTHeader)
+	for _, tile := range idx.Tiles {
+		vtt.WriteString(fmt.Sprintf(webVTTCueFormat, webVTTTimestamp(tile.Start), webVTTTimestamp(tile.End), sheetURL(tile.Sheet), tile.X, tile.Y, idx.TileWidth, idx.TileHeight))
+	}
+
+	return []byte(vtt.String())
+}
+
+func webVTTTimestamp(seconds float64) string {
+	duration := time.Duration(seconds * float64(time.Second)).Round(time.Millisecond)
+	hours := int(duration.Hours())
+	minutes := int(duration.Minutes()) % 60
+	secs := int(duration.Seconds()) % 60
+	millis := int(duration.Milliseconds()) % 1000
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, secs, millis)
+}
+
+// trickplayFailures holds errors of finished trickplay jobs, until they are reported.
+type trickplayFailures struct {
+	errs map[string]error
+	lock *sync.Mutex
+}
+
+func (f *trickplayFailures) set(key string, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.errs[key] = err
+}
+
+func (f *trickplayFailures) take(key string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	err := f.errs[key]
+	delete(f.errs, key)
+
+	return err
+}
+
+// Trickplay returns index of sprite sheets for the media file.
+// When sheets are not generated yet, their generation is started in the background and ErrTrickplayPending is returned
+// - caller should ask again later. Failure of the generation is returned once, and next call starts the generation again.
+func (g *Generator) Trickplay(src Source) (TrickplayIndex, error) {
+	dir, err := g.trickplayDir(src)
+	if err != nil {
+		return TrickplayIndex{}, err
+	}
+
+	index, err := readTrickplayIndex(dir)
+	if err == nil {
+		return index, nil
+	}
+
+	if err := g.trickplayFailures.take(dir); err != nil {
+		return TrickplayIndex{}, err
+	}
+
+	_, err = g.trickplayQueue.submit(dir, func(ctx context.Context) error {
+		if _, err := readTrickplayIndex(dir); err == nil {
+			return nil // generated by a job which finished after the check above
+		}
+
+		err := generateTrickplay(ctx, src, dir)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			g.trickplayFailures.set(dir, err)
+		}
+
+		return err
+	})
+	if err != nil {
+		return TrickplayIndex{}, err
+	}
+
+	return TrickplayIndex{}, ErrTrickplayPending
+}
+
+// CancelTrickplay stops generation of sprite sheets for the media file.
+// Returns false when the generation is not in progress.
+func (g *Generator) CancelTrickplay(src Source) (bool, error) {
+	dir, err := g.trickplayDir(src)
+	if err != nil {
+		return false, err
+	}
+
+	return g.trickplayQueue.Cancel(dir), nil
+}
+
+// TrickplaySheet returns path to the JPEG sprite sheet with the provided index.
+func (g *Generator) TrickplaySheet(src Source, sheet int) (string, error) {
+	index, err := g.Trickplay(src)
+	if err != nil {
+		return "", err
+	}
+
+	if sheet < 0 || sheet >= index.Sheets {
+		return "", ErrNoTrickplaySheet
+	}
+
+	dir, err := g.trickplayDir(src)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, fmt.Sprintf(trickplaySheetFormat, sheet)), nil
+}
+
+func (g *Generator) trickplayDir(src Source) (string, error) {
+	if !src.HasVideo {
+		return "", ErrNoImage
+	}
+
+	fingerprint, err := Fingerprint(src.Path)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(g.dir, trickplayDirName, fingerprint), nil
+}
+
+func readTrickplayIndex(dir string) (TrickplayIndex, error) {
+	var index TrickplayIndex
+
+	content, err := os.ReadFile(filepath.Join(dir, trickplayIndexFilename))
+	if err != nil {
+		return index, err
+	}
+
+	err = json.Unmarshal(content, &index)
+	return index, err
+}
+
+// generateTrickplay creates sprite sheets in a partial directory, which is renamed when all sheets and index are ready.
+func generateTrickplay(ctx context.Context, src Source, dir string) error {
+	partialDir := dir + partialFileSuffix
+	err := os.RemoveAll(partialDir)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(partialDir, 0750)
+	if err != nil {
+		return err
+	}
+
+	index := newTrickplayIndex(src)
+	err = extractTiles(ctx, src.Path, src.VideoStream, index, filepath.Join(partialDir, trickplaySheetFormat))
+	if err == nil {
+		err = finalizeTrickplayIndex(&index, partialDir, src.Duration)
+	}
+	if err == nil {
+		err = os.Rename(partialDir, dir)
+	}
+	if err != nil {
+		os.RemoveAll(partialDir)
+	}
+
+	return err
+}
+
+func newTrickplayIndex(src Source) TrickplayIndex {
+	aspectRatio := defaultTileAspectRatio
+	if src.Width > 0 && src.Height > 0 {
+		aspectRatio = float64(src.Height) / float64(src.Width)
+	}
+
+	return TrickplayIndex{
+		Columns:    TrickplayColumns,
+		Interval:   math.Max(minTrickplayInterval, math.Ceil(src.Duration/maxTrickplayTiles)),
+		Rows:       TrickplayRows,
+		TileHeight: int(math.Round(TrickplayTileWidth*aspectRatio/2)) * 2,
+		TileWidth:  TrickplayTileWidth,
+	}
+}
+
+// finalizeTrickplayIndex fills tiles based on sheets created by ffmpeg and saves the index.
+func finalizeTrickplayIndex(index *TrickplayIndex, dir string, duration float64) error {
+	sheets, err := filepath.Glob(filepath.Join(dir, "*.jpg"))
+	if err != nil {
+		return err
+	}
+
+	if len(sheets) == 0 {
+		return errors.New("ffmpeg did not create any trickplay sheets")
+	}
+
+	index.Sheets = len(sheets)
+	tilesPerSheet := index.Columns * index.Rows
+	for idx := 0; idx < index.Sheets*tilesPerSheet; idx++ {
+		start := float64(idx) * index.Interval
+		if idx > 0 && start >= duration {
+			break
+		}
+
+		posOnSheet := idx % tilesPerSheet
+		index.Tiles = append(index.Tiles, TrickplayTile{
+			End:   start + index.Interval,
+			Sheet: idx / tilesPerSheet,
+			Start: start,
+			X:     (posOnSheet % index.Columns) * index.TileWidth,
+			Y:     (posOnSheet / index.Columns) * index.TileHeight,
+		})
+	}
+
+	content, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, trickplayIndexFilename), content, 0640)
+}
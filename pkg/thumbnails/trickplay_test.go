@@ -0,0 +1,66 @@
+package thumbnails
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFinalizeTrickplayIndex_MapsTilesToSheets(t *testing.T) {
+	// given
+	dir := t.TempDir()
+	for _, sheet := range []string{"0.jpg", "1.jpg"} {
+		err := os.WriteFile(filepath.Join(dir, sheet), nil, 0640)
+		if err != nil {
+			t.Fatalf("Could not create sheet: %s", err)
+		}
+	}
+
+	index := newTrickplayIndex(Source{Duration: 1015, Width: 1920, Height: 800})
+
+	// when
+	err := finalizeTrickplayIndex(&index, dir, 1015)
+
+	// then
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if index.TileHeight != 66 || index.Interval != 10 {
+		t.Errorf("Expected tile height 66 and interval 10, got %d and %f", index.TileHeight, index.Interval)
+	}
+
+	if len(index.Tiles) != 102 {
+		t.Fatalf("Expected 102 tiles, got %d", len(index.Tiles))
+	}
+
+	last := index.Tiles[101]
+	if last.Sheet != 1 || last.X != TrickplayTileWidth || last.Y != 0 || last.Start != 1010 {
+		t.Errorf("Unexpected last tile: %+v", last)
+	}
+
+	saved, err := readTrickplayIndex(dir)
+	if err != nil || len(saved.Tiles) != len(index.Tiles) {
+		t.Errorf("Expected index to be saved, got error: %v", err)
+	}
+}
+
+func TestTrickplayIndex_WebVTT(t *testing.T) {
+	// given
+	uut := TrickplayIndex{
+		TileHeight: 90,
+		TileWidth:  160,
+		Tiles: []TrickplayTile{
+			{Start: 3590, End: 3600, Sheet: 3, X: 320, Y: 180},
+		},
+	}
+
+	// when
+	vtt := string(uut.WebVTT(func(sheet int) string { return "sheets/3.jpg" }))
+
+	// then
+	expected := "WEBVTT\n\n00:59:50.000 --> 01:00:00.000\nsheets/3.jpg#xywh=320,180,160,90\n\n"
+	if vtt != expected {
+		t.Errorf("Expected WebVTT:\n%s\ngot:\n%s", expected, vtt)
+	}
+}
@@ -0,0 +1,97 @@
+package user_metadata
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxRating is the highest rating that can be given. Rating of 0 means that the entry is not rated.
+	MaxRating = 5
+)
+
+var (
+	// ErrInvalidRating occurs when rating is out of supported bounds.
+	ErrInvalidRating = fmt.Errorf("rating should be between 0 and %d", MaxRating)
+)
+
+// Metadata is information attached to media files and directories by users, as opposed to the one read from files.
+type Metadata struct {
+	Favourite bool     `json:"Favourite"`
+	Notes     string   `json:"Notes"`
+	Rating    int      `json:"Rating"`
+	Tags      []string `json:"Tags"`
+	Watched   bool     `json:"Watched"`
+}
+
+// IsEmpty checks whether nothing was set by the user.
+func (m Metadata) IsEmpty() bool {
+	return !m.Favourite && m.Notes == "" && m.Rating == 0 && len(m.Tags) == 0 && !m.Watched
+}
+
+// HasTags checks whether metadata includes all provided tags. Tags are compared case-insensitively.
+func (m Metadata) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(m.Tags, func(t string) bool {
+			return strings.EqualFold(t, tag)
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Update describes changes to metadata. Nil fields are left unchanged.
+type Update struct {
+	Favourite *bool
+	Notes     *string
+	Rating    *int
+	Tags      *[]string
+	Watched   *bool
+}
+
+// Apply returns a copy of metadata with changes from the update.
+// Tags are trimmed and deduplicated (case-insensitively), keeping their order. Empty tags are skipped.
+func (m Metadata) Apply(update Update) (Metadata, error) {
+	if update.Favourite != nil {
+		m.Favourite = *update.Favourite
+	}
+
+	if update.Notes != nil {
+		m.Notes = *update.Notes
+	}
+
+	if update.Rating != nil {
+		if *update.Rating < 0 || *update.Rating > MaxRating {
+			return m, ErrInvalidRating
+		}
+
+		m.Rating = *update.Rating
+	}
+
+	if update.Tags != nil {
+		m.Tags = normalizeTags(*update.Tags)
+	}
+
+	if update.Watched != nil {
+		m.Watched = *update.Watched
+	}
+
+	return m, nil
+}
+
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.ContainsFunc(normalized, func(t string) bool {
+			return strings.EqualFold(t, tag)
+		}) {
+			normalized = append(normalized, tag)
+		}
+	}
+
+	return normalized
+}
@@ -0,0 +1,97 @@
+package user_metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store keeps user metadata keyed by paths of media files and directories, persisting it in a JSON file.
+// Metadata is stored separately from the cache of probed files, so it survives clearing of the cache.
+type Store struct {
+	items    map[string]Metadata
+	lock     *sync.RWMutex
+	path     string
+	saveLock *sync.Mutex
+}
+
+// Load returns store persisted in the file under the path. When the file does not exist yet, the store is empty.
+func Load(path string) (*Store, error) {
+	store := &Store{
+		items:    map[string]Metadata{},
+		lock:     &sync.RWMutex{},
+		path:     path,
+		saveLock: &sync.Mutex{},
+	}
+
+	payload, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+
+	if err != nil {
+		return store, err
+	}
+
+	return store, json.Unmarshal(payload, &store.items)
+}
+
+// Get returns metadata of the entry under the path, or empty metadata when nothing was set.
+func (s *Store) Get(path string) Metadata {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.items[path]
+}
+
+// Set replaces metadata of the entry under the path and persists the store.
+// Empty metadata removes the entry from the store.
+func (s *Store) Set(path string, metadata Metadata) error {
+	s.Put(path, metadata)
+
+	return s.Save()
+}
+
+// Put replaces metadata of the entry under the path without persisting the store,
+// so it can be done under other locks without waiting for the disk. Empty metadata removes the entry from the store.
+func (s *Store) Put(path string, metadata Metadata) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if metadata.IsEmpty() {
+		delete(s.items, path)
+	} else {
+		s.items[path] = metadata
+	}
+}
+
+// Save persists the current contents of the store. Saves are done one at a time, so the file
+// always ends up with contents from the latest save, and the store is not locked while writing the file.
+// The store is written to a temporary file first, so the previous contents are not lost when writing fails midway.
+func (s *Store) Save() error {
+	s.saveLock.Lock()
+	defer s.saveLock.Unlock()
+
+	s.lock.RLock()
+	payload, err := json.Marshal(s.items)
+	s.lock.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0750)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	err = os.WriteFile(tmpPath, payload, 0640)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, s.path)
+}
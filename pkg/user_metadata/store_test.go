@@ -0,0 +1,94 @@
+package user_metadata_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sarpt/mpv-web-api/pkg/user_metadata"
+)
+
+func TestStorePersistsMetadata(t *testing.T) {
+	// given
+	storePath := filepath.Join(t.TempDir(), "user_metadata.json")
+	uut, err := user_metadata.Load(storePath)
+	if err != nil {
+		t.Fatalf("Unexpected error while loading empty store: %s", err)
+	}
+
+	rating := 4
+	tags := []string{" action ", "Action", "classic"}
+	metadata, err := user_metadata.Metadata{}.Apply(user_metadata.Update{
+		Rating: &rating,
+		Tags:   &tags,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error while applying update: %s", err)
+	}
+
+	// when
+	err = uut.Set("/movies/movie.mkv", metadata)
+	if err != nil {
+		t.Fatalf("Unexpected error while setting metadata: %s", err)
+	}
+
+	loaded, err := user_metadata.Load(storePath)
+
+	// then
+	if err != nil {
+		t.Fatalf("Unexpected error while loading store: %s", err)
+	}
+
+	result := loaded.Get("/movies/movie.mkv")
+	if result.Rating != 4 || len(result.Tags) != 2 || result.Tags[0] != "action" || result.Tags[1] != "classic" {
+		t.Errorf("Unexpected metadata loaded: %+v", result)
+	}
+
+	if !result.HasTags([]string{"CLASSIC"}) {
+		t.Errorf("Expected tags to be matched case-insensitively")
+	}
+}
+
+func TestStorePutPersistsOnlyOnSave(t *testing.T) {
+	// given
+	storePath := filepath.Join(t.TempDir(), "user_metadata.json")
+	uut, err := user_metadata.Load(storePath)
+	if err != nil {
+		t.Fatalf("Unexpected error while loading empty store: %s", err)
+	}
+
+	// when
+	uut.Put("/movies/movie.mkv", user_metadata.Metadata{Favourite: true})
+	beforeSave, beforeSaveErr := user_metadata.Load(storePath)
+	err = uut.Save()
+	afterSave, afterSaveErr := user_metadata.Load(storePath)
+
+	// then
+	if beforeSaveErr != nil || err != nil || afterSaveErr != nil {
+		t.Fatalf("Unexpected errors while loading and saving store: %v, %v, %v", beforeSaveErr, err, afterSaveErr)
+	}
+
+	if !uut.Get("/movies/movie.mkv").Favourite {
+		t.Errorf("Expected put metadata to be available before saving")
+	}
+
+	if beforeSave.Get("/movies/movie.mkv").Favourite {
+		t.Errorf("Expected put metadata not to be persisted before saving")
+	}
+
+	if !afterSave.Get("/movies/movie.mkv").Favourite {
+		t.Errorf("Expected put metadata to be persisted after saving")
+	}
+}
+
+func TestApplyRejectsInvalidRating(t *testing.T) {
+	// given
+	rating := user_metadata.MaxRating + 1
+
+	// when
+	_, err := user_metadata.Metadata{}.Apply(user_metadata.Update{Rating: &rating})
+
+	// then
+	if err != user_metadata.ErrInvalidRating {
+		t.Errorf("Expected invalid rating error, got %v", err)
+	}
+}